@@ -1,6 +1,9 @@
 resource "aws_api_gateway_rest_api" "api" {
   name        = "${var.domain_name}-opentofu-registry"
   description = "API Gateway for the OpenTofu Registry"
+
+  // uploads to the publishing API are passed to the lambda base64 encoded
+  binary_media_types = ["multipart/form-data", "application/gzip", "application/x-gzip", "application/octet-stream"]
 }
 
 resource "aws_api_gateway_resource" "github" {
@@ -57,6 +60,18 @@ resource "aws_api_gateway_resource" "namespace_resource" {
   path_part   = "{namespace}"
 }
 
+resource "aws_api_gateway_resource" "providers_search_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.providers_resource.id
+  path_part   = "search"
+}
+
+resource "aws_api_gateway_resource" "providers_batch_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.providers_resource.id
+  path_part   = "batch"
+}
+
 resource "aws_api_gateway_resource" "provider_type_resource" {
   rest_api_id = aws_api_gateway_rest_api.api.id
   parent_id   = aws_api_gateway_resource.namespace_resource.id
@@ -69,6 +84,18 @@ resource "aws_api_gateway_resource" "provider_versions_resource" {
   path_part   = "versions"
 }
 
+resource "aws_api_gateway_resource" "provider_resolve_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.provider_type_resource.id
+  path_part   = "resolve"
+}
+
+resource "aws_api_gateway_resource" "provider_publish_version_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.provider_versions_resource.id
+  path_part   = "{version}"
+}
+
 resource "aws_api_gateway_resource" "provider_version_resource" {
   rest_api_id = aws_api_gateway_rest_api.api.id
   parent_id   = aws_api_gateway_resource.provider_type_resource.id
@@ -135,6 +162,48 @@ resource "aws_api_gateway_resource" "module_versions_resource" {
   path_part   = "versions"
 }
 
+resource "aws_api_gateway_resource" "module_resolve_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.modules_system_resource.id
+  path_part   = "resolve"
+}
+
+resource "aws_api_gateway_resource" "module_publish_version_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.module_versions_resource.id
+  path_part   = "{version}"
+}
+
+resource "aws_api_gateway_resource" "advisories_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.v1_resource.id
+  path_part   = "advisories"
+}
+
+resource "aws_api_gateway_resource" "advisories_namespace_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.advisories_resource.id
+  path_part   = "{namespace}"
+}
+
+resource "aws_api_gateway_resource" "advisories_type_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.advisories_namespace_resource.id
+  path_part   = "{type}"
+}
+
+resource "aws_api_gateway_resource" "webhooks_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.v1_resource.id
+  path_part   = "webhooks"
+}
+
+resource "aws_api_gateway_resource" "github_webhook_resource" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.webhooks_resource.id
+  path_part   = "github"
+}
+
 resource "aws_api_gateway_method" "provider_download_method" {
   rest_api_id   = aws_api_gateway_rest_api.api.id
   resource_id   = aws_api_gateway_resource.provider_arch_resource.id
@@ -175,8 +244,15 @@ resource "aws_api_gateway_method" "provider_list_versions_method" {
   authorization = "NONE"
 
   request_parameters = {
-    "method.request.path.namespace" = true,
-    "method.request.path.type"      = true,
+    "method.request.path.namespace"                  = true,
+    "method.request.path.type"                       = true,
+    "method.request.querystring.protocol"            = false,
+    "method.request.querystring.os"                  = false,
+    "method.request.querystring.arch"                = false,
+    "method.request.querystring.since"               = false,
+    "method.request.querystring.include_prereleases" = false,
+    "method.request.querystring.limit"               = false,
+    "method.request.querystring.cursor"              = false,
   }
 }
 
@@ -192,9 +268,191 @@ resource "aws_api_gateway_integration" "provider_list_versions_integration" {
   cache_key_parameters = [
     "method.request.path.namespace",
     "method.request.path.type",
+    "method.request.querystring.protocol",
+    "method.request.querystring.os",
+    "method.request.querystring.arch",
+    "method.request.querystring.since",
+    "method.request.querystring.include_prereleases",
+    "method.request.querystring.limit",
+    "method.request.querystring.cursor",
   ]
 }
 
+resource "aws_api_gateway_method" "provider_resolve_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.provider_resolve_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace"         = true,
+    "method.request.path.type"              = true,
+    "method.request.querystring.constraint" = false,
+    "method.request.querystring.os"         = false,
+    "method.request.querystring.arch"       = false,
+  }
+}
+
+resource "aws_api_gateway_integration" "provider_resolve_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.provider_resolve_resource.id
+  http_method = aws_api_gateway_method.provider_resolve_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.path.namespace",
+    "method.request.path.type",
+    "method.request.querystring.constraint",
+    "method.request.querystring.os",
+    "method.request.querystring.arch",
+  ]
+}
+
+resource "aws_api_gateway_method" "provider_publish_version_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.provider_publish_version_resource.id
+  http_method   = "POST"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace" = true,
+    "method.request.path.type"      = true,
+    "method.request.path.version"   = true,
+  }
+}
+
+resource "aws_api_gateway_integration" "provider_publish_version_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.provider_publish_version_resource.id
+  http_method = aws_api_gateway_method.provider_publish_version_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+}
+
+resource "aws_api_gateway_method" "provider_get_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.provider_type_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace" = true,
+    "method.request.path.type"      = true,
+  }
+}
+
+resource "aws_api_gateway_integration" "provider_get_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.provider_type_resource.id
+  http_method = aws_api_gateway_method.provider_get_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.path.namespace",
+    "method.request.path.type",
+  ]
+}
+
+resource "aws_api_gateway_method" "namespace_list_providers_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.namespace_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace" = true,
+  }
+}
+
+resource "aws_api_gateway_integration" "namespace_list_providers_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.namespace_resource.id
+  http_method = aws_api_gateway_method.namespace_list_providers_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.path.namespace",
+  ]
+}
+
+resource "aws_api_gateway_method" "providers_search_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.providers_search_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.querystring.q"         = false,
+    "method.request.querystring.namespace" = false,
+    "method.request.querystring.tier"      = false,
+    "method.request.querystring.limit"     = false,
+    "method.request.querystring.offset"    = false,
+  }
+}
+
+resource "aws_api_gateway_integration" "providers_search_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.providers_search_resource.id
+  http_method = aws_api_gateway_method.providers_search_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.querystring.q",
+    "method.request.querystring.namespace",
+    "method.request.querystring.tier",
+    "method.request.querystring.limit",
+    "method.request.querystring.offset",
+  ]
+}
+
+resource "aws_api_gateway_method" "providers_batch_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.providers_batch_resource.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "providers_batch_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.providers_batch_resource.id
+  http_method = aws_api_gateway_method.providers_batch_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+}
+
+resource "aws_api_gateway_method" "github_webhook_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.github_webhook_resource.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "github_webhook_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.github_webhook_resource.id
+  http_method = aws_api_gateway_method.github_webhook_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+}
+
 resource "aws_api_gateway_method" "module_download_method" {
   rest_api_id   = aws_api_gateway_rest_api.api.id
   resource_id   = aws_api_gateway_resource.module_download_resource.id
@@ -255,6 +513,88 @@ resource "aws_api_gateway_integration" "module_list_versions_integration" {
   ]
 }
 
+resource "aws_api_gateway_method" "module_resolve_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.module_resolve_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace"         = true,
+    "method.request.path.name"              = true,
+    "method.request.path.system"            = true,
+    "method.request.querystring.constraint" = false,
+  }
+}
+
+resource "aws_api_gateway_integration" "module_resolve_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.module_resolve_resource.id
+  http_method = aws_api_gateway_method.module_resolve_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.path.namespace",
+    "method.request.path.name",
+    "method.request.path.system",
+    "method.request.querystring.constraint",
+  ]
+}
+
+resource "aws_api_gateway_method" "module_publish_version_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.module_publish_version_resource.id
+  http_method   = "POST"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace" = true,
+    "method.request.path.name"      = true,
+    "method.request.path.system"    = true,
+    "method.request.path.version"   = true,
+  }
+}
+
+resource "aws_api_gateway_integration" "module_publish_version_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.module_publish_version_resource.id
+  http_method = aws_api_gateway_method.module_publish_version_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+}
+
+resource "aws_api_gateway_method" "provider_advisories_method" {
+  rest_api_id   = aws_api_gateway_rest_api.api.id
+  resource_id   = aws_api_gateway_resource.advisories_type_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.namespace" = true,
+    "method.request.path.type"      = true,
+  }
+}
+
+resource "aws_api_gateway_integration" "provider_advisories_integration" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.advisories_type_resource.id
+  http_method = aws_api_gateway_method.provider_advisories_method.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.api_function.invoke_arn
+
+  cache_key_parameters = [
+    "method.request.path.namespace",
+    "method.request.path.type",
+  ]
+}
+
 resource "aws_api_gateway_method" "metadata_method" {
   rest_api_id   = aws_api_gateway_rest_api.api.id
   resource_id   = aws_api_gateway_resource.terraform_json.id
@@ -340,12 +680,42 @@ resource "aws_api_gateway_deployment" "deployment" {
     aws_api_gateway_method.provider_list_versions_method,
     aws_api_gateway_integration.provider_list_versions_integration,
 
+    aws_api_gateway_method.provider_resolve_method,
+    aws_api_gateway_integration.provider_resolve_integration,
+
+    aws_api_gateway_method.provider_publish_version_method,
+    aws_api_gateway_integration.provider_publish_version_integration,
+
+    aws_api_gateway_method.provider_get_method,
+    aws_api_gateway_integration.provider_get_integration,
+
+    aws_api_gateway_method.namespace_list_providers_method,
+    aws_api_gateway_integration.namespace_list_providers_integration,
+
+    aws_api_gateway_method.providers_search_method,
+    aws_api_gateway_integration.providers_search_integration,
+
+    aws_api_gateway_method.providers_batch_method,
+    aws_api_gateway_integration.providers_batch_integration,
+
     aws_api_gateway_method.module_download_method,
     aws_api_gateway_integration.module_download_integration,
 
     aws_api_gateway_method.module_list_versions_method,
     aws_api_gateway_integration.module_list_versions_integration,
 
+    aws_api_gateway_method.module_resolve_method,
+    aws_api_gateway_integration.module_resolve_integration,
+
+    aws_api_gateway_method.module_publish_version_method,
+    aws_api_gateway_integration.module_publish_version_integration,
+
+    aws_api_gateway_method.provider_advisories_method,
+    aws_api_gateway_integration.provider_advisories_integration,
+
+    aws_api_gateway_method.github_webhook_method,
+    aws_api_gateway_integration.github_webhook_integration,
+
     aws_api_gateway_method.metadata_method,
     aws_api_gateway_integration.metadata_integration,
 
@@ -8,4 +8,42 @@ resource "aws_dynamodb_table" "provider_versions" {
     name = "provider"
     type = "S"
   }
-}
\ No newline at end of file
+}
+
+// the providers found in each namespace by the crawl_provider_namespaces_function lambda, one item per provider
+resource "aws_dynamodb_table" "provider_namespaces" {
+  name         = "${var.domain_name}-provider-namespaces"
+  billing_mode = "PAY_PER_REQUEST"
+
+  hash_key  = "namespace"
+  range_key = "type"
+
+  attribute {
+    name = "namespace"
+    type = "S"
+  }
+
+  attribute {
+    name = "type"
+    type = "S"
+  }
+}
+
+// the leases of the populate_provider_versions_function lambda on populating the versions of each provider, which
+// are deleted once expired
+resource "aws_dynamodb_table" "provider_version_leases" {
+  name         = "${var.domain_name}-provider-version-leases"
+  billing_mode = "PAY_PER_REQUEST"
+
+  hash_key = "provider"
+
+  attribute {
+    name = "provider"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "lease_expires_at"
+    enabled        = true
+  }
+}
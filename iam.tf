@@ -19,9 +19,9 @@ data "aws_iam_policy_document" "github_api_token_secrets_iam_policy" {
       "secretsmanager:GetSecretValue",
     ]
 
-    resources = [
+    resources = concat([
       aws_secretsmanager_secret.github_api_token.arn,
-    ]
+    ], aws_secretsmanager_secret.publish_api_tokens[*].arn, aws_secretsmanager_secret.github_webhook_secret[*].arn, var.namespace_source_secret_arns)
   }
 }
 
@@ -100,14 +100,16 @@ data "aws_iam_policy_document" "dynamodb_policy" {
     ]
 
     resources = [
-      aws_dynamodb_table.provider_versions.arn
+      aws_dynamodb_table.provider_versions.arn,
+      aws_dynamodb_table.provider_namespaces.arn,
+      aws_dynamodb_table.provider_version_leases.arn,
     ]
   }
 }
 
 resource "aws_iam_policy" "lambda_dynamo_policy" {
   name        = "${var.domain_name}-RegistryLambdaDynamoPolicy"
-  description = "Policy for lambda to Read and Write to the provider versions DynamoDB table"
+  description = "Policy for lambda to Read and Write to the provider DynamoDB tables"
   policy      = data.aws_iam_policy_document.dynamodb_policy.json
 }
 
@@ -116,7 +118,7 @@ resource "aws_iam_role_policy_attachment" "lambda_dynamo_policy_attachment" {
   policy_arn = aws_iam_policy.lambda_dynamo_policy.arn
 }
 
-// allow the api_function lambda to invoke the populate_provider_versions_function lambda
+// allow the api_function and crawl_provider_namespaces_function lambdas to invoke the populate_provider_versions_function lambda
 data "aws_iam_policy_document" "populate_provider_versions_policy" {
   statement {
     effect = "Allow"
@@ -141,4 +143,61 @@ resource "aws_iam_role_policy_attachment" "lambda_populate_provider_versions_pol
   policy_arn = aws_iam_policy.lambda_populate_provider_versions_policy.arn
 }
 
+// allow the lambdas to send populate jobs to the queue, and the populate_provider_versions_function lambda to receive them
+data "aws_iam_policy_document" "populate_provider_versions_queue_policy" {
+  count = var.job_dispatcher == "sqs" ? 1 : 0
 
+  statement {
+    effect = "Allow"
+    actions = [
+      "sqs:SendMessage",
+      "sqs:ReceiveMessage",
+      "sqs:DeleteMessage",
+      "sqs:GetQueueAttributes",
+    ]
+
+    resources = [
+      aws_sqs_queue.populate_provider_versions[0].arn
+    ]
+  }
+}
+
+resource "aws_iam_policy" "lambda_populate_provider_versions_queue_policy" {
+  count = var.job_dispatcher == "sqs" ? 1 : 0
+
+  name        = "${var.domain_name}-RegistryLambdaPopulateProviderVersionsQueuePolicy"
+  description = "Policy for the registry lambdas to send and receive the jobs of the populate provider versions queue"
+  policy      = data.aws_iam_policy_document.populate_provider_versions_queue_policy[0].json
+}
+
+resource "aws_iam_role_policy_attachment" "lambda_populate_provider_versions_queue_policy_attachment" {
+  count = var.job_dispatcher == "sqs" ? 1 : 0
+
+  role       = aws_iam_role.lambda.id
+  policy_arn = aws_iam_policy.lambda_populate_provider_versions_queue_policy[0].arn
+}
+
+data "aws_iam_policy_document" "artifacts_policy" {
+  statement {
+    effect = "Allow"
+    actions = [
+      "s3:GetObject",
+      "s3:PutObject",
+    ]
+
+    resources = [
+      "${aws_s3_bucket.artifacts.arn}/*",
+    ]
+  }
+}
+
+resource "aws_iam_policy" "lambda_artifacts_policy" {
+  name        = "${var.domain_name}-RegistryLambdaArtifactsPolicy"
+  description = "Policy for lambda to read and write published artifacts"
+  policy      = data.aws_iam_policy_document.artifacts_policy.json
+}
+
+resource "aws_iam_role_policy_attachment" "lambda_artifacts_policy_attachment" {
+  role       = aws_iam_role.lambda.id
+  policy_arn = aws_iam_policy.lambda_artifacts_policy.arn
+}
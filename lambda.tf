@@ -1,6 +1,22 @@
+locals {
+  // the source mappings file is bundled next to the bootstrap binary of every lambda, which runs from /var/task
+  copy_source_mappings      = var.source_mappings_file == "" ? "" : "cp ${abspath(var.source_mappings_file)}"
+  source_mappings_file_path = var.source_mappings_file == "" ? "" : "/var/task/source_mappings.json"
+
+  // the advisories are only used by the API lambda
+  copy_advisories = var.advisories_path == "" ? "" : "rm -rf ../api_function_bootstrap/advisories && cp -r ${abspath(var.advisories_path)} ../api_function_bootstrap/advisories"
+  advisories_path = var.advisories_path == "" ? "" : "/var/task/advisories"
+
+  // the advisories feed is ingested into the artifacts bucket on a schedule, and read from there by the API lambda
+  ingest_advisories     = var.advisories_feed_url != ""
+  advisories_object_key = local.ingest_advisories ? "_registry/advisories.json" : ""
+
+  populate_provider_versions_queue_url = join("", aws_sqs_queue.populate_provider_versions[*].url)
+}
+
 resource "null_resource" "api_function_binary" {
   provisioner "local-exec" {
-    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../api_function_bootstrap/bootstrap ./lambda/api"
+    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../api_function_bootstrap/bootstrap ./lambda/api${local.copy_source_mappings == "" ? "" : " && ${local.copy_source_mappings} ../api_function_bootstrap/source_mappings.json"}${local.copy_advisories == "" ? "" : " && ${local.copy_advisories}"}"
     working_dir = "./src"
   }
 
@@ -11,7 +27,31 @@ resource "null_resource" "api_function_binary" {
 
 resource "null_resource" "populate_provider_versions_binary" {
   provisioner "local-exec" {
-    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../populate_provider_versions_bootstrap/bootstrap ./lambda/populate_provider_versions"
+    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../populate_provider_versions_bootstrap/bootstrap ./lambda/populate_provider_versions${local.copy_source_mappings == "" ? "" : " && ${local.copy_source_mappings} ../populate_provider_versions_bootstrap/source_mappings.json"}"
+    working_dir = "./src"
+  }
+
+  triggers = {
+    always_run = timestamp()
+  }
+}
+
+resource "null_resource" "crawl_provider_namespaces_binary" {
+  provisioner "local-exec" {
+    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../crawl_provider_namespaces_bootstrap/bootstrap ./lambda/crawl_provider_namespaces${local.copy_source_mappings == "" ? "" : " && ${local.copy_source_mappings} ../crawl_provider_namespaces_bootstrap/source_mappings.json"}"
+    working_dir = "./src"
+  }
+
+  triggers = {
+    always_run = timestamp()
+  }
+}
+
+resource "null_resource" "ingest_advisories_binary" {
+  count = local.ingest_advisories ? 1 : 0
+
+  provisioner "local-exec" {
+    command     = "GOOS=linux GOARCH=amd64 CGO_ENABLED=0 GOFLAGS=-trimpath go build -mod=readonly -tags lambda.norpc -ldflags='-s -w' -o ../ingest_advisories_bootstrap/bootstrap ./lambda/ingest_advisories"
     working_dir = "./src"
   }
 
@@ -24,7 +64,7 @@ data "archive_file" "api_function_archive" {
   depends_on = [null_resource.api_function_binary]
 
   type        = "zip"
-  source_file = "./api_function_bootstrap/bootstrap"
+  source_dir  = "./api_function_bootstrap"
   output_path = "api_bootstrap.zip"
 }
 
@@ -32,10 +72,27 @@ data "archive_file" "populate_provider_versions_archive" {
   depends_on = [null_resource.populate_provider_versions_binary]
 
   type        = "zip"
-  source_file = "./populate_provider_versions_bootstrap/bootstrap"
+  source_dir  = "./populate_provider_versions_bootstrap"
   output_path = "populate_provider_versions_bootstrap.zip"
 }
 
+data "archive_file" "crawl_provider_namespaces_archive" {
+  depends_on = [null_resource.crawl_provider_namespaces_binary]
+
+  type        = "zip"
+  source_dir  = "./crawl_provider_namespaces_bootstrap"
+  output_path = "crawl_provider_namespaces_bootstrap.zip"
+}
+
+data "archive_file" "ingest_advisories_archive" {
+  count      = local.ingest_advisories ? 1 : 0
+  depends_on = [null_resource.ingest_advisories_binary]
+
+  type        = "zip"
+  source_dir  = "./ingest_advisories_bootstrap"
+  output_path = "ingest_advisories_bootstrap.zip"
+}
+
 // create the lambda function from zip file
 resource "aws_lambda_function" "api_function" {
   function_name = "${replace(var.domain_name, ".", "-")}-registry-handler"
@@ -60,9 +117,24 @@ resource "aws_lambda_function" "api_function" {
     variables = {
       GITHUB_TOKEN_SECRET_ASM_NAME             = aws_secretsmanager_secret.github_api_token.name
       PROVIDER_NAMESPACE_REDIRECTS             = jsonencode(var.provider_namespace_redirects)
+      SOURCE_MAPPINGS_FILE                     = local.source_mappings_file_path
+      PROVIDER_UNSIGNED_RELEASE_POLICIES       = jsonencode(var.provider_unsigned_release_policies)
+      YANKED_VERSIONS                          = jsonencode(var.yanked_versions)
+      WARNINGS_OBJECT_KEY                      = "_registry/warnings.json"
+      ADVISORIES_PATH                          = local.advisories_path
+      ADVISORIES_OBJECT_KEY                    = local.advisories_object_key
+      PROVIDER_NAMESPACE_TIERS                 = jsonencode(var.provider_namespace_tiers)
       PROVIDER_VERSIONS_TABLE_NAME             = aws_dynamodb_table.provider_versions.name
+      PROVIDER_NAMESPACES_TABLE_NAME           = aws_dynamodb_table.provider_namespaces.name
       POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME = aws_lambda_function.populate_provider_versions_function.function_name
+      POPULATE_PROVIDER_VERSIONS_QUEUE_URL     = local.populate_provider_versions_queue_url
+      JOB_DISPATCHER                           = var.job_dispatcher
       GITHUB_API_GW_URL                        = var.domain_name
+      NAMESPACE_SOURCES                        = jsonencode(var.namespace_sources)
+      ARTIFACTS_BUCKET_NAME                    = aws_s3_bucket.artifacts.id
+      ARTIFACTS_URL                            = "https://${aws_s3_bucket.artifacts.bucket_regional_domain_name}"
+      PUBLISH_TOKENS_SECRET_ASM_NAME           = join("", aws_secretsmanager_secret.publish_api_tokens[*].name)
+      GITHUB_WEBHOOK_SECRET_ASM_NAME           = join("", aws_secretsmanager_secret.github_webhook_secret[*].name)
     }
   }
 }
@@ -94,13 +166,136 @@ resource "aws_lambda_function" "populate_provider_versions_function" {
 
   environment {
     variables = {
-      PROVIDER_VERSIONS_TABLE_NAME = aws_dynamodb_table.provider_versions.name
-      GITHUB_TOKEN_SECRET_ASM_NAME = aws_secretsmanager_secret.github_api_token.name
-      GITHUB_API_GW_URL            = var.domain_name
+      PROVIDER_VERSIONS_TABLE_NAME   = aws_dynamodb_table.provider_versions.name
+      PROVIDER_NAMESPACES_TABLE_NAME = aws_dynamodb_table.provider_namespaces.name
+      PROVIDER_LEASES_TABLE_NAME     = aws_dynamodb_table.provider_version_leases.name
+      GITHUB_TOKEN_SECRET_ASM_NAME   = aws_secretsmanager_secret.github_api_token.name
+      GITHUB_API_GW_URL              = var.domain_name
+      SOURCE_MAPPINGS_FILE           = local.source_mappings_file_path
+      NAMESPACE_SOURCES              = jsonencode(var.namespace_sources)
+      ARTIFACTS_BUCKET_NAME          = aws_s3_bucket.artifacts.id
+      ARTIFACTS_URL                  = "https://${aws_s3_bucket.artifacts.bucket_regional_domain_name}"
+      JOB_DISPATCHER                 = var.job_dispatcher
+    }
+  }
+}
+
+// create the lambda function from zip file
+resource "aws_lambda_function" "crawl_provider_namespaces_function" {
+  function_name = "${replace(var.domain_name, ".", "-")}-crawl-provider-namespaces"
+  description   = "A lambda to discover the providers of whole namespaces and populate their versions"
+  role          = aws_iam_role.lambda.arn
+  handler       = "crawl-provider-namespaces"
+  memory_size   = 128
+  timeout       = 15 * 60
+
+  filename         = data.archive_file.crawl_provider_namespaces_archive.output_path
+  source_code_hash = data.archive_file.crawl_provider_namespaces_archive.output_base64sha256
+
+  runtime = "provided.al2"
+
+  tracing_config {
+    mode = "Active"
+  }
+
+  environment {
+    variables = {
+      PROVIDER_VERSIONS_TABLE_NAME             = aws_dynamodb_table.provider_versions.name
+      PROVIDER_NAMESPACES_TABLE_NAME           = aws_dynamodb_table.provider_namespaces.name
+      PROVIDER_NAMESPACE_REDIRECTS             = jsonencode(var.provider_namespace_redirects)
+      SOURCE_MAPPINGS_FILE                     = local.source_mappings_file_path
+      POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME = aws_lambda_function.populate_provider_versions_function.function_name
+      POPULATE_PROVIDER_VERSIONS_QUEUE_URL     = local.populate_provider_versions_queue_url
+      JOB_DISPATCHER                           = var.job_dispatcher
+      CRAWL_NAMESPACES                         = jsonencode(var.crawl_namespaces)
+      GITHUB_TOKEN_SECRET_ASM_NAME             = aws_secretsmanager_secret.github_api_token.name
+      GITHUB_API_GW_URL                        = var.domain_name
+      NAMESPACE_SOURCES                        = jsonencode(var.namespace_sources)
+      ARTIFACTS_BUCKET_NAME                    = aws_s3_bucket.artifacts.id
+      ARTIFACTS_URL                            = "https://${aws_s3_bucket.artifacts.bucket_regional_domain_name}"
+    }
+  }
+}
+
+resource "aws_cloudwatch_event_rule" "crawl_provider_namespaces_schedule" {
+  name                = "${replace(var.domain_name, ".", "-")}-crawl-provider-namespaces"
+  description         = "Periodically crawl namespaces for new providers"
+  schedule_expression = var.crawl_schedule_expression
+}
+
+resource "aws_cloudwatch_event_target" "crawl_provider_namespaces_schedule" {
+  rule = aws_cloudwatch_event_rule.crawl_provider_namespaces_schedule.name
+  arn  = aws_lambda_function.crawl_provider_namespaces_function.arn
+}
+
+resource "aws_lambda_permission" "crawl_provider_namespaces_schedule_permission" {
+  statement_id  = "AllowEventBridgeInvoke"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.crawl_provider_namespaces_function.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.crawl_provider_namespaces_schedule.arn
+}
+
+// create the lambda function from zip file
+resource "aws_lambda_function" "ingest_advisories_function" {
+  count = local.ingest_advisories ? 1 : 0
+
+  function_name = "${replace(var.domain_name, ".", "-")}-ingest-advisories"
+  description   = "A lambda to store the advisories of the security advisories feed that affect the registry"
+  role          = aws_iam_role.lambda.arn
+  handler       = "ingest-advisories"
+  // whole feeds are downloaded and parsed in memory
+  memory_size = 1024
+  timeout     = 5 * 60
+
+  filename         = data.archive_file.ingest_advisories_archive[0].output_path
+  source_code_hash = data.archive_file.ingest_advisories_archive[0].output_base64sha256
+
+  runtime = "provided.al2"
+
+  tracing_config {
+    mode = "Active"
+  }
+
+  environment {
+    variables = {
+      PROVIDER_VERSIONS_TABLE_NAME   = aws_dynamodb_table.provider_versions.name
+      PROVIDER_NAMESPACES_TABLE_NAME = aws_dynamodb_table.provider_namespaces.name
+      GITHUB_TOKEN_SECRET_ASM_NAME   = aws_secretsmanager_secret.github_api_token.name
+      GITHUB_API_GW_URL              = var.domain_name
+      ADVISORIES_FEED_URL            = var.advisories_feed_url
+      ADVISORIES_OBJECT_KEY          = local.advisories_object_key
+      ARTIFACTS_BUCKET_NAME          = aws_s3_bucket.artifacts.id
+      ARTIFACTS_URL                  = "https://${aws_s3_bucket.artifacts.bucket_regional_domain_name}"
     }
   }
 }
 
+resource "aws_cloudwatch_event_rule" "ingest_advisories_schedule" {
+  count = local.ingest_advisories ? 1 : 0
+
+  name                = "${replace(var.domain_name, ".", "-")}-ingest-advisories"
+  description         = "Periodically ingest the security advisories feed"
+  schedule_expression = var.advisories_schedule_expression
+}
+
+resource "aws_cloudwatch_event_target" "ingest_advisories_schedule" {
+  count = local.ingest_advisories ? 1 : 0
+
+  rule = aws_cloudwatch_event_rule.ingest_advisories_schedule[0].name
+  arn  = aws_lambda_function.ingest_advisories_function[0].arn
+}
+
+resource "aws_lambda_permission" "ingest_advisories_schedule_permission" {
+  count = local.ingest_advisories ? 1 : 0
+
+  statement_id  = "AllowEventBridgeInvoke"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.ingest_advisories_function[0].function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.ingest_advisories_schedule[0].arn
+}
+
 resource "aws_lambda_permission" "api_gateway_invoke_lambda_permission" {
   statement_id  = "AllowAPIGatewayInvoke"
   action        = "lambda:InvokeFunction"
@@ -0,0 +1,37 @@
+// holds provider and module versions published directly to the registry
+resource "aws_s3_bucket" "artifacts" {
+  bucket = "${var.domain_name}-artifacts"
+}
+
+resource "aws_s3_bucket_public_access_block" "artifacts" {
+  bucket = aws_s3_bucket.artifacts.id
+
+  block_public_acls       = true
+  ignore_public_acls      = true
+  block_public_policy     = false
+  restrict_public_buckets = false
+}
+
+// published artifacts are downloaded by clients directly from the bucket
+data "aws_iam_policy_document" "artifacts_public_read" {
+  statement {
+    effect  = "Allow"
+    actions = ["s3:GetObject"]
+
+    principals {
+      type        = "*"
+      identifiers = ["*"]
+    }
+
+    resources = [
+      "${aws_s3_bucket.artifacts.arn}/*",
+    ]
+  }
+}
+
+resource "aws_s3_bucket_policy" "artifacts" {
+  bucket = aws_s3_bucket.artifacts.id
+  policy = data.aws_iam_policy_document.artifacts_public_read.json
+
+  depends_on = [aws_s3_bucket_public_access_block.artifacts]
+}
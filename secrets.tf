@@ -6,3 +6,25 @@ resource "aws_secretsmanager_secret_version" "github_api_token" {
   secret_id     = aws_secretsmanager_secret.github_api_token.id
   secret_string = var.github_api_token
 }
+
+resource "aws_secretsmanager_secret" "publish_api_tokens" {
+  count = nonsensitive(length(var.publish_api_tokens)) > 0 ? 1 : 0
+  name  = "${var.domain_name}-publish_api_tokens"
+}
+
+resource "aws_secretsmanager_secret_version" "publish_api_tokens" {
+  count         = nonsensitive(length(var.publish_api_tokens)) > 0 ? 1 : 0
+  secret_id     = aws_secretsmanager_secret.publish_api_tokens[0].id
+  secret_string = jsonencode(var.publish_api_tokens)
+}
+
+resource "aws_secretsmanager_secret" "github_webhook_secret" {
+  count = var.github_webhook_secret != "" ? 1 : 0
+  name  = "${var.domain_name}-github_webhook_secret"
+}
+
+resource "aws_secretsmanager_secret_version" "github_webhook_secret" {
+  count         = var.github_webhook_secret != "" ? 1 : 0
+  secret_id     = aws_secretsmanager_secret.github_webhook_secret[0].id
+  secret_string = var.github_webhook_secret
+}
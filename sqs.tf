@@ -0,0 +1,19 @@
+// the jobs populating provider versions are sent to this queue when var.job_dispatcher is "sqs", rather than
+// invoking the populate_provider_versions_function lambda directly
+resource "aws_sqs_queue" "populate_provider_versions" {
+  count = var.job_dispatcher == "sqs" ? 1 : 0
+
+  name       = "${replace(var.domain_name, ".", "-")}-populate-provider-versions.fifo"
+  fifo_queue = true
+  // messages are redelivered once the populate_provider_versions_function lambda has timed out
+  visibility_timeout_seconds = aws_lambda_function.populate_provider_versions_function.timeout
+}
+
+resource "aws_lambda_event_source_mapping" "populate_provider_versions_queue" {
+  count = var.job_dispatcher == "sqs" ? 1 : 0
+
+  event_source_arn        = aws_sqs_queue.populate_provider_versions[0].arn
+  function_name           = aws_lambda_function.populate_provider_versions_function.arn
+  batch_size              = 1
+  function_response_types = ["ReportBatchItemFailures"]
+}
@@ -0,0 +1,247 @@
+// Package advisories matches security advisories in the OSV format (https://ossf.github.io/osv-schema/) to
+// provider and module versions.
+//
+// Advisories name the providers ("<namespace>/<type>") and modules ("<namespace>/<name>/<system>") they affect by
+// their effective address, after any redirects, as packages of the "OpenTofu" ecosystem:
+//
+//	{
+//	  "id": "GHSA-xxxx-xxxx-xxxx",
+//	  "modified": "2023-11-02T10:00:00Z",
+//	  "summary": "Credentials are written to the plan in clear text",
+//	  "affected": [{
+//	    "package": {"ecosystem": "OpenTofu", "name": "acme/widget"},
+//	    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.1"}]}]
+//	  }]
+//	}
+//
+// Packages of other ecosystems, withdrawn advisories and GIT ranges are ignored.
+package advisories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Ecosystem is the OSV ecosystem of providers and modules.
+const Ecosystem = "OpenTofu"
+
+// Advisory is an OSV entry. Only the fields used by the registry are kept.
+type Advisory struct {
+	ID         string      `json:"id"`
+	Modified   time.Time   `json:"modified"`
+	Published  *time.Time  `json:"published,omitempty"`
+	Withdrawn  *time.Time  `json:"withdrawn,omitempty"`
+	Aliases    []string    `json:"aliases,omitempty"`
+	Summary    string      `json:"summary,omitempty"`
+	Details    string      `json:"details,omitempty"`
+	Severity   []Severity  `json:"severity,omitempty"`
+	Affected   []Affected  `json:"affected"`
+	References []Reference `json:"references,omitempty"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Affected lists the versions of a package affected by an advisory, as version ranges and as individual versions.
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range is a list of events introducing and fixing the vulnerability, evaluated in version order.
+type Range struct {
+	Type   string  `json:"type"`
+	Repo   string  `json:"repo,omitempty"`
+	Events []Event `json:"events"`
+}
+
+// Event is one of introduced, fixed, last_affected or limit. The introduced version "0" is before any other version.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Match is an advisory along with the served versions of a provider or module that it affects.
+type Match struct {
+	ID       string     `json:"id"`
+	Aliases  []string   `json:"aliases,omitempty"`
+	Summary  string     `json:"summary,omitempty"`
+	Severity []Severity `json:"severity,omitempty"`
+	Versions []string   `json:"versions"`
+}
+
+// Warning describes the match to clients, which show it at "tofu init" time.
+func (m Match) Warning() string {
+	summary := m.Summary
+	if summary == "" {
+		summary = "see https://osv.dev/vulnerability/" + m.ID
+	}
+	return fmt.Sprintf("Security advisory %s affects version(s) %s: %s", m.ID, strings.Join(m.Versions, ", "), summary)
+}
+
+// Database indexes advisories by the address of the providers and modules they affect.
+// A nil Database has no advisories.
+type Database struct {
+	byAddress map[string][]Advisory
+}
+
+// New indexes the given advisories, leaving out withdrawn ones. If several advisories have the same ID,
+// the most recently modified one is kept.
+func New(advisories []Advisory) *Database {
+	latest := make(map[string]Advisory, len(advisories))
+	for _, a := range advisories {
+		if previous, ok := latest[a.ID]; !ok || a.Modified.After(previous.Modified) {
+			latest[a.ID] = a
+		}
+	}
+
+	ids := make([]string, 0, len(latest))
+	for id := range latest {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	db := &Database{byAddress: make(map[string][]Advisory)}
+	for _, id := range ids {
+		a := latest[id]
+		if a.Withdrawn != nil {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, affected := range a.Affected {
+			if affected.Package.Ecosystem != Ecosystem || seen[affected.Package.Name] {
+				continue
+			}
+			seen[affected.Package.Name] = true
+			db.byAddress[affected.Package.Name] = append(db.byAddress[affected.Package.Name], a)
+		}
+	}
+	return db
+}
+
+// For returns the advisories affecting any version of a provider or module, ordered by ID.
+func (d *Database) For(address string) []Advisory {
+	if d == nil {
+		return nil
+	}
+	return d.byAddress[address]
+}
+
+// Match returns the advisories of a provider or module that affect any of the given versions.
+func (d *Database) Match(address string, versions []string) []Match {
+	var matches []Match
+	for _, a := range d.For(address) {
+		var affected []string
+		for _, v := range versions {
+			if a.Affects(address, v) {
+				affected = append(affected, v)
+			}
+		}
+		if len(affected) == 0 {
+			continue
+		}
+
+		matches = append(matches, Match{
+			ID:       a.ID,
+			Aliases:  a.Aliases,
+			Summary:  a.Summary,
+			Severity: a.Severity,
+			Versions: affected,
+		})
+	}
+	return matches
+}
+
+// Affects returns true if the advisory affects the given version of a provider or module.
+func (a Advisory) Affects(address, rawVersion string) bool {
+	v, err := version.NewVersion(rawVersion)
+	if err != nil {
+		return false
+	}
+
+	for _, affected := range a.Affected {
+		if affected.Package.Ecosystem != Ecosystem || affected.Package.Name != address {
+			continue
+		}
+		for _, listed := range affected.Versions {
+			if strings.TrimPrefix(listed, "v") == strings.TrimPrefix(rawVersion, "v") {
+				return true
+			}
+		}
+		for _, r := range affected.Ranges {
+			if r.affects(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// affects evaluates the events of a SEMVER or ECOSYSTEM range in version order: the version is affected if the
+// last event at or below it introduced the vulnerability. Events with versions that cannot be parsed are ignored.
+func (r Range) affects(v *version.Version) bool {
+	if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+		return false
+	}
+
+	type event struct {
+		version    *version.Version
+		introduced bool
+		// lastAffected events end the range after their version rather than at it
+		lastAffected bool
+	}
+
+	var events []event
+	for _, e := range r.Events {
+		raw, introduced, lastAffected := e.Fixed, false, false
+		switch {
+		case e.Introduced != "":
+			raw, introduced = e.Introduced, true
+		case e.LastAffected != "":
+			raw, lastAffected = e.LastAffected, true
+		case e.Fixed == "":
+			// limit events only matter for GIT ranges
+			continue
+		}
+
+		parsed, err := version.NewVersion(strings.TrimPrefix(raw, "v"))
+		if err != nil {
+			continue
+		}
+		events = append(events, event{version: parsed, introduced: introduced, lastAffected: lastAffected})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].version.LessThan(events[j].version) })
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.lastAffected:
+			if v.GreaterThan(e.version) {
+				affected = false
+			}
+		case v.GreaterThanOrEqual(e.version):
+			affected = e.introduced
+		}
+	}
+	return affected
+}
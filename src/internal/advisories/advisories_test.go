@@ -0,0 +1,87 @@
+package advisories_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/advisories"
+)
+
+func affected(name string, ranges []advisories.Range, versions ...string) advisories.Affected {
+	return advisories.Affected{
+		Package:  advisories.Package{Ecosystem: advisories.Ecosystem, Name: name},
+		Ranges:   ranges,
+		Versions: versions,
+	}
+}
+
+func TestAffects(t *testing.T) {
+	advisory := advisories.Advisory{
+		ID: "GHSA-1",
+		Affected: []advisories.Affected{
+			affected("acme/widget", []advisories.Range{
+				{Type: "SEMVER", Events: []advisories.Event{{Introduced: "0"}, {Fixed: "1.2.1"}}},
+				{Type: "SEMVER", Events: []advisories.Event{{Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}},
+				{Type: "GIT", Events: []advisories.Event{{Introduced: "abc"}}},
+			}, "3.0.0"),
+		},
+	}
+
+	tests := map[string]bool{
+		"1.0.0": true,
+		"1.2.0": true,
+		"1.2.1": false,
+		"1.5.0": false,
+		"2.0.0": true,
+		"2.1.0": true,
+		"2.1.1": false,
+		"3.0.0": true,
+		"3.0.1": false,
+		"bad":   false,
+	}
+	for v, want := range tests {
+		if got := advisory.Affects("acme/widget", v); got != want {
+			t.Errorf("Affects(%s) = %v, want %v", v, got, want)
+		}
+	}
+
+	if advisory.Affects("acme/other", "1.0.0") {
+		t.Error("expected other packages not to be affected")
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	introduced := []advisories.Range{{Type: "SEMVER", Events: []advisories.Event{{Introduced: "0"}, {Fixed: "2.0.0"}}}}
+	withdrawn := time.Now()
+	db := advisories.New([]advisories.Advisory{
+		{ID: "GHSA-2", Summary: "old", Modified: time.Unix(1, 0), Affected: []advisories.Affected{affected("acme/widget", introduced)}},
+		{ID: "GHSA-2", Summary: "updated", Modified: time.Unix(2, 0), Affected: []advisories.Affected{affected("acme/widget", introduced)}},
+		{ID: "GHSA-1", Summary: "module", Affected: []advisories.Affected{affected("acme/vpc/aws", nil, "1.0.0"), affected("acme/widget", nil, "1.5.0")}},
+		{ID: "GHSA-3", Withdrawn: &withdrawn, Affected: []advisories.Affected{affected("acme/widget", introduced)}},
+		{ID: "GO-1", Affected: []advisories.Affected{{Package: advisories.Package{Ecosystem: "Go", Name: "acme/widget"}, Versions: []string{"1.0.0"}}}},
+	})
+
+	if got := len(db.For("acme/widget")); got != 2 {
+		t.Errorf("expected 2 advisories for acme/widget, got %d", got)
+	}
+
+	matches := db.Match("acme/widget", []string{"2.1.0", "1.5.0", "1.0.0"})
+	expected := []advisories.Match{
+		{ID: "GHSA-1", Summary: "module", Versions: []string{"1.5.0"}},
+		{ID: "GHSA-2", Summary: "updated", Versions: []string{"1.5.0", "1.0.0"}},
+	}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("Match() = %+v, want %+v", matches, expected)
+	}
+
+	want := "Security advisory GHSA-2 affects version(s) 1.5.0, 1.0.0: updated"
+	if got := matches[1].Warning(); got != want {
+		t.Errorf("Warning() = %q, want %q", got, want)
+	}
+
+	var nilDB *advisories.Database
+	if got := nilDB.Match("acme/widget", []string{"1.0.0"}); got != nil {
+		t.Errorf("expected no matches without a database, got %v", got)
+	}
+}
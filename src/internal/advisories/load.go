@@ -0,0 +1,247 @@
+package advisories
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	feedRequestTimeout = 2 * time.Minute
+	// maxFeedSize bounds the size of a downloaded feed, which is held in memory to be parsed.
+	maxFeedSize = 256 << 20 // 256 MiB
+	// maxArchiveEntrySize bounds the uncompressed size of a file in an archived feed, as a small archive can
+	// decompress to far more than maxFeedSize.
+	maxArchiveEntrySize = 4 << 20 // 4 MiB
+)
+
+// Parse reads either a single OSV entry or a JSON list of entries.
+func Parse(contents []byte) ([]Advisory, error) {
+	var advisories []Advisory
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &advisories); err != nil {
+			return nil, fmt.Errorf("could not parse advisories: %w", err)
+		}
+	} else {
+		var advisory Advisory
+		if err := json.Unmarshal(trimmed, &advisory); err != nil {
+			return nil, fmt.Errorf("could not parse advisory: %w", err)
+		}
+		advisories = []Advisory{advisory}
+	}
+
+	for _, a := range advisories {
+		if a.ID == "" {
+			return nil, fmt.Errorf("advisories must have an id")
+		}
+	}
+	return advisories, nil
+}
+
+// LoadPath reads the advisories in a JSON file, or in all the JSON files below a directory.
+// An empty path has no advisories.
+func LoadPath(path string) ([]Advisory, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var advisories []Advisory
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// the path itself is read whatever its name
+		if entry.IsDir() || (file != path && !strings.HasSuffix(file, ".json")) {
+			return nil
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("could not read advisories file: %w", err)
+		}
+		parsed, err := Parse(contents)
+		if err != nil {
+			return fmt.Errorf("invalid advisories file %s: %w", file, err)
+		}
+		advisories = append(advisories, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
+
+// ParseFeed reads the contents of an advisories feed, which is either a JSON list of entries or a zip archive
+// of JSON files, such as the archives published by https://osv.dev. Invalid files in an archive are skipped.
+func ParseFeed(contents []byte) ([]Advisory, error) {
+	if !bytes.HasPrefix(contents, []byte("PK\x03\x04")) {
+		return Parse(contents)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nil, fmt.Errorf("could not read advisories archive: %w", err)
+	}
+
+	var advisories []Advisory
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		parsed, err := parseArchiveFile(file)
+		if err != nil {
+			slog.Warn("Skipping invalid advisory", "file", file.Name, "error", err)
+			continue
+		}
+		advisories = append(advisories, parsed...)
+	}
+	return advisories, nil
+}
+
+func parseArchiveFile(file *zip.File) ([]Advisory, error) {
+	if file.UncompressedSize64 > maxArchiveEntrySize {
+		return nil, fmt.Errorf("the file is larger than %d bytes", maxArchiveEntrySize)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// the size in the archive's header is not trusted, so the contents are limited as they are read
+	contents, err := io.ReadAll(io.LimitReader(reader, maxArchiveEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) > maxArchiveEntrySize {
+		return nil, fmt.Errorf("the file is larger than %d bytes", maxArchiveEntrySize)
+	}
+	return Parse(contents)
+}
+
+// Relevant returns the advisories that affect providers or modules and are not withdrawn, keeping only the packages
+// of the OpenTofu ecosystem. Feeds covering several ecosystems are reduced to these before being stored.
+func Relevant(advisories []Advisory) []Advisory {
+	var relevant []Advisory
+	for _, a := range advisories {
+		if a.Withdrawn != nil {
+			continue
+		}
+
+		var affected []Affected
+		for _, af := range a.Affected {
+			if af.Package.Ecosystem == Ecosystem {
+				affected = append(affected, af)
+			}
+		}
+		if len(affected) == 0 {
+			continue
+		}
+
+		a.Affected = affected
+		relevant = append(relevant, a)
+	}
+	return relevant
+}
+
+// FeedFetcher returns a function downloading the advisories feed at feedURL. Feeds larger than maxFeedSize are refused.
+// The feed is meant to be ingested by a scheduled job rather than while serving requests, as it can be large.
+func FeedFetcher(feedURL string) func(ctx context.Context) ([]byte, error) {
+	httpClient := xray.Client(&http.Client{Timeout: feedRequestTimeout})
+
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch advisories feed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %d fetching advisories feed", resp.StatusCode)
+		}
+
+		contents, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read advisories feed: %w", err)
+		}
+		if len(contents) > maxFeedSize {
+			return nil, fmt.Errorf("the advisories feed is larger than %d bytes", maxFeedSize)
+		}
+		return contents, nil
+	}
+}
+
+// Loader keeps the advisories in memory between requests. The local advisories are read once, while the advisories
+// ingested from the feed, if any, are read again once the advisories are older than maxAge.
+type Loader struct {
+	local     []Advisory
+	fetchFeed func(ctx context.Context) ([]byte, error)
+	maxAge    time.Duration
+
+	mu       sync.Mutex
+	db       *Database
+	loadedAt time.Time
+}
+
+// NewLoader creates a Loader for the given local advisories and feed. fetchFeed returns the advisories ingested from
+// the feed, as a JSON list, and may be nil if there is no feed.
+func NewLoader(local []Advisory, fetchFeed func(ctx context.Context) ([]byte, error), maxAge time.Duration) *Loader {
+	return &Loader{local: local, fetchFeed: fetchFeed, maxAge: maxAge, db: New(local)}
+}
+
+// Database returns the current advisories. If the ingested advisories cannot be read, the previously loaded ones keep
+// being used. A nil Loader has no advisories.
+func (l *Loader) Database(ctx context.Context) *Database {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fetchFeed == nil || (!l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.maxAge) {
+		return l.db
+	}
+
+	// don't retry on every request if the feed is unavailable
+	l.loadedAt = time.Now()
+
+	contents, err := l.fetchFeed(ctx)
+	if err != nil {
+		slog.Error("Error fetching advisories feed, using the previous advisories", "error", err)
+		return l.db
+	}
+	var feed []Advisory
+	if len(contents) > 0 { // nothing has been ingested from the feed yet otherwise
+		feed, err = Parse(contents)
+	}
+	if err != nil {
+		slog.Error("Invalid advisories feed, using the previous advisories", "error", err)
+		return l.db
+	}
+
+	l.db = New(append(append([]Advisory{}, l.local...), feed...))
+	slog.Info("Loaded advisories", "local", len(l.local), "feed", len(feed))
+	return l.db
+}
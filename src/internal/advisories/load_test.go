@@ -0,0 +1,141 @@
+package advisories_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/advisories"
+)
+
+const widgetAdvisory = `{"id": "GHSA-1", "affected": [{"package": {"ecosystem": "OpenTofu", "name": "acme/widget"}, "versions": ["1.0.0"]}]}`
+
+func TestLoadPath(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"GHSA-1.json":        widgetAdvisory,
+		"nested/list.json":   `[{"id": "GHSA-2", "affected": []}, {"id": "GHSA-3", "affected": []}]`,
+		"nested/README.md":   "not an advisory",
+		"nested/GHSA-4.json": `{"id": "GHSA-4"}`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := advisories.LoadPath(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(loaded) != 4 {
+		t.Errorf("expected 4 advisories, got %d", len(loaded))
+	}
+
+	loaded, err = advisories.LoadPath(filepath.Join(dir, "GHSA-1.json"))
+	if err != nil || len(loaded) != 1 {
+		t.Errorf("expected 1 advisory, got %d (error: %v)", len(loaded), err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"summary": "no id"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := advisories.LoadPath(dir); err == nil {
+		t.Error("expected an error for an advisory without an id")
+	}
+}
+
+func TestParseFeed(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	// valid, but too large to be read from an archive
+	oversized := widgetAdvisory + strings.Repeat(" ", 5<<20)
+	for name, contents := range map[string]string{"GHSA-1.json": widgetAdvisory, "broken.json": "{", "oversized.json": oversized} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := advisories.ParseFeed(buf.Bytes())
+	if err != nil || len(loaded) != 1 || loaded[0].ID != "GHSA-1" {
+		t.Errorf("unexpected advisories %+v (error: %v)", loaded, err)
+	}
+
+	loaded, err = advisories.ParseFeed([]byte("[" + widgetAdvisory + "]"))
+	if err != nil || len(loaded) != 1 {
+		t.Errorf("unexpected advisories %+v (error: %v)", loaded, err)
+	}
+}
+
+func TestLoader(t *testing.T) {
+	local := []advisories.Advisory{{ID: "LOCAL-1", Affected: []advisories.Affected{affected("acme/widget", nil, "1.0.0")}}}
+	feed := "[" + widgetAdvisory + "]"
+	var feedErr error
+	loader := advisories.NewLoader(local, func(_ context.Context) ([]byte, error) {
+		return []byte(feed), feedErr
+	}, time.Hour)
+
+	ctx := context.Background()
+	if got := len(loader.Database(ctx).For("acme/widget")); got != 2 {
+		t.Errorf("expected the local and feed advisories, got %d", got)
+	}
+
+	// the feed is not fetched again before the advisories expire
+	feedErr = errors.New("unavailable")
+	if got := len(loader.Database(ctx).For("acme/widget")); got != 2 {
+		t.Errorf("expected the previous advisories, got %d", got)
+	}
+
+	withoutFeed := advisories.NewLoader(local, nil, time.Hour)
+	if got := len(withoutFeed.Database(ctx).For("acme/widget")); got != 1 {
+		t.Errorf("expected the local advisories, got %d", got)
+	}
+}
+
+func TestLoaderWithoutIngestedFeed(t *testing.T) {
+	local := []advisories.Advisory{{ID: "LOCAL-1", Affected: []advisories.Affected{affected("acme/widget", nil, "1.0.0")}}}
+	loader := advisories.NewLoader(local, func(_ context.Context) ([]byte, error) {
+		return nil, nil
+	}, time.Hour)
+
+	if got := len(loader.Database(context.Background()).For("acme/widget")); got != 1 {
+		t.Errorf("expected the local advisories, got %d", got)
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	withdrawn := time.Now()
+	feed := []advisories.Advisory{
+		{ID: "GHSA-1", Affected: []advisories.Affected{
+			affected("acme/widget", nil, "1.0.0"),
+			{Package: advisories.Package{Ecosystem: "npm", Name: "widget"}},
+		}},
+		{ID: "GHSA-2", Affected: []advisories.Affected{{Package: advisories.Package{Ecosystem: "PyPI", Name: "widget"}}}},
+		{ID: "GHSA-3", Withdrawn: &withdrawn, Affected: []advisories.Affected{affected("acme/widget", nil, "1.0.0")}},
+	}
+
+	relevant := advisories.Relevant(feed)
+	if len(relevant) != 1 || relevant[0].ID != "GHSA-1" {
+		t.Fatalf("expected only GHSA-1, got %+v", relevant)
+	}
+	if len(relevant[0].Affected) != 1 || relevant[0].Affected[0].Package.Ecosystem != advisories.Ecosystem {
+		t.Errorf("expected only the OpenTofu package, got %+v", relevant[0].Affected)
+	}
+}
@@ -5,20 +5,35 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-xray-sdk-go/xray"
 	gogithub "github.com/google/go-github/v54/github"
+	"github.com/opentofu/registry/internal/advisories"
 	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/jobs"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/namespaceindex"
 	"github.com/opentofu/registry/internal/providers/providercache"
+	"github.com/opentofu/registry/internal/search"
 	"github.com/opentofu/registry/internal/secrets"
+	"github.com/opentofu/registry/internal/source"
+	"github.com/opentofu/registry/internal/storage"
+	"github.com/opentofu/registry/internal/warnings"
+	"github.com/opentofu/registry/internal/yank"
 	"github.com/shurcooL/githubv4"
 )
 
 type Builder struct {
 	IncludeProviderRedirects bool
+	IncludePublishing        bool
+	IncludeWebhooks          bool
+	IncludeProviderLeases    bool
 }
 
 func NewBuilder(options ...func(*Builder)) *Builder {
@@ -35,15 +50,91 @@ func WithProviderRedirects() func(*Builder) {
 	}
 }
 
+// WithPublishing loads the tokens used to authenticate requests to the publishing API.
+func WithPublishing() func(*Builder) {
+	return func(builder *Builder) {
+		builder.IncludePublishing = true
+	}
+}
+
+// WithWebhooks loads the secret that GitHub webhook deliveries are signed with.
+func WithWebhooks() func(*Builder) {
+	return func(builder *Builder) {
+		builder.IncludeWebhooks = true
+	}
+}
+
+// WithProviderLeases sets up the leases making sure a single worker populates the versions of a provider at a time.
+func WithProviderLeases() func(*Builder) {
+	return func(builder *Builder) {
+		builder.IncludeProviderLeases = true
+	}
+}
+
 type Config struct {
 	ManagedGithubClient *gogithub.Client
 	RawGithubv4Client   *githubv4.Client
 
-	LambdaClient         *lambda.Client
+	// ReleaseSource is used to read repositories and releases for providers and modules,
+	// unless their namespace has a dedicated source in NamespaceSources.
+	ReleaseSource    source.ReleaseSource
+	NamespaceSources map[string]source.ReleaseSource
+
+	LambdaClient *lambda.Client
+	// PopulateDispatcher runs the jobs populating the cached versions of providers, see jobs.PopulateProviderVersions.
+	// JobDispatcher is how the jobs reach the populate provider versions lambda, JobDispatcherLambda or JobDispatcherSQS.
+	PopulateDispatcher   jobs.JobDispatcher
+	JobDispatcher        string
 	ProviderVersionCache *providercache.Handler
-	SecretsHandler       *secrets.Handler
+	// ProviderNamespaceIndex holds the providers found in each namespace by the namespace crawler.
+	ProviderNamespaceIndex *namespaceindex.Handler
+	// ProviderLeases makes sure a single worker populates the versions of a provider at a time. It is only set up
+	// by WithProviderLeases.
+	ProviderLeases *providercache.Leases
+	SecretsHandler *secrets.Handler
 
 	ProviderRedirects map[string]string
+
+	// SourceMappings resolves provider and module addresses to the repositories they are read from,
+	// following the ProviderRedirects and the mappings file.
+	SourceMappings *mappings.Mappings
+
+	// ProviderNamespaceTiers maps provider namespaces to their tier in search results. Namespaces without
+	// a tier are community namespaces.
+	ProviderNamespaceTiers map[string]string
+
+	// YankedVersions are provider and module versions that are no longer served, along with the reason.
+	YankedVersions yank.List
+
+	// Warnings holds the warnings shown to clients for providers and modules, reloaded regularly from the
+	// ArtifactStore so that they can be changed without a redeploy. It is nil if no warnings object is configured.
+	Warnings *warnings.Loader
+
+	// Advisories holds the OSV security advisories of providers and modules. It is nil if no advisories are configured.
+	Advisories *advisories.Loader
+
+	// AdvisoriesFeedURL is the feed of advisories that the ingest_advisories job stores in the ArtifactStore under
+	// AdvisoriesObjectKey, which Advisories reads them from.
+	AdvisoriesFeedURL   string
+	AdvisoriesObjectKey string
+
+	// CrawlNamespaces are crawled for providers in addition to the namespaces that have registered keys.
+	CrawlNamespaces []string
+
+	// UnsignedReleasePolicies maps provider namespaces to the policy used for releases that are
+	// missing a SHA256SUMS signature. The "*" key, if present, applies to all other namespaces.
+	UnsignedReleasePolicies map[string]providers.UnsignedReleasePolicy
+
+	// ArtifactStore holds provider and module versions published directly to the registry. It is nil if publishing
+	// is not configured. PublishedNamespaces are the namespaces using the "registry" source type, which are read
+	// back from the ArtifactStore. PublishTokens maps each namespace to the token its publishing requests are
+	// authenticated with.
+	ArtifactStore       storage.ObjectStore
+	PublishedNamespaces map[string]bool
+	PublishTokens       map[string]string
+
+	// GithubWebhookSecret is the secret GitHub webhook deliveries are signed with. Webhooks are disabled if it is empty.
+	GithubWebhookSecret string
 }
 
 // BuildConfig will build a configuration object for the application. This
@@ -81,24 +172,129 @@ func (c Builder) BuildConfig(ctx context.Context, xraySegmentName string) (confi
 		return nil, err
 	}
 
+	namespacesTableName := os.Getenv("PROVIDER_NAMESPACES_TABLE_NAME")
+	if namespacesTableName == "" {
+		err = fmt.Errorf("PROVIDER_NAMESPACES_TABLE_NAME environment variable not set")
+		return nil, err
+	}
+
+	var providerLeases *providercache.Leases
+	if c.IncludeProviderLeases {
+		leasesTableName := os.Getenv("PROVIDER_LEASES_TABLE_NAME")
+		if leasesTableName == "" {
+			err = fmt.Errorf("PROVIDER_LEASES_TABLE_NAME environment variable not set")
+			return nil, err
+		}
+		providerLeases = providercache.NewLeases(awsConfig, leasesTableName)
+	}
+
+	crawlNamespaces, err := parseCrawlNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	providerNamespaceTiers, err := parseProviderNamespaceTiers()
+	if err != nil {
+		return nil, err
+	}
+
 	providerRedirects := make(map[string]string)
 	if c.IncludeProviderRedirects {
-		if redirectsJSON, ok := os.LookupEnv("PROVIDER_NAMESPACE_REDIRECTS"); ok {
-			if err := json.Unmarshal([]byte(redirectsJSON), &providerRedirects); err != nil {
-				panic(fmt.Errorf("could not parse PROVIDER_NAMESPACE_REDIRECTS: %w", err))
-			}
+		providerRedirects, err = parseProviderRedirects()
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	sourceMappings, err := mappings.Load(os.Getenv("SOURCE_MAPPINGS_FILE"), providerRedirects)
+	if err != nil {
+		err = fmt.Errorf("invalid source mappings: %w", err)
+		return nil, err
+	}
+
+	unsignedReleasePolicies, err := parseUnsignedReleasePolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	yankedVersions, err := parseYankedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	artifactStore, err := buildArtifactStore(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	warningsLoader := buildWarningsLoader(artifactStore)
+
+	advisoriesObjectKey := os.Getenv("ADVISORIES_OBJECT_KEY")
+	advisoriesLoader, err := buildAdvisoriesLoader(artifactStore, advisoriesObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var publishTokens map[string]string
+	if c.IncludePublishing {
+		publishTokens, err = getPublishTokens(ctx, secretsHandler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var webhookSecret string
+	if c.IncludeWebhooks {
+		webhookSecret, err = getWebhookSecret(ctx, secretsHandler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	namespaceSources, publishedNamespaces, err := buildNamespaceSources(ctx, secretsHandler, artifactStore)
+	if err != nil {
+		return nil, err
+	}
+
+	managedGithubClient := github.NewManagedGithubClient(githubAPIToken)
+	rawGithubv4Client := github.NewRawGithubv4Client(githubAPIToken)
+
+	lambdaClient := lambda.NewFromConfig(awsConfig)
+
+	jobDispatcher, populateDispatcher, err := buildPopulateDispatcher(awsConfig, lambdaClient)
+	if err != nil {
+		return nil, err
+	}
+
 	config = &Config{
-		ManagedGithubClient: github.NewManagedGithubClient(githubAPIToken),
-		RawGithubv4Client:   github.NewRawGithubv4Client(githubAPIToken),
+		ManagedGithubClient: managedGithubClient,
+		RawGithubv4Client:   rawGithubv4Client,
+		ReleaseSource:       github.NewReleaseSource(managedGithubClient, rawGithubv4Client),
+		NamespaceSources:    namespaceSources,
+
+		SecretsHandler:         secretsHandler,
+		ProviderVersionCache:   providercache.NewHandler(awsConfig, tableName),
+		ProviderNamespaceIndex: namespaceindex.NewHandler(awsConfig, namespacesTableName),
+		ProviderLeases:         providerLeases,
+		LambdaClient:           lambdaClient,
+		PopulateDispatcher:     populateDispatcher,
+		JobDispatcher:          jobDispatcher,
 
-		SecretsHandler:       secretsHandler,
-		ProviderVersionCache: providercache.NewHandler(awsConfig, tableName),
-		LambdaClient:         lambda.NewFromConfig(awsConfig),
+		ProviderRedirects:       providerRedirects,
+		SourceMappings:          sourceMappings,
+		ProviderNamespaceTiers:  providerNamespaceTiers,
+		CrawlNamespaces:         crawlNamespaces,
+		UnsignedReleasePolicies: unsignedReleasePolicies,
+		YankedVersions:          yankedVersions,
+		Warnings:                warningsLoader,
+		Advisories:              advisoriesLoader,
+		AdvisoriesFeedURL:       os.Getenv("ADVISORIES_FEED_URL"),
+		AdvisoriesObjectKey:     advisoriesObjectKey,
 
-		ProviderRedirects: providerRedirects,
+		ArtifactStore:       artifactStore,
+		PublishedNamespaces: publishedNamespaces,
+		PublishTokens:       publishTokens,
+		GithubWebhookSecret: webhookSecret,
 	}
 	return config, nil
 }
@@ -107,9 +303,237 @@ func (c Builder) BuildConfig(ctx context.Context, xraySegmentName string) (confi
 // where the author (owner of the namespace) does not release artifacts as
 // GitHub Releases.
 func (c Config) EffectiveProviderNamespace(namespace string) string {
-	if redirect, ok := c.ProviderRedirects[namespace]; ok {
-		return redirect
+	return c.SourceMappings.EffectiveNamespace(namespace)
+}
+
+// JobsQueued returns whether the jobs populating provider versions are sent to a queue, whose messages are then the
+// events of the populate provider versions lambda.
+func (c Config) JobsQueued() bool {
+	return c.JobDispatcher == JobDispatcherSQS
+}
+
+// ProviderLocation returns the effective address of a provider and the repository its releases are read from.
+// Prefer it over EffectiveProviderNamespace for single providers, as providers can be mapped individually.
+func (c Config) ProviderLocation(namespace, providerType string) mappings.ProviderLocation {
+	return c.SourceMappings.Provider(namespace, providerType)
+}
+
+// ModuleLocation returns the effective address of a module and the repository its releases are read from.
+func (c Config) ModuleLocation(namespace, name, system string) mappings.ModuleLocation {
+	return c.SourceMappings.Module(namespace, name, system)
+}
+
+// MovedProviderLocation returns the location of a provider whose repository was renamed or transferred to movedTo,
+// and true if the provider is now served from another address.
+func (c Config) MovedProviderLocation(location mappings.ProviderLocation, movedTo string) (mappings.ProviderLocation, bool) {
+	return c.SourceMappings.ProviderMove(location, movedTo)
+}
+
+// MovedModuleLocation returns the location of a module whose repository was renamed or transferred to movedTo.
+func (c Config) MovedModuleLocation(location mappings.ModuleLocation, movedTo string) mappings.ModuleLocation {
+	return c.SourceMappings.ModuleMove(location, movedTo)
+}
+
+// UnsignedReleasePolicy returns the policy for serving unsigned releases of
+// providers in the given (effective) namespace.
+func (c Config) UnsignedReleasePolicy(namespace string) providers.UnsignedReleasePolicy {
+	if policy, ok := c.UnsignedReleasePolicies[namespace]; ok {
+		return policy
+	}
+	if policy, ok := c.UnsignedReleasePolicies["*"]; ok {
+		return policy
+	}
+
+	return providers.DefaultUnsignedReleasePolicy
+}
+
+// ProviderTier returns the tier of the given (effective) provider namespace in search results.
+func (c Config) ProviderTier(namespace string) string {
+	if tier, ok := c.ProviderNamespaceTiers[namespace]; ok {
+		return tier
+	}
+	return search.TierCommunity
+}
+
+func parseUnsignedReleasePolicies() (map[string]providers.UnsignedReleasePolicy, error) {
+	policies := make(map[string]providers.UnsignedReleasePolicy)
+
+	policiesJSON, ok := os.LookupEnv("PROVIDER_UNSIGNED_RELEASE_POLICIES")
+	if !ok || policiesJSON == "" {
+		return policies, nil
+	}
+
+	var rawPolicies map[string]string
+	if err := json.Unmarshal([]byte(policiesJSON), &rawPolicies); err != nil {
+		return nil, fmt.Errorf("could not parse PROVIDER_UNSIGNED_RELEASE_POLICIES: %w", err)
+	}
+
+	for namespace, rawPolicy := range rawPolicies {
+		policy, err := providers.ParseUnsignedReleasePolicy(rawPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PROVIDER_UNSIGNED_RELEASE_POLICIES entry for %s: %w", namespace, err)
+		}
+		policies[namespace] = policy
+	}
+
+	return policies, nil
+}
+
+// parseProviderRedirects parses the PROVIDER_NAMESPACE_REDIRECTS environment variable, a JSON object mapping
+// namespaces to the namespace they are served from.
+func parseProviderRedirects() (map[string]string, error) {
+	redirects := make(map[string]string)
+
+	redirectsJSON, ok := os.LookupEnv("PROVIDER_NAMESPACE_REDIRECTS")
+	if !ok || redirectsJSON == "" {
+		return redirects, nil
+	}
+
+	if err := json.Unmarshal([]byte(redirectsJSON), &redirects); err != nil {
+		return nil, fmt.Errorf("could not parse PROVIDER_NAMESPACE_REDIRECTS: %w", err)
+	}
+	return redirects, nil
+}
+
+// parseYankedVersions parses the YANKED_VERSIONS environment variable, a JSON object mapping provider and module
+// addresses to an object of yanked versions and the reason they were yanked.
+func parseYankedVersions() (yank.List, error) {
+	yankedJSON, ok := os.LookupEnv("YANKED_VERSIONS")
+	if !ok || yankedJSON == "" {
+		return yank.List{}, nil
+	}
+
+	var rawYanked map[string]map[string]string
+	if err := json.Unmarshal([]byte(yankedJSON), &rawYanked); err != nil {
+		return nil, fmt.Errorf("could not parse YANKED_VERSIONS: %w", err)
+	}
+
+	yanked, err := yank.Parse(rawYanked)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YANKED_VERSIONS: %w", err)
+	}
+	return yanked, nil
+}
+
+// populateDeduplicationWindow is how long jobs populating the versions of the same provider are dropped for after
+// one was dispatched, which is about how long populating the versions of a provider takes.
+const populateDeduplicationWindow = time.Minute
+
+const (
+	// JobDispatcherLambda invokes the populate provider versions lambda asynchronously for every job.
+	JobDispatcherLambda = "lambda"
+	// JobDispatcherSQS sends the jobs to an SQS FIFO queue, which triggers the populate provider versions lambda.
+	JobDispatcherSQS = "sqs"
+	// JobDispatcherPool runs the jobs on a worker pool in the same process, which invokes the populate provider
+	// versions lambda synchronously. It is meant for local runs against a Lambda emulator.
+	JobDispatcherPool = "pool"
+)
+
+const (
+	populatePoolWorkers   = 4
+	populatePoolQueueSize = 100
+)
+
+// buildPopulateDispatcher dispatches the jobs populating provider versions as selected by the JOB_DISPATCHER
+// environment variable: to the Lambda function named by POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME (the default), to
+// the SQS FIFO queue at POPULATE_PROVIDER_VERSIONS_QUEUE_URL, which deduplicates jobs itself, or to a worker pool
+// invoking the same Lambda function synchronously.
+func buildPopulateDispatcher(awsConfig aws.Config, lambdaClient *lambda.Client) (string, jobs.JobDispatcher, error) {
+	switch dispatcher := os.Getenv("JOB_DISPATCHER"); dispatcher {
+	case "", JobDispatcherLambda:
+		functionName := os.Getenv("POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME")
+		return JobDispatcherLambda, jobs.Deduplicate(jobs.NewLambdaDispatcher(lambdaClient, functionName), populateDeduplicationWindow), nil
+	case JobDispatcherSQS:
+		queue := jobs.NewSQSQueue(sqs.NewFromConfig(awsConfig), os.Getenv("POPULATE_PROVIDER_VERSIONS_QUEUE_URL"))
+		return JobDispatcherSQS, jobs.NewQueueDispatcher(queue), nil
+	case JobDispatcherPool:
+		run := jobs.NewLambdaRunner(lambdaClient, os.Getenv("POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME"))
+		return JobDispatcherPool, jobs.NewPool(populatePoolWorkers, populatePoolQueueSize, run), nil
+	default:
+		return "", nil, fmt.Errorf("invalid JOB_DISPATCHER %q, must be %s, %s or %s", dispatcher, JobDispatcherLambda, JobDispatcherSQS, JobDispatcherPool)
+	}
+}
+
+// warningsMaxAge is how long the warnings are kept in memory before they are loaded again.
+const warningsMaxAge = 5 * time.Minute
+
+// buildWarningsLoader loads the warnings from the object named by the WARNINGS_OBJECT_KEY environment variable
+// in the artifact store. It returns nil if either is not configured, leaving only the built-in warnings.
+func buildWarningsLoader(artifactStore storage.ObjectStore) *warnings.Loader {
+	key := os.Getenv("WARNINGS_OBJECT_KEY")
+	if key == "" || artifactStore == nil {
+		return nil
+	}
+
+	return warnings.NewLoader(func(ctx context.Context) ([]byte, error) {
+		return artifactStore.Get(ctx, key)
+	}, warningsMaxAge)
+}
+
+// advisoriesMaxAge is how long the advisories are kept in memory before the ingested advisories are read again.
+const advisoriesMaxAge = 30 * time.Minute
+
+// buildAdvisoriesLoader reads the advisories in the file or directory named by the ADVISORIES_PATH environment variable,
+// along with the advisories ingested from the feed into the object named by objectKey in the artifact store.
+// It returns nil if neither is configured.
+func buildAdvisoriesLoader(artifactStore storage.ObjectStore, objectKey string) (*advisories.Loader, error) {
+	path := os.Getenv("ADVISORIES_PATH")
+	if artifactStore == nil {
+		objectKey = ""
+	}
+	if path == "" && objectKey == "" {
+		return nil, nil
 	}
 
-	return namespace
+	local, err := advisories.LoadPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADVISORIES_PATH: %w", err)
+	}
+
+	var fetchFeed func(ctx context.Context) ([]byte, error)
+	if objectKey != "" {
+		fetchFeed = func(ctx context.Context) ([]byte, error) {
+			return artifactStore.Get(ctx, objectKey)
+		}
+	}
+	return advisories.NewLoader(local, fetchFeed, advisoriesMaxAge), nil
+}
+
+// parseCrawlNamespaces parses the CRAWL_NAMESPACES environment variable, a JSON list of namespaces.
+func parseCrawlNamespaces() ([]string, error) {
+	namespacesJSON, ok := os.LookupEnv("CRAWL_NAMESPACES")
+	if !ok || namespacesJSON == "" {
+		return nil, nil
+	}
+
+	var namespaces []string
+	if err := json.Unmarshal([]byte(namespacesJSON), &namespaces); err != nil {
+		return nil, fmt.Errorf("could not parse CRAWL_NAMESPACES: %w", err)
+	}
+	return namespaces, nil
+}
+
+// parseProviderNamespaceTiers parses the PROVIDER_NAMESPACE_TIERS environment variable, a JSON object mapping
+// namespaces to their tier.
+func parseProviderNamespaceTiers() (map[string]string, error) {
+	tiers := make(map[string]string)
+
+	tiersJSON, ok := os.LookupEnv("PROVIDER_NAMESPACE_TIERS")
+	if !ok || tiersJSON == "" {
+		return tiers, nil
+	}
+
+	var rawTiers map[string]string
+	if err := json.Unmarshal([]byte(tiersJSON), &rawTiers); err != nil {
+		return nil, fmt.Errorf("could not parse PROVIDER_NAMESPACE_TIERS: %w", err)
+	}
+
+	for namespace, rawTier := range rawTiers {
+		tier, err := search.ParseTier(rawTier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PROVIDER_NAMESPACE_TIERS entry for %s: %w", namespace, err)
+		}
+		tiers[namespace] = tier
+	}
+	return tiers, nil
 }
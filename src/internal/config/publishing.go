@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/opentofu/registry/internal/secrets"
+	"github.com/opentofu/registry/internal/storage"
+)
+
+// buildArtifactStore creates the object store holding directly published artifacts from the ARTIFACTS_BUCKET_NAME
+// and ARTIFACTS_URL environment variables. It returns nil if no bucket is configured.
+func buildArtifactStore(awsConfig aws.Config) (storage.ObjectStore, error) {
+	bucket := os.Getenv("ARTIFACTS_BUCKET_NAME")
+	if bucket == "" {
+		return nil, nil //nolint:nilnil // Publishing is optional.
+	}
+
+	publicURL := os.Getenv("ARTIFACTS_URL")
+	if publicURL == "" {
+		return nil, fmt.Errorf("ARTIFACTS_URL environment variable not set")
+	}
+
+	store, err := storage.NewS3Store(awsConfig, bucket, publicURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not create artifact store: %w", err)
+	}
+	return store, nil
+}
+
+// getPublishTokens reads the tokens that publishing requests must be authenticated with, keyed by the namespace they
+// can publish to. The secret holds them as a JSON object, and publishing is disabled if there is no secret.
+func getPublishTokens(ctx context.Context, secretsHandler *secrets.Handler) (map[string]string, error) {
+	if os.Getenv("PUBLISH_TOKENS_SECRET_ASM_NAME") == "" {
+		return nil, nil
+	}
+
+	tokensJSON, err := secretsHandler.GetSecretValueFromEnvReference(ctx, "PUBLISH_TOKENS_SECRET_ASM_NAME")
+	if err != nil {
+		return nil, fmt.Errorf("could not get publish tokens: %w", err)
+	}
+
+	var tokens map[string]string
+	if err := json.Unmarshal([]byte(tokensJSON), &tokens); err != nil {
+		return nil, fmt.Errorf("could not parse publish tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// PublishingEnabled returns true if versions can be published to the given namespace. Only namespaces using the
+// "registry" source type that have a publish token accept published versions, so that they are served from the
+// artifact store afterwards.
+func (c Config) PublishingEnabled(namespace string) bool {
+	return c.ArtifactStore != nil && c.PublishTokens[namespace] != "" && c.PublishedNamespaces[namespace]
+}
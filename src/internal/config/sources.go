@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/opentofu/registry/internal/gitea"
+	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/gitlab"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/oci"
+	"github.com/opentofu/registry/internal/secrets"
+	"github.com/opentofu/registry/internal/source"
+	"github.com/opentofu/registry/internal/static"
+	"github.com/opentofu/registry/internal/storage"
+)
+
+const (
+	SourceTypeGitHub  = "github"
+	SourceTypeGitLab  = "gitlab"
+	SourceTypeGitea   = "gitea"
+	SourceTypeForgejo = "forgejo"
+	SourceTypeHTTP    = "http"
+	SourceTypeOCI     = "oci"
+
+	// SourceTypeRegistry is used for namespaces whose versions are published directly to the registry.
+	SourceTypeRegistry = "registry"
+)
+
+// SourceConfig describes where the providers and modules of a single namespace are hosted
+// when they are not read from GitHub.
+type SourceConfig struct {
+	Type               string `json:"type"`                            // The type of the backend: "github" (Enterprise Server), "gitlab", "gitea", "forgejo", "http", "oci" or "registry".
+	URL                string `json:"url"`                             // The base URL of the backend instance (the REST API URL for GitHub Enterprise Server).
+	Group              string `json:"group,omitempty"`                 // The group or owner on GitLab, Gitea or Forgejo if it differs from the namespace, or the repository prefix on OCI registries.
+	TokenSecretASMName string `json:"token_secret_asm_name,omitempty"` // The name of the AWS Secrets Manager secret holding the API token (or "<username>:<password>" for OCI registries).
+
+	GraphQLURL string `json:"graphql_url,omitempty"` // The GraphQL API URL for GitHub Enterprise Server, defaults to https://<host>/api/graphql.
+	CloneHost  string `json:"clone_host,omitempty"`  // The host modules are cloned from with git, defaults to the host of URL.
+
+	IndexFormat string `json:"index_format,omitempty"` // The index format for "http" sources: "json" (index.json, the default) or "html" (directory listing).
+}
+
+// ReleaseSourceFor returns the release source that hosts the given (effective) namespace.
+// Namespaces without a dedicated source are read from GitHub.
+func (c Config) ReleaseSourceFor(namespace string) source.ReleaseSource {
+	if src, ok := c.NamespaceSources[namespace]; ok {
+		return src
+	}
+
+	return c.ReleaseSource
+}
+
+// ProviderReleaseSource returns the release source of a provider, which only sees the releases with the provider's tag prefix.
+func (c Config) ProviderReleaseSource(location mappings.ProviderLocation) source.ReleaseSource {
+	return source.WithTagPrefix(c.ReleaseSourceFor(location.Namespace), location.TagPrefix)
+}
+
+// ModuleReleaseSource returns the release source of a module, which only sees the releases and tags with the module's tag prefix.
+func (c Config) ModuleReleaseSource(location mappings.ModuleLocation) source.ReleaseSource {
+	return source.WithTagPrefix(c.ReleaseSourceFor(location.Namespace), location.TagPrefix)
+}
+
+// buildNamespaceSources parses the NAMESPACE_SOURCES environment variable, a JSON object mapping namespaces
+// to a SourceConfig, and creates a release source for each of them. It also returns the set of namespaces
+// using the "registry" source type.
+func buildNamespaceSources(ctx context.Context, secretsHandler *secrets.Handler, artifactStore storage.ObjectStore) (map[string]source.ReleaseSource, map[string]bool, error) {
+	sources := make(map[string]source.ReleaseSource)
+	publishedNamespaces := make(map[string]bool)
+
+	sourcesJSON, ok := os.LookupEnv("NAMESPACE_SOURCES")
+	if !ok || sourcesJSON == "" {
+		return sources, publishedNamespaces, nil
+	}
+
+	var sourceConfigs map[string]SourceConfig
+	if err := json.Unmarshal([]byte(sourcesJSON), &sourceConfigs); err != nil {
+		return nil, nil, fmt.Errorf("could not parse NAMESPACE_SOURCES: %w", err)
+	}
+
+	for namespace, sourceConfig := range sourceConfigs {
+		if sourceConfig.Type == SourceTypeRegistry {
+			if artifactStore == nil {
+				return nil, nil, fmt.Errorf("invalid NAMESPACE_SOURCES entry for %s: the registry source type requires ARTIFACTS_BUCKET_NAME to be set", namespace)
+			}
+			// published versions are laid out in the artifact store as expected by the static source's JSON index format
+			src, err := static.NewReleaseSource(artifactStore.URL(namespace), static.IndexFormatJSON, "")
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid NAMESPACE_SOURCES entry for %s: %w", namespace, err)
+			}
+			sources[namespace] = src
+			publishedNamespaces[namespace] = true
+			continue
+		}
+
+		src, err := buildSource(ctx, secretsHandler, sourceConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid NAMESPACE_SOURCES entry for %s: %w", namespace, err)
+		}
+		sources[namespace] = src
+	}
+
+	return sources, publishedNamespaces, nil
+}
+
+func buildSource(ctx context.Context, secretsHandler *secrets.Handler, sourceConfig SourceConfig) (source.ReleaseSource, error) {
+	if sourceConfig.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	var token string
+	if sourceConfig.TokenSecretASMName != "" {
+		var err error
+		token, err = secretsHandler.GetValue(ctx, sourceConfig.TokenSecretASMName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get token: %w", err)
+		}
+	}
+
+	switch sourceConfig.Type {
+	case SourceTypeGitHub:
+		return buildGithubEnterpriseSource(sourceConfig, token)
+	case SourceTypeGitLab:
+		return gitlab.NewReleaseSource(sourceConfig.URL, token, sourceConfig.Group)
+	case SourceTypeGitea, SourceTypeForgejo:
+		return gitea.NewReleaseSource(sourceConfig.URL, token, sourceConfig.Group)
+	case SourceTypeHTTP:
+		return static.NewReleaseSource(sourceConfig.URL, sourceConfig.IndexFormat, token)
+	case SourceTypeOCI:
+		return oci.NewReleaseSource(sourceConfig.URL, token, sourceConfig.Group)
+	default:
+		return nil, fmt.Errorf("unknown source type %q", sourceConfig.Type)
+	}
+}
+
+func buildGithubEnterpriseSource(sourceConfig SourceConfig, token string) (source.ReleaseSource, error) {
+	restURL, err := url.Parse(sourceConfig.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	graphqlURL := sourceConfig.GraphQLURL
+	if graphqlURL == "" {
+		graphqlURL = fmt.Sprintf("%s://%s/api/graphql", restURL.Scheme, restURL.Host)
+	}
+
+	cloneHost := sourceConfig.CloneHost
+	if cloneHost == "" {
+		cloneHost = restURL.Host
+	}
+
+	managedClient, err := github.NewEnterpriseManagedGithubClient(sourceConfig.URL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return github.NewReleaseSource(
+		managedClient,
+		github.NewEnterpriseGithubv4Client(graphqlURL, token),
+		github.WithCloneHost(cloneHost),
+		github.WithAssetHost(restURL.Host),
+	), nil
+}
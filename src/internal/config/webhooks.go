@@ -0,0 +1,22 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/opentofu/registry/internal/secrets"
+)
+
+// getWebhookSecret reads the secret that GitHub webhook deliveries are signed with, if webhooks are enabled.
+func getWebhookSecret(ctx context.Context, secretsHandler *secrets.Handler) (string, error) {
+	if os.Getenv("GITHUB_WEBHOOK_SECRET_ASM_NAME") == "" {
+		return "", nil
+	}
+
+	secret, err := secretsHandler.GetSecretValueFromEnvReference(ctx, "GITHUB_WEBHOOK_SECRET_ASM_NAME")
+	if err != nil {
+		return "", fmt.Errorf("could not get webhook secret: %w", err)
+	}
+	return secret, nil
+}
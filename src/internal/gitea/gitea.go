@@ -0,0 +1,322 @@
+// Package gitea reads provider and module releases from a Gitea or Forgejo instance.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	giteaRequestTimeout = 60 * time.Second
+	// perPage is the page size requested, which servers cap at their MAX_RESPONSE_ITEMS (50 by default). Pages can
+	// therefore be smaller than requested, and only an empty page marks the end of a listing.
+	perPage      = 50
+	sincePadding = 2 * time.Minute
+)
+
+// ReleaseSource implements source.ReleaseSource on top of the Gitea (and Forgejo) releases API.
+type ReleaseSource struct {
+	baseURL    *url.URL
+	token      string
+	owner      string
+	httpClient *http.Client
+}
+
+// NewReleaseSource creates a ReleaseSource for the Gitea or Forgejo instance at baseURL (for example https://gitea.example.com).
+// If owner is set, repositories are looked up under that user or organization instead of the one named after the registry namespace.
+func NewReleaseSource(baseURL, token, owner string) (*ReleaseSource, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gitea URL %q: %w", baseURL, err)
+	}
+
+	return &ReleaseSource{
+		baseURL:    parsed,
+		token:      token,
+		owner:      owner,
+		httpClient: xray.Client(&http.Client{Timeout: giteaRequestTimeout}),
+	}, nil
+}
+
+// giteaRelease is a release as returned by the Gitea releases API.
+type giteaRelease struct {
+	TagName      string    `json:"tag_name"`
+	IsDraft      bool      `json:"draft"`
+	IsPrerelease bool      `json:"prerelease"`
+	CreatedAt    time.Time `json:"created_at"`
+	Assets       []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// giteaRepository is a repository as returned by the Gitea repositories API.
+type giteaRepository struct {
+	Description string   `json:"description"`
+	WebURL      string   `json:"html_url"`
+	Topics      []string `json:"topics"`
+	Archived    bool     `json:"archived"`
+}
+
+type giteaTag struct {
+	Name string `json:"name"`
+}
+
+func (s *ReleaseSource) repoPath(namespace, name string) string {
+	owner := namespace
+	if s.owner != "" {
+		owner = s.owner
+	}
+	return fmt.Sprintf("%s/%s", url.PathEscape(owner), url.PathEscape(name))
+}
+
+func (s *ReleaseSource) repoURL(namespace, name string) string {
+	return fmt.Sprintf("%s/api/v1/repos/%s", s.baseURL, s.repoPath(namespace, name))
+}
+
+func (s *ReleaseSource) RepositoryExists(ctx context.Context, namespace, name string) (exists bool, err error) {
+	err = xray.Capture(ctx, "gitea.repository.exists", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		slog.Info("Checking if Gitea repository exists")
+
+		resp, reqErr := s.get(tracedCtx, s.repoURL(namespace, name))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get repository: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			exists = true
+			return nil
+		case http.StatusNotFound:
+			slog.Info("Gitea repository does not exist")
+			return nil
+		default:
+			return fmt.Errorf("unexpected status code when getting repository: %d", resp.StatusCode)
+		}
+	})
+
+	return exists, err
+}
+
+func (s *ReleaseSource) RepositoryMetadata(ctx context.Context, namespace, name string) (metadata *source.RepositoryMetadata, err error) {
+	err = xray.Capture(ctx, "gitea.repository.metadata", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		resp, reqErr := s.get(tracedCtx, s.repoURL(namespace, name))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get repository: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			slog.Info("Gitea repository does not exist")
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code when getting repository: %d", resp.StatusCode)
+		}
+
+		var repo giteaRepository
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&repo); decodeErr != nil {
+			return fmt.Errorf("failed to decode repository: %w", decodeErr)
+		}
+
+		metadata = &source.RepositoryMetadata{
+			Name:        name,
+			Description: repo.Description,
+			URL:         repo.WebURL,
+			Topics:      repo.Topics,
+			Archived:    repo.Archived,
+		}
+		return nil
+	})
+
+	return metadata, err
+}
+
+func (s *ReleaseSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) (releases []source.Release, err error) {
+	err = xray.Capture(ctx, "gitea.releases.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		slog.Info("Fetching new Gitea releases")
+
+		for page := 1; ; page++ {
+			var giteaReleases []giteaRelease
+			if fetchErr := s.getJSON(tracedCtx, fmt.Sprintf("%s/releases?draft=false&limit=%d&page=%d", s.repoURL(namespace, name), perPage, page), &giteaReleases); fetchErr != nil {
+				return fmt.Errorf("failed to fetch releases: %w", fetchErr)
+			}
+
+			for _, r := range giteaReleases {
+				if r.IsDraft {
+					continue
+				}
+
+				// releases are ordered by creation date, so we can stop as soon as we see one older than "since"
+				if since != nil && r.CreatedAt.Before(since.Add(-sincePadding)) {
+					slog.Info("Release was created before given time, stopping reading releases", "release", r.TagName, "created_at", r.CreatedAt)
+					return nil
+				}
+
+				releases = append(releases, r.toSourceRelease())
+			}
+
+			if len(giteaReleases) == 0 {
+				return nil
+			}
+		}
+	})
+
+	slog.Info("Gitea releases fetched", "count", len(releases))
+	return releases, err
+}
+
+func (s *ReleaseSource) FindRelease(ctx context.Context, namespace, name, version string) (release *source.Release, err error) {
+	err = xray.Capture(ctx, "gitea.release.find", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+		xray.AddAnnotation(tracedCtx, "versionNumber", version)
+
+		resp, reqErr := s.get(tracedCtx, fmt.Sprintf("%s/releases/tags/%s", s.repoURL(namespace, name), url.PathEscape("v"+version)))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get release: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			slog.Info("Gitea release not found")
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code when getting release: %d", resp.StatusCode)
+		}
+
+		var r giteaRelease
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&r); decodeErr != nil {
+			return fmt.Errorf("failed to decode release: %w", decodeErr)
+		}
+		if r.IsDraft {
+			return nil
+		}
+
+		sourceRelease := r.toSourceRelease()
+		release = &sourceRelease
+		return nil
+	})
+
+	return release, err
+}
+
+func (s *ReleaseSource) FetchTags(ctx context.Context, namespace, name string) (tags []string, err error) {
+	err = xray.Capture(ctx, "gitea.tags.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		for page := 1; ; page++ {
+			var giteaTags []giteaTag
+			if fetchErr := s.getJSON(tracedCtx, fmt.Sprintf("%s/tags?limit=%d&page=%d", s.repoURL(namespace, name), perPage, page), &giteaTags); fetchErr != nil {
+				return fmt.Errorf("failed to fetch tags: %w", fetchErr)
+			}
+
+			for _, t := range giteaTags {
+				tags = append(tags, t.Name)
+			}
+
+			if len(giteaTags) == 0 {
+				return nil
+			}
+		}
+	})
+
+	slog.Info("Gitea tags fetched", "count", len(tags))
+	return tags, err
+}
+
+func (s *ReleaseSource) DownloadAsset(ctx context.Context, asset source.Asset) (body io.ReadCloser, err error) {
+	err = xray.Capture(ctx, "gitea.asset.download", func(tracedCtx context.Context) error {
+		slog.Info("Downloading asset", "url", asset.DownloadURL)
+
+		resp, reqErr := s.get(tracedCtx, asset.DownloadURL)
+		if reqErr != nil {
+			return fmt.Errorf("error downloading asset: %w", reqErr)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status code when downloading asset: %d", resp.StatusCode)
+		}
+
+		body = resp.Body
+		return nil
+	})
+
+	return body, err
+}
+
+func (s *ReleaseSource) ModuleDownloadURL(_ context.Context, namespace, name, tag string) (string, error) {
+	return fmt.Sprintf("git::%s/%s.git?ref=%s", s.baseURL, s.repoPath(namespace, name), tag), nil
+}
+
+// get performs an authenticated GET request. The token is only sent to the configured Gitea host,
+// as release assets may be hosted elsewhere.
+func (s *ReleaseSource) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if s.token != "" && req.URL.Host == s.baseURL.Host {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", s.token))
+	}
+
+	return s.httpClient.Do(req)
+}
+
+func (s *ReleaseSource) getJSON(ctx context.Context, rawURL string, target interface{}) error {
+	resp, err := s.get(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (r giteaRelease) toSourceRelease() source.Release {
+	assets := make([]source.Asset, 0, len(r.Assets))
+	for _, a := range r.Assets {
+		assets = append(assets, source.Asset{
+			Name:        a.Name,
+			DownloadURL: a.BrowserDownloadURL,
+		})
+	}
+
+	return source.Release{
+		TagName:      r.TagName,
+		Assets:       assets,
+		IsPrerelease: r.IsPrerelease,
+		CreatedAt:    r.CreatedAt,
+	}
+}
@@ -0,0 +1,162 @@
+package gitea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry/internal/gitea"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers"
+)
+
+const testToken = "gitea-test"
+
+// newFakeGitea serves a minimal subset of the Gitea v1 API for the "infra/terraform-provider-dummy"
+// and "infra/terraform-aws-network" repositories.
+func newFakeGitea(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	release := func(version string, draft bool) string {
+		prefix := fmt.Sprintf("terraform-provider-dummy_%s", version)
+		asset := func(name string) string {
+			return fmt.Sprintf(`{"name": %q, "browser_download_url": "%s/attachments/%s"}`, name, server.URL, name)
+		}
+		return fmt.Sprintf(`{"tag_name": "v%s", "draft": %t, "prerelease": false, "created_at": "2023-10-01T10:00:00Z", "assets": [%s, %s, %s]}`,
+			version, draft, asset(prefix+"_linux_arm64.zip"), asset(prefix+"_SHA256SUMS"), asset(prefix+"_SHA256SUMS.sig"))
+	}
+
+	mux.HandleFunc("/api/v1/repos/infra/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/repos/infra/terraform-provider-dummy":
+			fmt.Fprintf(w, `{"id": 1, "description": "A dummy provider", "html_url": "%s/infra/terraform-provider-dummy", "archived": true}`, server.URL)
+		case "/api/v1/repos/infra/terraform-aws-network":
+			fmt.Fprint(w, `{"id": 2}`)
+		case "/api/v1/repos/infra/terraform-provider-dummy/releases":
+			// pages hold a single release, as on servers with MAX_RESPONSE_ITEMS set to 1
+			switch r.URL.Query().Get("page") {
+			case "1":
+				fmt.Fprintf(w, "[%s]", release("2.0.0", true))
+			case "2":
+				fmt.Fprintf(w, "[%s]", release("1.0.0", false))
+			default:
+				fmt.Fprint(w, "[]")
+			}
+		case "/api/v1/repos/infra/terraform-provider-dummy/releases/tags/v1.0.0":
+			fmt.Fprint(w, release("1.0.0", false))
+		case "/api/v1/repos/infra/terraform-aws-network/tags":
+			switch r.URL.Query().Get("page") {
+			case "1":
+				fmt.Fprint(w, `[{"name": "v0.2.0"}]`)
+			case "2":
+				fmt.Fprint(w, `[{"name": "v0.1.0"}]`)
+			default:
+				fmt.Fprint(w, "[]")
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	mux.HandleFunc("/attachments/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "_SHA256SUMS") {
+			fmt.Fprint(w, "1234  terraform-provider-dummy_1.0.0_linux_arm64.zip\n")
+			return
+		}
+		fmt.Fprint(w, "binary")
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRepositoryMetadata(t *testing.T) {
+	server := newFakeGitea(t)
+	src, err := gitea.NewReleaseSource(server.URL, testToken, "infra")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	metadata, err := src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || metadata == nil {
+		t.Fatalf("expected metadata, got %v (error: %v)", metadata, err)
+	}
+	if metadata.Description != "A dummy provider" || metadata.URL != server.URL+"/infra/terraform-provider-dummy" || !metadata.Archived {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+
+	metadata, err = src.RepositoryMetadata(context.Background(), "internal", "terraform-aws-network")
+	if err != nil || metadata == nil || metadata.Archived {
+		t.Errorf("expected metadata of a repository that is not archived, got %+v (error: %v)", metadata, err)
+	}
+}
+
+func TestProviderVersions(t *testing.T) {
+	server := newFakeGitea(t)
+	src, err := gitea.NewReleaseSource(server.URL, testToken, "infra")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	versions, _, err := providers.GetVersions(context.Background(), src, "internal", "terraform-provider-dummy", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(versions) != 1 || versions[0].Version != "1.0.0" {
+		t.Fatalf("expected only the published 1.0.0 release, got %v", versions)
+	}
+
+	details := versions[0].GetVersionDetails("linux", "arm64")
+	if details == nil || details.SHASum != "1234" {
+		t.Fatalf("unexpected download details %v", details)
+	}
+
+	versionDetails, err := providers.GetVersion(context.Background(), src, "internal", "terraform-provider-dummy", "1.0.0", "linux", "arm64", providers.UnsignedReleasePolicyRequire)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if versionDetails.DownloadURL != server.URL+"/attachments/terraform-provider-dummy_1.0.0_linux_arm64.zip" {
+		t.Errorf("unexpected download URL %s", versionDetails.DownloadURL)
+	}
+}
+
+func TestModules(t *testing.T) {
+	server := newFakeGitea(t)
+	src, err := gitea.NewReleaseSource(server.URL, testToken, "infra")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	versions, _, err := modules.GetVersions(context.Background(), src, "internal", "terraform-aws-network", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "0.2.0" {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+
+	tag, err := modules.ResolveTag(context.Background(), src, "internal", "terraform-aws-network", "0.1.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	downloadURL, err := src.ModuleDownloadURL(context.Background(), "internal", "terraform-aws-network", tag)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := "git::" + server.URL + "/infra/terraform-aws-network.git?ref=v0.1.0"; downloadURL != expected {
+		t.Errorf("expected %s, got %s", expected, downloadURL)
+	}
+}
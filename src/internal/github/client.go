@@ -28,3 +28,19 @@ func NewManagedGithubClient(token string) *github.Client {
 func NewRawGithubv4Client(token string) *githubv4.Client {
 	return githubv4.NewEnterpriseClient(fmt.Sprintf("https://%s/github/graphql/", os.Getenv("GITHUB_API_GW_URL")), getGithubOauth2Client(token))
 }
+
+// NewEnterpriseManagedGithubClient creates a REST client for a GitHub Enterprise Server instance,
+// for example with the base URL https://github.example.com/api/v3/.
+func NewEnterpriseManagedGithubClient(baseURL, token string) (*github.Client, error) {
+	client, err := github.NewEnterpriseClient(baseURL, baseURL, getGithubOauth2Client(token))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub Enterprise Server URL %q: %w", baseURL, err)
+	}
+	return client, nil
+}
+
+// NewEnterpriseGithubv4Client creates a GraphQL client for a GitHub Enterprise Server instance,
+// for example with the URL https://github.example.com/api/graphql.
+func NewEnterpriseGithubv4Client(graphqlURL, token string) *githubv4.Client {
+	return githubv4.NewEnterpriseClient(graphqlURL, getGithubOauth2Client(token))
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-xray-sdk-go/xray"
 	"github.com/google/go-github/v54/github"
+	"github.com/opentofu/registry/internal/source"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/exp/slog"
 )
@@ -63,7 +65,7 @@ func RepositoryExists(ctx context.Context, managedGhClient *github.Client, names
 
 		_, response, getErr := managedGhClient.Repositories.Get(tracedCtx, namespace, name)
 		if getErr != nil {
-			if response.StatusCode == http.StatusNotFound {
+			if response != nil && response.StatusCode == http.StatusNotFound {
 				slog.Info("Repository does not exist")
 				return nil
 			}
@@ -79,6 +81,99 @@ func RepositoryExists(ctx context.Context, managedGhClient *github.Client, names
 	return exists, err
 }
 
+// GetRepositoryMetadata returns the description, web URL and state of a repository, or nil if the repository does not exist.
+func GetRepositoryMetadata(ctx context.Context, managedGhClient *github.Client, namespace, name string) (metadata *source.RepositoryMetadata, err error) {
+	err = xray.Capture(ctx, "github.repository.metadata", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		repo, response, getErr := managedGhClient.Repositories.Get(tracedCtx, namespace, name)
+		if getErr != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				slog.Info("Repository does not exist")
+				return nil
+			}
+			return fmt.Errorf("failed to get repository: %w", getErr)
+		}
+
+		repoMetadata := toRepositoryMetadata(repo)
+		// GitHub redirects requests for renamed and transferred repositories to their new location
+		if fullName := repo.GetFullName(); fullName != "" && !strings.EqualFold(fullName, namespace+"/"+name) {
+			slog.Info("Repository has moved", "moved_to", fullName)
+			repoMetadata.MovedTo = fullName
+		}
+		metadata = &repoMetadata
+		return nil
+	})
+
+	return metadata, err
+}
+
+// ListRepositories returns all repositories owned by a GitHub organization or user.
+func ListRepositories(ctx context.Context, managedGhClient *github.Client, owner string) (repositories []source.RepositoryMetadata, err error) {
+	err = xray.Capture(ctx, "github.repositories.list", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "owner", owner)
+
+		slog.Info("Listing repositories", "owner", owner)
+
+		orgOptions := &github.RepositoryListByOrgOptions{Type: "public", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			repos, response, listErr := managedGhClient.Repositories.ListByOrg(tracedCtx, owner, orgOptions)
+			if listErr != nil {
+				// the owner may be a user rather than an organization
+				if response != nil && response.StatusCode == http.StatusNotFound && orgOptions.Page == 0 {
+					return listUserRepositories(tracedCtx, managedGhClient, owner, &repositories)
+				}
+				return fmt.Errorf("failed to list repositories: %w", listErr)
+			}
+
+			for _, repo := range repos {
+				repositories = append(repositories, toRepositoryMetadata(repo))
+			}
+
+			if response.NextPage == 0 {
+				return nil
+			}
+			orgOptions.Page = response.NextPage
+		}
+	})
+
+	slog.Info("Repositories listed", "owner", owner, "count", len(repositories))
+	return repositories, err
+}
+
+func listUserRepositories(ctx context.Context, managedGhClient *github.Client, user string, repositories *[]source.RepositoryMetadata) error {
+	slog.Info("Owner is not an organization, listing user repositories", "owner", user)
+
+	userOptions := &github.RepositoryListOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		repos, response, err := managedGhClient.Repositories.List(ctx, user, userOptions)
+		if err != nil {
+			return fmt.Errorf("failed to list repositories: %w", err)
+		}
+
+		for _, repo := range repos {
+			*repositories = append(*repositories, toRepositoryMetadata(repo))
+		}
+
+		if response.NextPage == 0 {
+			return nil
+		}
+		userOptions.Page = response.NextPage
+	}
+}
+
+func toRepositoryMetadata(repo *github.Repository) source.RepositoryMetadata {
+	return source.RepositoryMetadata{
+		Name:        repo.GetName(),
+		Description: repo.GetDescription(),
+		URL:         repo.GetHTMLURL(),
+		Topics:      repo.Topics,
+		Archived:    repo.GetArchived(),
+		Disabled:    repo.GetDisabled(),
+	}
+}
+
 func FindRelease(ctx context.Context, ghClient *githubv4.Client, namespace, name, versionNumber string) (release *GHRelease, err error) {
 	err = xray.Capture(ctx, "github.release.find", func(tracedCtx context.Context) error {
 		xray.AddAnnotation(tracedCtx, "namespace", namespace)
@@ -210,18 +305,6 @@ func fetchReleaseNodes(ctx context.Context, ghClient *githubv4.Client, variables
 	return releases, endCursor, err
 }
 
-func FindAssetBySuffix(assets []ReleaseAsset, suffix string) *ReleaseAsset {
-	slog.Info("Finding asset by suffix", "suffix", suffix)
-	for _, asset := range assets {
-		if strings.HasSuffix(asset.Name, suffix) {
-			slog.Info("Asset found", "asset", asset)
-			return &asset
-		}
-	}
-	slog.Info("Asset not found")
-	return nil
-}
-
 const githubAssetDownloadTimeout = 60 * time.Second
 
 func DownloadAssetContents(ctx context.Context, downloadURL string) (body io.ReadCloser, err error) {
@@ -255,3 +338,48 @@ func DownloadAssetContents(ctx context.Context, downloadURL string) (body io.Rea
 	slog.Info("Asset downloaded successfully")
 	return body, err
 }
+
+// DownloadReleaseAsset downloads a release asset through the releases API of the REST client, which sends its token,
+// rather than from its public download URL, https://<host>/<owner>/<repo>/releases/download/<tag>/<name>.
+func DownloadReleaseAsset(ctx context.Context, managedGhClient *github.Client, downloadURL *url.URL) (body io.ReadCloser, err error) {
+	err = xray.Capture(ctx, "github.asset.download", func(tracedCtx context.Context) error {
+		owner, repo, tag, name, parseErr := parseAssetDownloadURL(downloadURL)
+		if parseErr != nil {
+			return parseErr
+		}
+
+		slog.Info("Downloading asset through the releases API", "owner", owner, "repo", repo, "tag", tag, "name", name)
+		release, _, releaseErr := managedGhClient.Repositories.GetReleaseByTag(tracedCtx, owner, repo, tag)
+		if releaseErr != nil {
+			slog.Error("Failed to get release", "error", releaseErr)
+			return fmt.Errorf("failed to get release %s: %w", tag, releaseErr)
+		}
+
+		for _, asset := range release.Assets {
+			if asset.GetName() != name {
+				continue
+			}
+			// the asset is either returned directly or redirected to a pre-signed storage URL, which is followed without the token
+			rc, _, downloadErr := managedGhClient.Repositories.DownloadReleaseAsset(tracedCtx, owner, repo, asset.GetID(), xray.Client(&http.Client{Timeout: githubAssetDownloadTimeout}))
+			if downloadErr != nil {
+				slog.Error("Error downloading asset", "error", downloadErr)
+				return fmt.Errorf("error downloading asset: %w", downloadErr)
+			}
+			body = rc
+			return nil
+		}
+
+		return fmt.Errorf("release %s has no asset %s", tag, name)
+	})
+
+	return body, err
+}
+
+func parseAssetDownloadURL(downloadURL *url.URL) (owner, repo, tag, name string, err error) {
+	parts := strings.Split(strings.TrimPrefix(downloadURL.Path, "/"), "/")
+	// tags may contain slashes, the name of the asset never does
+	if len(parts) < 6 || parts[2] != "releases" || parts[3] != "download" {
+		return "", "", "", "", fmt.Errorf("unexpected asset download URL %s", downloadURL)
+	}
+	return parts[0], parts[1], strings.Join(parts[4:len(parts)-1], "/"), parts[len(parts)-1], nil
+}
@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v54/github"
+	"github.com/opentofu/registry/internal/source"
+	"github.com/shurcooL/githubv4"
+)
+
+const defaultCloneHost = "github.com"
+
+// ReleaseSource implements source.ReleaseSource on top of GitHub Releases.
+type ReleaseSource struct {
+	managedGhClient *github.Client
+	ghClient        *githubv4.Client
+	cloneHost       string
+	assetHost       string
+}
+
+// NewReleaseSource creates a ReleaseSource using the REST client for repository lookups
+// and the GraphQL client for reading releases.
+func NewReleaseSource(managedGhClient *github.Client, ghClient *githubv4.Client, options ...func(*ReleaseSource)) *ReleaseSource {
+	src := &ReleaseSource{
+		managedGhClient: managedGhClient,
+		ghClient:        ghClient,
+		cloneHost:       defaultCloneHost,
+	}
+	for _, option := range options {
+		option(src)
+	}
+	return src
+}
+
+// WithCloneHost sets the host that modules are cloned from, for example the hostname of a GitHub Enterprise Server instance.
+func WithCloneHost(host string) func(*ReleaseSource) {
+	return func(src *ReleaseSource) {
+		src.cloneHost = host
+	}
+}
+
+// WithAssetHost downloads the release assets hosted on the given host through the authenticated releases API,
+// for example from a GitHub Enterprise Server instance whose repositories are private or internal.
+func WithAssetHost(host string) func(*ReleaseSource) {
+	return func(src *ReleaseSource) {
+		src.assetHost = host
+	}
+}
+
+func (s *ReleaseSource) RepositoryExists(ctx context.Context, namespace, name string) (bool, error) {
+	return RepositoryExists(ctx, s.managedGhClient, namespace, name)
+}
+
+func (s *ReleaseSource) RepositoryMetadata(ctx context.Context, namespace, name string) (*source.RepositoryMetadata, error) {
+	return GetRepositoryMetadata(ctx, s.managedGhClient, namespace, name)
+}
+
+func (s *ReleaseSource) ListRepositories(ctx context.Context, namespace string) ([]source.RepositoryMetadata, error) {
+	return ListRepositories(ctx, s.managedGhClient, namespace)
+}
+
+func (s *ReleaseSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) ([]source.Release, error) {
+	ghReleases, err := FetchReleases(ctx, s.ghClient, namespace, name, since)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]source.Release, 0, len(ghReleases))
+	for _, r := range ghReleases {
+		releases = append(releases, r.toSourceRelease())
+	}
+	return releases, nil
+}
+
+func (s *ReleaseSource) FindRelease(ctx context.Context, namespace, name, version string) (*source.Release, error) {
+	ghRelease, err := FindRelease(ctx, s.ghClient, namespace, name, version)
+	if err != nil || ghRelease == nil {
+		return nil, err
+	}
+
+	release := ghRelease.toSourceRelease()
+	return &release, nil
+}
+
+func (s *ReleaseSource) DownloadAsset(ctx context.Context, asset source.Asset) (io.ReadCloser, error) {
+	if s.assetHost != "" {
+		if assetURL, err := url.Parse(asset.DownloadURL); err == nil && assetURL.Host == s.assetHost {
+			return DownloadReleaseAsset(ctx, s.managedGhClient, assetURL)
+		}
+	}
+	return DownloadAssetContents(ctx, asset.DownloadURL)
+}
+
+func (s *ReleaseSource) ModuleDownloadURL(_ context.Context, namespace, name, tag string) (string, error) {
+	return fmt.Sprintf("git::https://%s/%s/%s?ref=%s", s.cloneHost, namespace, name, tag), nil
+}
+
+func (r GHRelease) toSourceRelease() source.Release {
+	assets := make([]source.Asset, 0, len(r.ReleaseAssets.Nodes))
+	for _, a := range r.ReleaseAssets.Nodes {
+		assets = append(assets, source.Asset{
+			Name:        a.Name,
+			DownloadURL: a.DownloadURL,
+		})
+	}
+
+	return source.Release{
+		TagName:      r.TagName,
+		Assets:       assets,
+		IsPrerelease: r.IsPrerelease,
+		CreatedAt:    r.CreatedAt,
+	}
+}
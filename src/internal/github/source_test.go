@@ -0,0 +1,244 @@
+package github_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/source"
+)
+
+func TestModuleDownloadURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []func(*github.ReleaseSource)
+		expected string
+	}{
+		{
+			name:     "github.com by default",
+			expected: "git::https://github.com/opentofu/terraform-aws-vpc?ref=v1.0.0",
+		},
+		{
+			name:     "enterprise clone host",
+			options:  []func(*github.ReleaseSource){github.WithCloneHost("github.example.com")},
+			expected: "git::https://github.example.com/opentofu/terraform-aws-vpc?ref=v1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := github.NewReleaseSource(nil, nil, tt.options...)
+			got, err := src.ModuleDownloadURL(context.Background(), "opentofu", "terraform-aws-vpc", "v1.0.0")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEnterpriseRepositoryExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/internal/terraform-provider-dummy" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer ghes-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"full_name": "internal/terraform-provider-dummy", "description": "A dummy provider", "html_url": "https://github.example.com/internal/terraform-provider-dummy"}`))
+	}))
+	defer server.Close()
+
+	client, err := github.NewEnterpriseManagedGithubClient(server.URL+"/api/v3/", "ghes-token")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	src := github.NewReleaseSource(client, nil)
+
+	exists, err := src.RepositoryExists(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || !exists {
+		t.Fatalf("expected repository to exist, got %v (error: %v)", exists, err)
+	}
+
+	exists, err = src.RepositoryExists(context.Background(), "internal", "terraform-provider-missing")
+	if err != nil || exists {
+		t.Fatalf("expected repository to not exist, got %v (error: %v)", exists, err)
+	}
+}
+
+func TestRepositoryMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/internal/terraform-provider-dummy" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"full_name": "internal/terraform-provider-dummy", "description": "A dummy provider", "html_url": "https://github.example.com/internal/terraform-provider-dummy"}`))
+	}))
+	defer server.Close()
+
+	client, err := github.NewEnterpriseManagedGithubClient(server.URL+"/api/v3/", "ghes-token")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	src := github.NewReleaseSource(client, nil)
+
+	metadata, err := src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || metadata == nil {
+		t.Fatalf("expected metadata, got %v (error: %v)", metadata, err)
+	}
+	if metadata.Description != "A dummy provider" || metadata.URL != "https://github.example.com/internal/terraform-provider-dummy" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+
+	metadata, err = src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-missing")
+	if err != nil || metadata != nil {
+		t.Fatalf("expected no metadata, got %v (error: %v)", metadata, err)
+	}
+}
+
+func TestRepositoryMetadataState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/internal/terraform-provider-archived":
+			_, _ = w.Write([]byte(`{"full_name": "internal/terraform-provider-archived", "archived": true}`))
+		case "/api/v3/repos/internal/terraform-provider-old":
+			// the response of the redirect that GitHub answers requests for renamed repositories with
+			_, _ = w.Write([]byte(`{"full_name": "acme/terraform-provider-new"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := github.NewEnterpriseManagedGithubClient(server.URL+"/api/v3/", "ghes-token")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	src := github.NewReleaseSource(client, nil)
+
+	metadata, err := src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-archived")
+	if err != nil || metadata == nil || !metadata.Archived || metadata.MovedTo != "" {
+		t.Errorf("expected an archived repository, got %+v (error: %v)", metadata, err)
+	}
+
+	metadata, err = src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-old")
+	if err != nil || metadata == nil || metadata.MovedTo != "acme/terraform-provider-new" {
+		t.Errorf("expected a moved repository, got %+v (error: %v)", metadata, err)
+	}
+}
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/orgs/internal/repos":
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`[{"name": "terraform-provider-second", "topics": ["cloud"]}]`))
+				return
+			}
+			w.Header().Set("Link", `<`+"http://"+r.Host+`/api/v3/orgs/internal/repos?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[{"name": "terraform-provider-first", "description": "The first provider"}, {"name": "website"}]`))
+		case "/api/v3/users/someone/repos":
+			_, _ = w.Write([]byte(`[{"name": "terraform-provider-personal"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := github.NewEnterpriseManagedGithubClient(server.URL+"/api/v3/", "ghes-token")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	src := github.NewReleaseSource(client, nil)
+
+	repositories, err := src.ListRepositories(context.Background(), "internal")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repositories) != 3 {
+		t.Fatalf("expected 3 repositories, got %d", len(repositories))
+	}
+	if repositories[0].Name != "terraform-provider-first" || repositories[0].Description != "The first provider" {
+		t.Errorf("unexpected first repository %+v", repositories[0])
+	}
+	if repositories[2].Name != "terraform-provider-second" || len(repositories[2].Topics) != 1 || repositories[2].Topics[0] != "cloud" {
+		t.Errorf("unexpected last repository %+v", repositories[2])
+	}
+
+	// owners that are not organizations are listed as users
+	repositories, err = src.ListRepositories(context.Background(), "someone")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repositories) != 1 || repositories[0].Name != "terraform-provider-personal" {
+		t.Errorf("unexpected repositories %+v", repositories)
+	}
+}
+
+func TestEnterpriseDownloadAsset(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/storage/SHA256SUMS" {
+			// pre-signed storage URLs are followed without the token
+			if r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte("checksums"))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer ghes-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v3/repos/internal/terraform-provider-dummy/releases/tags/v1.0.0":
+			_, _ = w.Write([]byte(`{"tag_name": "v1.0.0", "assets": [{"id": 1, "name": "terraform-provider-dummy_1.0.0.zip"}, {"id": 2, "name": "SHA256SUMS"}]}`))
+		case "/api/v3/repos/internal/terraform-provider-dummy/releases/assets/2":
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			http.Redirect(w, r, server.URL+"/storage/SHA256SUMS", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := github.NewEnterpriseManagedGithubClient(server.URL+"/api/v3/", "ghes-token")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	serverURL, _ := url.Parse(server.URL)
+	src := github.NewReleaseSource(client, nil, github.WithAssetHost(serverURL.Host))
+
+	body, err := src.DownloadAsset(context.Background(), source.Asset{
+		Name:        "SHA256SUMS",
+		DownloadURL: server.URL + "/internal/terraform-provider-dummy/releases/download/v1.0.0/SHA256SUMS",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer body.Close()
+	contents, _ := io.ReadAll(body)
+	if string(contents) != "checksums" {
+		t.Errorf("expected the asset contents, got %q", contents)
+	}
+
+	_, err = src.DownloadAsset(context.Background(), source.Asset{
+		Name:        "SHA256SUMS.sig",
+		DownloadURL: server.URL + "/internal/terraform-provider-dummy/releases/download/v1.0.0/SHA256SUMS.sig",
+	})
+	if err == nil {
+		t.Error("expected an error for a missing asset")
+	}
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WebhookEvent is a release or tag pushed to a repository, as reported by a GitHub webhook.
+type WebhookEvent struct {
+	Owner      string
+	Repository string
+	Tag        string
+}
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of a webhook delivery, the hex encoded
+// HMAC-SHA256 of the body prefixed with "sha256=", against the webhook's secret.
+func VerifyWebhookSignature(secret string, body []byte, signature string) error {
+	encoded, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return fmt.Errorf("missing sha256 signature")
+	}
+	decoded, err := hex.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(decoded, mac.Sum(nil)) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+type webhookRepository struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type releasePayload struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName string `json:"tag_name"`
+	} `json:"release"`
+	Repository webhookRepository `json:"repository"`
+}
+
+type pushPayload struct {
+	Ref        string            `json:"ref"`
+	Deleted    bool              `json:"deleted"`
+	Repository webhookRepository `json:"repository"`
+}
+
+// releaseActions are the actions of release events after which the release can be served.
+//
+//nolint:gochecknoglobals // This should be treated as a constant.
+var releaseActions = map[string]bool{
+	"published":   true,
+	"released":    true,
+	"prereleased": true,
+	"edited":      true,
+}
+
+// ParseWebhookEvent reads the payload of a webhook delivery of the given type, from the X-GitHub-Event header.
+// It returns nil for events that do not add a release or tag, such as pushes to branches or deleted releases.
+func ParseWebhookEvent(eventType string, body []byte) (*WebhookEvent, error) {
+	switch eventType {
+	case "release":
+		var payload releasePayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("could not parse release event: %w", err)
+		}
+		if !releaseActions[payload.Action] {
+			return nil, nil //nolint:nilnil // Only new releases are reported.
+		}
+		return newWebhookEvent(payload.Repository, payload.Release.TagName)
+	case "push":
+		var payload pushPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("could not parse push event: %w", err)
+		}
+		tag, ok := strings.CutPrefix(payload.Ref, "refs/tags/")
+		if !ok || payload.Deleted {
+			return nil, nil //nolint:nilnil // Only new tags are reported.
+		}
+		return newWebhookEvent(payload.Repository, tag)
+	default:
+		return nil, nil //nolint:nilnil // Other events are ignored.
+	}
+}
+
+func newWebhookEvent(repository webhookRepository, tag string) (*WebhookEvent, error) {
+	if repository.Owner.Login == "" || repository.Name == "" || tag == "" {
+		return nil, fmt.Errorf("the event does not name a repository and tag")
+	}
+	return &WebhookEvent{Owner: repository.Owner.Login, Repository: repository.Name, Tag: tag}, nil
+}
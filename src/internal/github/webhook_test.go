@@ -0,0 +1,77 @@
+package github_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/opentofu/registry/internal/github"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"action":"published"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := github.VerifyWebhookSignature("secret", body, signature); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+
+	invalid := map[string]struct {
+		secret    string
+		body      string
+		signature string
+	}{
+		"wrong secret":   {secret: "other", body: string(body), signature: signature},
+		"modified body":  {secret: "secret", body: `{"action":"deleted"}`, signature: signature},
+		"missing prefix": {secret: "secret", body: string(body), signature: hex.EncodeToString(mac.Sum(nil))},
+		"not hex":        {secret: "secret", body: string(body), signature: "sha256=zz"},
+		"empty":          {secret: "secret", body: string(body)},
+	}
+	for name, tt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := github.VerifyWebhookSignature(tt.secret, []byte(tt.body), tt.signature); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	repository := `"repository": {"name": "terraform-provider-widget", "owner": {"login": "acme"}}`
+	expected := &github.WebhookEvent{Owner: "acme", Repository: "terraform-provider-widget", Tag: "v1.2.0"}
+
+	tests := []struct {
+		name      string
+		eventType string
+		body      string
+		expected  *github.WebhookEvent
+	}{
+		{"published release", "release", `{"action": "published", "release": {"tag_name": "v1.2.0"}, ` + repository + `}`, expected},
+		{"deleted release", "release", `{"action": "deleted", "release": {"tag_name": "v1.2.0"}, ` + repository + `}`, nil},
+		{"pushed tag", "push", `{"ref": "refs/tags/v1.2.0", ` + repository + `}`, expected},
+		{"deleted tag", "push", `{"ref": "refs/tags/v1.2.0", "deleted": true, ` + repository + `}`, nil},
+		{"pushed branch", "push", `{"ref": "refs/heads/main", ` + repository + `}`, nil},
+		{"other event", "ping", `{"zen": "Keep it logically awesome."}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := github.ParseWebhookEvent(tt.eventType, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := github.ParseWebhookEvent("release", []byte(`{"action": "published"}`)); err == nil {
+		t.Error("expected an error for an event without a repository")
+	}
+	if _, err := github.ParseWebhookEvent("push", []byte(`not json`)); err == nil {
+		t.Error("expected an error for an invalid payload")
+	}
+}
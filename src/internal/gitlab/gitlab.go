@@ -0,0 +1,328 @@
+// Package gitlab reads provider and module releases from a (self-hosted) GitLab instance.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	gitlabRequestTimeout = 60 * time.Second
+	perPage              = 100
+	sincePadding         = 2 * time.Minute
+)
+
+// ReleaseSource implements source.ReleaseSource on top of GitLab Releases and release links.
+type ReleaseSource struct {
+	baseURL    *url.URL
+	token      string
+	group      string
+	httpClient *http.Client
+}
+
+// NewReleaseSource creates a ReleaseSource for the GitLab instance at baseURL (for example https://gitlab.example.com).
+// If group is set, repositories are looked up in that GitLab group (which may be nested, like "platform/terraform")
+// instead of in the group named after the registry namespace.
+func NewReleaseSource(baseURL, token, group string) (*ReleaseSource, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab URL %q: %w", baseURL, err)
+	}
+
+	return &ReleaseSource{
+		baseURL:    parsed,
+		token:      token,
+		group:      group,
+		httpClient: xray.Client(&http.Client{Timeout: gitlabRequestTimeout}),
+	}, nil
+}
+
+// gitlabRelease is a release as returned by the GitLab releases API.
+type gitlabRelease struct {
+	TagName         string    `json:"tag_name"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+	Assets          struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// gitlabProject is a project as returned by the GitLab projects API.
+type gitlabProject struct {
+	Description string   `json:"description"`
+	WebURL      string   `json:"web_url"`
+	Topics      []string `json:"topics"`
+	Archived    bool     `json:"archived"`
+}
+
+type gitlabTag struct {
+	Name string `json:"name"`
+}
+
+func (s *ReleaseSource) projectPath(namespace, name string) string {
+	group := namespace
+	if s.group != "" {
+		group = s.group
+	}
+	return fmt.Sprintf("%s/%s", group, name)
+}
+
+func (s *ReleaseSource) projectURL(namespace, name string) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", s.baseURL, url.PathEscape(s.projectPath(namespace, name)))
+}
+
+func (s *ReleaseSource) RepositoryExists(ctx context.Context, namespace, name string) (exists bool, err error) {
+	err = xray.Capture(ctx, "gitlab.repository.exists", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		slog.Info("Checking if GitLab project exists")
+
+		resp, reqErr := s.get(tracedCtx, s.projectURL(namespace, name))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get project: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			exists = true
+			return nil
+		case http.StatusNotFound:
+			slog.Info("GitLab project does not exist")
+			return nil
+		default:
+			return fmt.Errorf("unexpected status code when getting project: %d", resp.StatusCode)
+		}
+	})
+
+	return exists, err
+}
+
+func (s *ReleaseSource) RepositoryMetadata(ctx context.Context, namespace, name string) (metadata *source.RepositoryMetadata, err error) {
+	err = xray.Capture(ctx, "gitlab.repository.metadata", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		resp, reqErr := s.get(tracedCtx, s.projectURL(namespace, name))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get project: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			slog.Info("GitLab project does not exist")
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code when getting project: %d", resp.StatusCode)
+		}
+
+		var repo gitlabProject
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&repo); decodeErr != nil {
+			return fmt.Errorf("failed to decode project: %w", decodeErr)
+		}
+
+		metadata = &source.RepositoryMetadata{
+			Name:        name,
+			Description: repo.Description,
+			URL:         repo.WebURL,
+			Topics:      repo.Topics,
+			Archived:    repo.Archived,
+		}
+		return nil
+	})
+
+	return metadata, err
+}
+
+func (s *ReleaseSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) (releases []source.Release, err error) {
+	err = xray.Capture(ctx, "gitlab.releases.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		slog.Info("Fetching new GitLab releases")
+
+		for page := "1"; page != ""; {
+			var glReleases []gitlabRelease
+			nextPage, fetchErr := s.getJSONPage(tracedCtx, fmt.Sprintf("%s/releases?order_by=created_at&sort=desc&per_page=%d&page=%s", s.projectURL(namespace, name), perPage, page), &glReleases)
+			if fetchErr != nil {
+				return fmt.Errorf("failed to fetch releases: %w", fetchErr)
+			}
+
+			for _, r := range glReleases {
+				if r.UpcomingRelease {
+					continue
+				}
+
+				// releases are ordered by creation date, so we can stop as soon as we see one older than "since"
+				if since != nil && r.CreatedAt.Before(since.Add(-sincePadding)) {
+					slog.Info("Release was created before given time, stopping reading releases", "release", r.TagName, "created_at", r.CreatedAt, "since", since)
+					return nil
+				}
+
+				releases = append(releases, r.toSourceRelease())
+			}
+
+			page = nextPage
+		}
+
+		return nil
+	})
+
+	slog.Info("GitLab releases fetched", "count", len(releases))
+	return releases, err
+}
+
+func (s *ReleaseSource) FindRelease(ctx context.Context, namespace, name, version string) (release *source.Release, err error) {
+	err = xray.Capture(ctx, "gitlab.release.find", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+		xray.AddAnnotation(tracedCtx, "versionNumber", version)
+
+		resp, reqErr := s.get(tracedCtx, fmt.Sprintf("%s/releases/%s", s.projectURL(namespace, name), url.PathEscape("v"+version)))
+		if reqErr != nil {
+			return fmt.Errorf("failed to get release: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			slog.Info("GitLab release not found")
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code when getting release: %d", resp.StatusCode)
+		}
+
+		var glRelease gitlabRelease
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&glRelease); decodeErr != nil {
+			return fmt.Errorf("failed to decode release: %w", decodeErr)
+		}
+
+		r := glRelease.toSourceRelease()
+		release = &r
+		return nil
+	})
+
+	return release, err
+}
+
+func (s *ReleaseSource) FetchTags(ctx context.Context, namespace, name string) (tags []string, err error) {
+	err = xray.Capture(ctx, "gitlab.tags.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		for page := "1"; page != ""; {
+			var glTags []gitlabTag
+			nextPage, fetchErr := s.getJSONPage(tracedCtx, fmt.Sprintf("%s/repository/tags?per_page=%d&page=%s", s.projectURL(namespace, name), perPage, page), &glTags)
+			if fetchErr != nil {
+				return fmt.Errorf("failed to fetch tags: %w", fetchErr)
+			}
+
+			for _, t := range glTags {
+				tags = append(tags, t.Name)
+			}
+
+			page = nextPage
+		}
+
+		return nil
+	})
+
+	slog.Info("GitLab tags fetched", "count", len(tags))
+	return tags, err
+}
+
+func (s *ReleaseSource) DownloadAsset(ctx context.Context, asset source.Asset) (body io.ReadCloser, err error) {
+	err = xray.Capture(ctx, "gitlab.asset.download", func(tracedCtx context.Context) error {
+		slog.Info("Downloading asset", "url", asset.DownloadURL)
+
+		resp, reqErr := s.get(tracedCtx, asset.DownloadURL)
+		if reqErr != nil {
+			return fmt.Errorf("error downloading asset: %w", reqErr)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status code when downloading asset: %d", resp.StatusCode)
+		}
+
+		body = resp.Body
+		return nil
+	})
+
+	return body, err
+}
+
+func (s *ReleaseSource) ModuleDownloadURL(_ context.Context, namespace, name, tag string) (string, error) {
+	return fmt.Sprintf("git::%s/%s.git?ref=%s", s.baseURL, s.projectPath(namespace, name), tag), nil
+}
+
+// get performs an authenticated GET request. The token is only sent to the configured GitLab host,
+// as release links may point anywhere.
+func (s *ReleaseSource) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if s.token != "" && req.URL.Host == s.baseURL.Host {
+		req.Header.Set("PRIVATE-TOKEN", s.token)
+	}
+
+	return s.httpClient.Do(req)
+}
+
+// getJSONPage decodes a single page of a paginated GitLab API response into target and returns the next page number,
+// which is empty once the last page has been reached.
+func (s *ReleaseSource) getJSONPage(ctx context.Context, rawURL string, target interface{}) (nextPage string, err error) {
+	resp, err := s.get(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+func (r gitlabRelease) toSourceRelease() source.Release {
+	assets := make([]source.Asset, 0, len(r.Assets.Links))
+	for _, link := range r.Assets.Links {
+		downloadURL := link.DirectAssetURL
+		if downloadURL == "" {
+			downloadURL = link.URL
+		}
+		assets = append(assets, source.Asset{
+			Name:        link.Name,
+			DownloadURL: downloadURL,
+		})
+	}
+
+	return source.Release{
+		TagName:   r.TagName,
+		Assets:    assets,
+		CreatedAt: r.CreatedAt,
+	}
+}
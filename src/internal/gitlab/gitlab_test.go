@@ -0,0 +1,182 @@
+package gitlab_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentofu/registry/internal/gitlab"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers"
+)
+
+const testToken = "glpat-test"
+
+// newFakeGitLab serves a minimal subset of the GitLab v4 API for the "platform/terraform-provider-dummy"
+// and "platform/terraform-aws-network" projects.
+func newFakeGitLab(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	requireToken := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("PRIVATE-TOKEN") != testToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	release := func(version string) string {
+		prefix := fmt.Sprintf("terraform-provider-dummy_%s", version)
+		link := func(name string) string {
+			return fmt.Sprintf(`{"name": %q, "url": "%s/files/%s", "direct_asset_url": "%s/files/%s"}`, name, server.URL, name, server.URL, name)
+		}
+		return fmt.Sprintf(`{"tag_name": "v%s", "created_at": "2023-10-01T10:00:00Z", "upcoming_release": false, "assets": {"links": [%s, %s, %s]}}`,
+			version, link(prefix+"_linux_amd64.zip"), link(prefix+"_SHA256SUMS"), link(prefix+"_SHA256SUMS.sig"))
+	}
+
+	mux.HandleFunc("/api/v4/projects/", requireToken(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RawPath {
+		case "/api/v4/projects/platform%2Fterraform-provider-dummy", "/api/v4/projects/platform%2Fterraform-aws-network":
+			fmt.Fprint(w, `{"id": 1, "description": "A dummy provider", "web_url": "https://gitlab.example.com/platform/terraform-provider-dummy"}`)
+		case "/api/v4/projects/platform%2Fterraform-provider-dummy/releases":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprintf(w, "[%s]", release("1.1.0"))
+				return
+			}
+			fmt.Fprintf(w, "[%s]", release("1.0.0"))
+		case "/api/v4/projects/platform%2Fterraform-provider-dummy/releases/v1.0.0":
+			fmt.Fprint(w, release("1.0.0"))
+		case "/api/v4/projects/platform%2Fterraform-aws-network/repository/tags":
+			fmt.Fprint(w, `[{"name": "v2.0.0"}, {"name": "1.0.0"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	mux.HandleFunc("/files/", requireToken(func(w http.ResponseWriter, r *http.Request) {
+		for _, version := range []string{"1.0.0", "1.1.0"} {
+			if r.URL.Path == fmt.Sprintf("/files/terraform-provider-dummy_%s_SHA256SUMS", version) {
+				fmt.Fprintf(w, "abcd  terraform-provider-dummy_%s_linux_amd64.zip\n", version)
+				return
+			}
+		}
+		fmt.Fprint(w, "binary")
+	}))
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestSource(t *testing.T) (*gitlab.ReleaseSource, *httptest.Server) {
+	t.Helper()
+
+	server := newFakeGitLab(t)
+	src, err := gitlab.NewReleaseSource(server.URL, testToken, "platform")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return src, server
+}
+
+func TestRepositoryExists(t *testing.T) {
+	src, _ := newTestSource(t)
+
+	exists, err := src.RepositoryExists(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || !exists {
+		t.Fatalf("expected project to exist, got %v (error: %v)", exists, err)
+	}
+
+	exists, err = src.RepositoryExists(context.Background(), "internal", "terraform-provider-missing")
+	if err != nil || exists {
+		t.Fatalf("expected project to not exist, got %v (error: %v)", exists, err)
+	}
+}
+
+func TestRepositoryMetadata(t *testing.T) {
+	src, _ := newTestSource(t)
+
+	metadata, err := src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || metadata == nil {
+		t.Fatalf("expected metadata, got %v (error: %v)", metadata, err)
+	}
+	if metadata.Description != "A dummy provider" || metadata.URL != "https://gitlab.example.com/platform/terraform-provider-dummy" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+
+	metadata, err = src.RepositoryMetadata(context.Background(), "internal", "terraform-provider-missing")
+	if err != nil || metadata != nil {
+		t.Fatalf("expected no metadata, got %v (error: %v)", metadata, err)
+	}
+}
+
+func TestProviderVersions(t *testing.T) {
+	src, server := newTestSource(t)
+
+	versions, _, err := providers.GetVersions(context.Background(), src, "internal", "terraform-provider-dummy", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions across both pages, got %d", len(versions))
+	}
+
+	for _, v := range versions {
+		details := v.GetVersionDetails("linux", "amd64")
+		if details == nil {
+			t.Fatalf("expected linux_amd64 download details for %s", v.Version)
+		}
+		if details.SHASum != "abcd" {
+			t.Errorf("expected shasum abcd, got %s", details.SHASum)
+		}
+		expectedURL := fmt.Sprintf("%s/files/terraform-provider-dummy_%s_linux_amd64.zip", server.URL, v.Version)
+		if details.DownloadURL != expectedURL {
+			t.Errorf("expected download URL %s, got %s", expectedURL, details.DownloadURL)
+		}
+		if !v.IsSigned() {
+			t.Errorf("expected version %s to be signed", v.Version)
+		}
+	}
+
+	details, err := providers.GetVersion(context.Background(), src, "internal", "terraform-provider-dummy", "1.0.0", "linux", "amd64", providers.UnsignedReleasePolicyRequire)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.SHASum != "abcd" {
+		t.Errorf("expected shasum abcd, got %s", details.SHASum)
+	}
+}
+
+func TestModuleTags(t *testing.T) {
+	src, server := newTestSource(t)
+
+	versions, _, err := modules.GetVersions(context.Background(), src, "internal", "terraform-aws-network", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "2.0.0" || versions[1].Version != "1.0.0" {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+
+	tag, err := modules.ResolveTag(context.Background(), src, "internal", "terraform-aws-network", "2.0.0")
+	if err != nil || tag != "v2.0.0" {
+		t.Fatalf("expected tag v2.0.0, got %q (error: %v)", tag, err)
+	}
+
+	downloadURL, err := src.ModuleDownloadURL(context.Background(), "internal", "terraform-aws-network", tag)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := fmt.Sprintf("git::%s/platform/terraform-aws-network.git?ref=v2.0.0", server.URL)
+	if downloadURL != expected {
+		t.Errorf("expected %s, got %s", expected, downloadURL)
+	}
+}
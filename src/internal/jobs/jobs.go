@@ -0,0 +1,136 @@
+// Package jobs dispatches background jobs, such as populating the cached versions of a provider, to the workers
+// running them. Workers are either another Lambda function, invoked asynchronously, the consumers of a queue, or a
+// pool of goroutines in the same process for local runs.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Job is a unit of background work.
+type Job struct {
+	// Key identifies the work done by the job. Jobs with the same key are duplicates of each other.
+	Key string
+	// Payload is the JSON encoded input of the worker.
+	Payload []byte
+}
+
+// NewJob encodes the payload of a job.
+func NewJob(key string, payload any) (Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("could not encode job payload: %w", err)
+	}
+	return Job{Key: key, Payload: encoded}, nil
+}
+
+// JobDispatcher hands jobs over to the workers running them asynchronously. Dispatch returns once the job is
+// accepted, not once it has run. Dispatchers may drop jobs that duplicate a pending or recently dispatched job.
+type JobDispatcher interface {
+	Dispatch(ctx context.Context, job Job) error
+}
+
+// PopulateProviderVersions is the payload of the jobs populating the cached versions of a provider, which is the
+// event handled by the populate provider versions lambda.
+type PopulateProviderVersions struct {
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	// Force populates the versions even if the cached document is up to date, such as when a webhook reports a new release.
+	Force bool `json:"force,omitempty"`
+	// Trigger identifies the event forcing the job, such as a webhook delivery.
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// Job validates the address of the provider and returns the job populating its versions. Forced jobs do not
+// duplicate the regular ones, so that they are not dropped after a regular job for the same provider, and only
+// duplicate the forced jobs with the same trigger, so that a new release is not dropped after another one.
+func (p PopulateProviderVersions) Job() (Job, error) {
+	for _, part := range []string{p.Namespace, p.Type} {
+		if part == "" || strings.ContainsAny(part, "/ ") {
+			return Job{}, fmt.Errorf("invalid provider address %q", p.Namespace+"/"+p.Type)
+		}
+	}
+
+	key := "populate_provider_versions:" + p.Namespace + "/" + p.Type
+	if p.Force {
+		key += ":force"
+		if p.Trigger != "" {
+			key += ":" + p.Trigger
+		}
+	}
+	return NewJob(key, p)
+}
+
+// Dispatch dispatches the job populating the versions of the provider. It is shared by every function that populates
+// the versions of providers in the background.
+func (p PopulateProviderVersions) Dispatch(ctx context.Context, dispatcher JobDispatcher) error {
+	job, err := p.Job()
+	if err != nil {
+		slog.Error("Invalid populate job", "error", err)
+		return err
+	}
+
+	slog.Info("Dispatching job to populate provider versions", "key", job.Key)
+	if err := dispatcher.Dispatch(ctx, job); err != nil {
+		slog.Error("Error dispatching job", "key", job.Key, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Deduplicate wraps a dispatcher to drop jobs with the key of a job dispatched by this process less than window ago.
+// It suits dispatchers without deduplication of their own, such as the LambdaDispatcher.
+func Deduplicate(next JobDispatcher, window time.Duration) JobDispatcher {
+	return &deduplicator{next: next, window: window, dispatched: make(map[string]time.Time), now: time.Now}
+}
+
+type deduplicator struct {
+	next   JobDispatcher
+	window time.Duration
+
+	mu         sync.Mutex
+	dispatched map[string]time.Time
+	now        func() time.Time
+}
+
+func (d *deduplicator) Dispatch(ctx context.Context, job Job) error {
+	if !d.claim(job.Key) {
+		slog.Info("Dropping duplicate job", "key", job.Key)
+		return nil
+	}
+
+	if err := d.next.Dispatch(ctx, job); err != nil {
+		// the job can be dispatched again right away, as it has not been
+		d.mu.Lock()
+		delete(d.dispatched, job.Key)
+		d.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// claim records the job as dispatched, returning false if it was dispatched within the window.
+func (d *deduplicator) claim(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for k, at := range d.dispatched {
+		if now.Sub(at) >= d.window {
+			delete(d.dispatched, k)
+		}
+	}
+
+	if _, ok := d.dispatched[key]; ok {
+		return false
+	}
+	d.dispatched[key] = now
+	return true
+}
@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+type recordingDispatcher struct {
+	mu   sync.Mutex
+	jobs []Job
+	err  error
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, job Job) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.jobs = append(d.jobs, job)
+	return nil
+}
+
+func TestPopulateProviderVersionsJob(t *testing.T) {
+	job, err := PopulateProviderVersions{Namespace: "acme", Type: `wid"get`}.Job()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var decoded PopulateProviderVersions
+	if err := json.Unmarshal(job.Payload, &decoded); err != nil {
+		t.Fatalf("expected a valid payload, got %v", err)
+	}
+	if decoded.Type != `wid"get` {
+		t.Errorf("expected the type to round trip, got %q", decoded.Type)
+	}
+
+	forced, err := PopulateProviderVersions{Namespace: "acme", Type: `wid"get`, Force: true}.Job()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if forced.Key == job.Key {
+		t.Error("expected forced jobs to have their own key")
+	}
+
+	first, err := PopulateProviderVersions{Namespace: "acme", Type: "widget", Force: true, Trigger: "delivery-1"}.Job()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := PopulateProviderVersions{Namespace: "acme", Type: "widget", Force: true, Trigger: "delivery-2"}.Job()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	again, err := PopulateProviderVersions{Namespace: "acme", Type: "widget", Force: true, Trigger: "delivery-1"}.Job()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if first.Key == second.Key || first.Key != again.Key {
+		t.Errorf("expected forced jobs to be keyed by their trigger, got %q, %q and %q", first.Key, second.Key, again.Key)
+	}
+
+	for _, invalid := range []PopulateProviderVersions{{Type: "widget"}, {Namespace: "acme"}, {Namespace: "acme/widget", Type: "x"}} {
+		if _, err := invalid.Job(); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestDispatchPopulateProviderVersions(t *testing.T) {
+	dispatcher := &recordingDispatcher{}
+	ctx := context.Background()
+
+	if err := (PopulateProviderVersions{Namespace: "acme", Type: "widget"}).Dispatch(ctx, dispatcher); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := (PopulateProviderVersions{Namespace: "acme/widget"}).Dispatch(ctx, dispatcher); err == nil {
+		t.Error("expected an error for an invalid provider address")
+	}
+	if len(dispatcher.jobs) != 1 || dispatcher.jobs[0].Key != "populate_provider_versions:acme/widget" {
+		t.Errorf("expected only the valid job to be dispatched, got %v", dispatcher.jobs)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	next := &recordingDispatcher{}
+	dispatcher := Deduplicate(next, time.Minute).(*deduplicator)
+	now := time.Now()
+	dispatcher.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "a"} {
+		if err := dispatcher.Dispatch(ctx, Job{Key: key}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if len(next.jobs) != 2 {
+		t.Fatalf("expected the duplicate to be dropped, got %d jobs", len(next.jobs))
+	}
+
+	now = now.Add(time.Minute)
+	if err := dispatcher.Dispatch(ctx, Job{Key: "a"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(next.jobs) != 3 {
+		t.Errorf("expected the job to be dispatched again after the window, got %d jobs", len(next.jobs))
+	}
+
+	// failed jobs can be retried right away
+	next.err = errors.New("unavailable")
+	if err := dispatcher.Dispatch(ctx, Job{Key: "c"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	next.err = nil
+	if err := dispatcher.Dispatch(ctx, Job{Key: "c"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(next.jobs) != 4 {
+		t.Errorf("expected the failed job to be dispatched again, got %d jobs", len(next.jobs))
+	}
+}
+
+func TestPool(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var ran []string
+
+	pool := NewPool(1, 10, func(_ context.Context, payload []byte) error {
+		<-release
+		mu.Lock()
+		ran = append(ran, string(payload))
+		mu.Unlock()
+		return nil
+	})
+
+	ctx := context.Background()
+	for _, job := range []Job{{Key: "a", Payload: []byte("a")}, {Key: "b", Payload: []byte("b")}, {Key: "a", Payload: []byte("a again")}} {
+		if err := pool.Dispatch(ctx, job); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	close(release)
+	pool.Close()
+
+	if len(ran) != 2 || ran[0] != "a" || ran[1] != "b" {
+		t.Errorf("expected the duplicate to be dropped, ran %v", ran)
+	}
+	if err := pool.Dispatch(ctx, Job{Key: "c"}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolFull(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewPool(0, 1, func(context.Context, []byte) error { <-release; return nil })
+
+	ctx := context.Background()
+	if err := pool.Dispatch(ctx, Job{Key: "a"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := pool.Dispatch(ctx, Job{Key: "b"}); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("expected ErrPoolFull, got %v", err)
+	}
+	close(release)
+}
+
+func TestQueueDispatcher(t *testing.T) {
+	queue := NewMemoryQueue(DefaultDeduplicationInterval)
+	now := time.Now()
+	queue.now = func() time.Time { return now }
+	dispatcher := NewQueueDispatcher(queue)
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "a"} {
+		if err := dispatcher.Dispatch(ctx, Job{Key: key, Payload: []byte(key)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	received := queue.Receive(10)
+	if len(received) != 2 || received[0].DeduplicationID != "a" || received[1].DeduplicationID != "b" {
+		t.Fatalf("expected the duplicate to be dropped, received %+v", received)
+	}
+	if len(queue.Receive(10)) != 0 {
+		t.Error("expected received messages to be removed")
+	}
+
+	// a duplicate is only dropped within the deduplication interval, even once the original was received
+	_ = dispatcher.Dispatch(ctx, Job{Key: "a"})
+	now = now.Add(DefaultDeduplicationInterval)
+	_ = dispatcher.Dispatch(ctx, Job{Key: "a"})
+	if received := queue.Receive(1); len(received) != 1 {
+		t.Errorf("expected the job to be sent again after the interval, received %+v", received)
+	}
+}
+
+type recordingSender struct {
+	inputs []*sqs.SendMessageInput
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	s.inputs = append(s.inputs, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSQSQueue(t *testing.T) {
+	sender := &recordingSender{}
+	dispatcher := NewQueueDispatcher(NewSQSQueue(sender, "https://sqs.example.com/populate.fifo"))
+
+	ctx := context.Background()
+	longKey := "populate_provider_versions:acme/" + strings.Repeat("w", 128)
+	for _, key := range []string{"populate_provider_versions:acme/widget", "populate_provider_versions:acme/wïdget", longKey} {
+		if err := dispatcher.Dispatch(ctx, Job{Key: key, Payload: []byte(`{"namespace":"acme"}`)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if len(sender.inputs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(sender.inputs))
+	}
+	first := sender.inputs[0]
+	if *first.QueueUrl != "https://sqs.example.com/populate.fifo" || *first.MessageBody != `{"namespace":"acme"}` ||
+		*first.MessageDeduplicationId != "populate_provider_versions:acme/widget" || *first.MessageGroupId != *first.MessageDeduplicationId {
+		t.Errorf("unexpected message %+v", first)
+	}
+	// keys SQS does not accept as IDs are hashed
+	for _, input := range sender.inputs[1:] {
+		if id := *input.MessageDeduplicationId; len(id) != 64 || *input.MessageGroupId != id {
+			t.Errorf("expected a hashed ID, got %q", id)
+		}
+	}
+
+	if err := NewSQSQueue(sender, "").Send(ctx, Message{DeduplicationID: "a"}); err == nil {
+		t.Error("expected an error without a queue URL")
+	}
+}
+
+type recordingInvoker struct {
+	inputs []*lambda.InvokeInput
+	output lambda.InvokeOutput
+}
+
+func (i *recordingInvoker) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	i.inputs = append(i.inputs, params)
+	output := i.output
+	return &output, nil
+}
+
+func TestLambdaDispatcher(t *testing.T) {
+	invoker := &recordingInvoker{}
+	job := Job{Key: "a", Payload: []byte(`{"namespace":"acme"}`)}
+
+	if err := NewLambdaDispatcher(invoker, "populate").Dispatch(context.Background(), job); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(invoker.inputs) != 1 || *invoker.inputs[0].FunctionName != "populate" || string(invoker.inputs[0].Payload) != string(job.Payload) {
+		t.Errorf("unexpected invocations %+v", invoker.inputs)
+	}
+
+	if err := NewLambdaDispatcher(invoker, "").Dispatch(context.Background(), job); err == nil {
+		t.Error("expected an error without a function name")
+	}
+}
+
+func TestLambdaRunner(t *testing.T) {
+	invoker := &recordingInvoker{}
+	run := NewLambdaRunner(invoker, "populate")
+
+	if err := run(context.Background(), []byte(`{"namespace":"acme"}`)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(invoker.inputs) != 1 || invoker.inputs[0].InvocationType != lambdaTypes.InvocationTypeRequestResponse {
+		t.Errorf("expected a synchronous invocation, got %+v", invoker.inputs)
+	}
+
+	invoker.output = lambda.InvokeOutput{FunctionError: aws.String("Unhandled"), Payload: []byte(`{"errorMessage":"boom"}`)}
+	if err := run(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the function error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// LambdaInvoker is the part of the Lambda client used to invoke functions.
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// LambdaDispatcher runs jobs by invoking a Lambda function asynchronously, with the payload of the job as its event.
+// It does not deduplicate jobs, see Deduplicate.
+type LambdaDispatcher struct {
+	client       LambdaInvoker
+	functionName string
+}
+
+func NewLambdaDispatcher(client LambdaInvoker, functionName string) *LambdaDispatcher {
+	return &LambdaDispatcher{client: client, functionName: functionName}
+}
+
+func (d *LambdaDispatcher) Dispatch(ctx context.Context, job Job) error {
+	if d.functionName == "" {
+		return fmt.Errorf("no function to invoke for job %s", job.Key)
+	}
+
+	_, err := d.client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(d.functionName),
+		InvocationType: types.InvocationTypeEvent, // Event == async
+		Payload:        job.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invoke %s: %w", d.functionName, err)
+	}
+	return nil
+}
+
+// NewLambdaRunner returns a function running jobs by invoking a Lambda function synchronously, with the payload of the
+// job as its event, such as to run them on a Pool against a local Lambda endpoint that only supports synchronous
+// invocations.
+func NewLambdaRunner(client LambdaInvoker, functionName string) func(ctx context.Context, payload []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		if functionName == "" {
+			return fmt.Errorf("no function to invoke")
+		}
+
+		output, err := client.Invoke(ctx, &lambda.InvokeInput{
+			FunctionName:   aws.String(functionName),
+			InvocationType: types.InvocationTypeRequestResponse,
+			Payload:        payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to invoke %s: %w", functionName, err)
+		}
+		if output.FunctionError != nil {
+			return fmt.Errorf("%s failed: %s: %s", functionName, aws.ToString(output.FunctionError), output.Payload)
+		}
+		return nil
+	}
+}
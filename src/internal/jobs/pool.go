@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrPoolFull is returned when a job is dispatched to a Pool whose queue is full.
+	ErrPoolFull = errors.New("the job queue is full")
+	// ErrPoolClosed is returned when a job is dispatched to a closed Pool.
+	ErrPoolClosed = errors.New("the job pool is closed")
+)
+
+// Pool runs jobs in the background of the same process, on a fixed number of goroutines. It is meant for local runs
+// of the registry, whose processes outlive the requests dispatching the jobs, unlike Lambda functions. Jobs with the
+// key of a job that is queued or running are dropped.
+type Pool struct {
+	run  func(ctx context.Context, payload []byte) error
+	jobs chan Job
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[string]bool
+	closed  bool
+}
+
+// NewPool starts workers goroutines running the jobs with run, queueing at most queueSize jobs.
+func NewPool(workers, queueSize int, run func(ctx context.Context, payload []byte) error) *Pool {
+	p := &Pool{run: run, jobs: make(chan Job, queueSize), pending: make(map[string]bool)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) Dispatch(_ context.Context, job Job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if p.pending[job.Key] {
+		slog.Info("Dropping duplicate job", "key", job.Key)
+		return nil
+	}
+
+	select {
+	case p.jobs <- job:
+		p.pending[job.Key] = true
+		return nil
+	default:
+		return ErrPoolFull
+	}
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		// jobs outlive the requests dispatching them
+		if err := p.run(context.Background(), job.Payload); err != nil {
+			slog.Error("Job failed", "key", job.Key, "error", err)
+		}
+
+		p.mu.Lock()
+		delete(p.pending, job.Key)
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Message is a job sent to a queue. Like in SQS FIFO queues, a message with the DeduplicationID of a message sent
+// within the queue's deduplication interval is accepted but not delivered.
+type Message struct {
+	DeduplicationID string
+	Body            []byte
+}
+
+// Queue is the sending side of a message queue, such as an SQS FIFO queue.
+type Queue interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// QueueDispatcher runs jobs by sending them to a queue, whose consumers run them. The key of a job is its
+// deduplication ID, so the queue drops duplicate jobs.
+type QueueDispatcher struct {
+	queue Queue
+}
+
+func NewQueueDispatcher(queue Queue) *QueueDispatcher {
+	return &QueueDispatcher{queue: queue}
+}
+
+func (d *QueueDispatcher) Dispatch(ctx context.Context, job Job) error {
+	return d.queue.Send(ctx, Message{DeduplicationID: job.Key, Body: job.Payload})
+}
+
+// DefaultDeduplicationInterval is the deduplication interval of SQS FIFO queues.
+const DefaultDeduplicationInterval = 5 * time.Minute
+
+// MemoryQueue is an in-memory stand-in for an SQS FIFO queue, for running the registry locally and for tests.
+type MemoryQueue struct {
+	interval time.Duration
+
+	mu       sync.Mutex
+	messages []Message
+	sent     map[string]time.Time
+	now      func() time.Time
+}
+
+// NewMemoryQueue creates an empty queue dropping duplicate messages sent within the given interval.
+func NewMemoryQueue(interval time.Duration) *MemoryQueue {
+	return &MemoryQueue{interval: interval, sent: make(map[string]time.Time), now: time.Now}
+}
+
+func (q *MemoryQueue) Send(_ context.Context, message Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	for id, sentAt := range q.sent {
+		if now.Sub(sentAt) >= q.interval {
+			delete(q.sent, id)
+		}
+	}
+	if _, ok := q.sent[message.DeduplicationID]; ok {
+		return nil
+	}
+	q.sent[message.DeduplicationID] = now
+	q.messages = append(q.messages, message)
+	return nil
+}
+
+// Receive removes and returns up to max messages from the front of the queue.
+func (q *MemoryQueue) Receive(max int) []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if max > len(q.messages) {
+		max = len(q.messages)
+	}
+	received := q.messages[:max:max]
+	q.messages = q.messages[max:]
+	return received
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// SQSSender is the part of the SQS client used to send messages.
+type SQSSender interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQSQueue sends messages to an SQS FIFO queue. Each deduplication ID is also the message group of its messages, so
+// messages for different keys are delivered in parallel while duplicates are dropped by SQS.
+type SQSQueue struct {
+	client   SQSSender
+	queueURL string
+}
+
+func NewSQSQueue(client SQSSender, queueURL string) *SQSQueue {
+	return &SQSQueue{client: client, queueURL: queueURL}
+}
+
+func (q *SQSQueue) Send(ctx context.Context, message Message) error {
+	if q.queueURL == "" {
+		return fmt.Errorf("no queue to send message %s to", message.DeduplicationID)
+	}
+
+	id := sqsMessageID(message.DeduplicationID)
+	_, err := q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:               aws.String(q.queueURL),
+		MessageBody:            aws.String(string(message.Body)),
+		MessageDeduplicationId: aws.String(id),
+		MessageGroupId:         aws.String(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message %s: %w", message.DeduplicationID, err)
+	}
+	return nil
+}
+
+// sqsMessageID returns the deduplication ID as is if SQS accepts it as a deduplication and group ID, which are at
+// most 128 printable ASCII characters without spaces, and its SHA-256 hash otherwise.
+func sqsMessageID(deduplicationID string) string {
+	valid := deduplicationID != "" && len(deduplicationID) <= 128
+	for _, r := range deduplicationID {
+		if r <= ' ' || r > '~' {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return deduplicationID
+	}
+
+	sum := sha256.Sum256([]byte(deduplicationID))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,341 @@
+// Package mappings resolves provider and module addresses to the repositories their releases are read from.
+//
+// By default, the provider "<namespace>/<type>" is read from the "terraform-provider-<type>" repository owned by
+// the namespace, and the module "<namespace>/<name>/<system>" from the "terraform-<system>-<name>" repository.
+// A mappings file can override this for individual addresses, either by redirecting them to another address or
+// by naming the owner, repository and tag prefix to read them from:
+//
+//	{
+//	  "providers": {
+//	    "hashicorp/aws": {"redirect": "opentofu/aws"},
+//	    "acme/widget": {"owner": "acme-corp", "repository": "widget-tools", "tag_prefix": "provider/"}
+//	  },
+//	  "modules": {
+//	    "acme/vpc/aws": {"repository": "infrastructure", "tag_prefix": "modules/vpc/"}
+//	  }
+//	}
+//
+// Redirects can be chained, and namespace redirects apply to providers that do not have a mapping of their own.
+package mappings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers"
+)
+
+// Mapping overrides where a single provider or module is read from. It either redirects to another address,
+// or sets any of the owner, repository and tag prefix, leaving the others at their defaults.
+type Mapping struct {
+	Redirect   string `json:"redirect,omitempty"`   // The address to serve this address from instead.
+	Owner      string `json:"owner,omitempty"`      // The owner of the repository, defaults to the namespace.
+	Repository string `json:"repository,omitempty"` // The name of the repository.
+	TagPrefix  string `json:"tag_prefix,omitempty"` // The prefix of the tags of this provider or module, such as "aws/" in monorepos.
+}
+
+// File is the format of the mappings file, keyed by provider ("<namespace>/<type>") and module ("<namespace>/<name>/<system>") addresses.
+type File struct {
+	Providers map[string]Mapping `json:"providers"`
+	Modules   map[string]Mapping `json:"modules"`
+}
+
+// ProviderLocation is where the releases of a provider are read from.
+type ProviderLocation struct {
+	// Namespace and Type are the effective address of the provider, after following redirects. The effective
+	// address is used for the cache, signing keys and release source of the provider.
+	Namespace string
+	Type      string
+
+	Owner      string
+	Repository string
+	TagPrefix  string
+}
+
+// ModuleLocation is where the releases of a module are read from.
+type ModuleLocation struct {
+	// Namespace, Name and System are the effective address of the module, after following redirects.
+	Namespace string
+	Name      string
+	System    string
+
+	Owner      string
+	Repository string
+	TagPrefix  string
+}
+
+// Mappings resolves addresses to locations. A nil Mappings resolves every address to its default location.
+type Mappings struct {
+	providers          map[string]Mapping
+	modules            map[string]Mapping
+	namespaceRedirects map[string]string
+}
+
+// Load reads and validates the mappings file at the given path. An empty path only uses the namespace redirects.
+func Load(path string, namespaceRedirects map[string]string) (*Mappings, error) {
+	var file File
+	if path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read mappings file: %w", err)
+		}
+		if err := json.Unmarshal(contents, &file); err != nil {
+			return nil, fmt.Errorf("could not parse mappings file %s: %w", path, err)
+		}
+	}
+
+	return New(file, namespaceRedirects)
+}
+
+// New validates the mappings and namespace redirects, returning an error for invalid addresses and redirect loops.
+func New(file File, namespaceRedirects map[string]string) (*Mappings, error) {
+	m := &Mappings{providers: file.Providers, modules: file.Modules, namespaceRedirects: namespaceRedirects}
+
+	for namespace, redirect := range namespaceRedirects {
+		if namespace == "" || redirect == "" || strings.Contains(namespace, "/") || strings.Contains(redirect, "/") {
+			return nil, fmt.Errorf("invalid namespace redirect from %q to %q", namespace, redirect)
+		}
+		if _, err := m.resolveNamespace(namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	for address, mapping := range file.Providers {
+		if err := validateMapping(address, mapping, 2); err != nil {
+			return nil, fmt.Errorf("invalid provider mapping: %w", err)
+		}
+		if _, err := m.resolveProvider(address); err != nil {
+			return nil, err
+		}
+	}
+
+	for address, mapping := range file.Modules {
+		if err := validateMapping(address, mapping, 3); err != nil {
+			return nil, fmt.Errorf("invalid module mapping: %w", err)
+		}
+		if _, err := m.resolveModule(address); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func validateMapping(address string, mapping Mapping, parts int) error {
+	if !isAddress(address, parts) {
+		return fmt.Errorf("%q is not a valid address", address)
+	}
+	if mapping.Redirect == "" {
+		if mapping.Owner == "" && mapping.Repository == "" && mapping.TagPrefix == "" {
+			return fmt.Errorf("%s must set a redirect, owner, repository or tag prefix", address)
+		}
+		return nil
+	}
+
+	if mapping.Owner != "" || mapping.Repository != "" || mapping.TagPrefix != "" {
+		return fmt.Errorf("%s cannot set an owner, repository or tag prefix along with a redirect", address)
+	}
+	if !isAddress(mapping.Redirect, parts) {
+		return fmt.Errorf("%s redirects to %q, which is not a valid address", address, mapping.Redirect)
+	}
+	return nil
+}
+
+func isAddress(address string, parts int) bool {
+	segments := strings.Split(address, "/")
+	if len(segments) != parts {
+		return false
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Provider returns the location of the given provider. Resolving the effective address of a location
+// returns the same location, so the effective address can be passed on to other lambdas.
+func (m *Mappings) Provider(namespace, providerType string) ProviderLocation {
+	address := namespace + "/" + providerType
+	if m != nil {
+		// loops have been ruled out when the mappings were created
+		address, _ = m.resolveProvider(address)
+	}
+
+	effectiveNamespace, effectiveType, _ := strings.Cut(address, "/")
+	location := ProviderLocation{
+		Namespace:  effectiveNamespace,
+		Type:       effectiveType,
+		Owner:      effectiveNamespace,
+		Repository: providers.GetRepoName(effectiveType),
+	}
+	if m != nil {
+		applyMapping(m.providers[address], &location.Owner, &location.Repository, &location.TagPrefix)
+	}
+	return location
+}
+
+// ProvidersInRepository returns the locations of the providers whose releases are read from the given repository,
+// either because it is named after the provider or because a mapping reads a provider from it. Owner and repository
+// names are compared case-insensitively, as on GitHub.
+func (m *Mappings) ProvidersInRepository(owner, repository string) []ProviderLocation {
+	var candidates []ProviderLocation
+	if providerType, ok := providers.GetProviderType(repository); ok {
+		candidates = append(candidates, m.Provider(strings.ToLower(owner), strings.ToLower(providerType)))
+	}
+	if m != nil {
+		for address := range m.providers {
+			namespace, providerType, _ := strings.Cut(address, "/")
+			candidates = append(candidates, m.Provider(namespace, providerType))
+		}
+	}
+
+	var locations []ProviderLocation
+	seen := map[ProviderLocation]bool{}
+	for _, location := range candidates {
+		if seen[location] || !strings.EqualFold(location.Owner, owner) || !strings.EqualFold(location.Repository, repository) {
+			continue
+		}
+		seen[location] = true
+		locations = append(locations, location)
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].Namespace != locations[j].Namespace {
+			return locations[i].Namespace < locations[j].Namespace
+		}
+		return locations[i].Type < locations[j].Type
+	})
+	return locations
+}
+
+// Module returns the location of the given module, see Provider.
+func (m *Mappings) Module(namespace, name, system string) ModuleLocation {
+	address := strings.Join([]string{namespace, name, system}, "/")
+	if m != nil {
+		address, _ = m.resolveModule(address)
+	}
+
+	segments := strings.Split(address, "/")
+	location := ModuleLocation{
+		Namespace:  segments[0],
+		Name:       segments[1],
+		System:     segments[2],
+		Owner:      segments[0],
+		Repository: modules.GetRepoName(segments[2], segments[1]),
+	}
+	if m != nil {
+		applyMapping(m.modules[address], &location.Owner, &location.Repository, &location.TagPrefix)
+	}
+	return location
+}
+
+// EffectiveNamespace returns the namespace the given provider namespace is redirected to, following chained redirects.
+func (m *Mappings) EffectiveNamespace(namespace string) string {
+	if m == nil {
+		return namespace
+	}
+
+	// loops have been ruled out when the mappings were created
+	namespace, _ = m.resolveNamespace(namespace)
+	return namespace
+}
+
+func applyMapping(mapping Mapping, owner, repository, tagPrefix *string) {
+	if mapping.Owner != "" {
+		*owner = mapping.Owner
+	}
+	if mapping.Repository != "" {
+		*repository = mapping.Repository
+	}
+	*tagPrefix = mapping.TagPrefix
+}
+
+// resolveProvider follows the redirects of a provider address, preferring the provider's own mapping over its namespace redirect.
+func (m *Mappings) resolveProvider(address string) (string, error) {
+	return resolve(address, func(current string) (string, bool) {
+		if mapping, ok := m.providers[current]; ok {
+			return mapping.Redirect, mapping.Redirect != ""
+		}
+
+		namespace, providerType, _ := strings.Cut(current, "/")
+		if redirect, ok := m.namespaceRedirects[namespace]; ok {
+			return redirect + "/" + providerType, true
+		}
+		return "", false
+	})
+}
+
+// resolveNamespace follows the redirects of a provider namespace.
+func (m *Mappings) resolveNamespace(namespace string) (string, error) {
+	return resolve(namespace, func(current string) (string, bool) {
+		redirect, ok := m.namespaceRedirects[current]
+		return redirect, ok
+	})
+}
+
+// resolveModule follows the redirects of a module address.
+func (m *Mappings) resolveModule(address string) (string, error) {
+	return resolve(address, func(current string) (string, bool) {
+		mapping, ok := m.modules[current]
+		return mapping.Redirect, ok && mapping.Redirect != ""
+	})
+}
+
+// resolve follows redirects from the given address until an address without a redirect, returning an error on loops.
+func resolve(address string, next func(string) (string, bool)) (string, error) {
+	chain := []string{address}
+	seen := map[string]bool{address: true}
+	for {
+		redirect, ok := next(address)
+		if !ok {
+			return address, nil
+		}
+
+		chain = append(chain, redirect)
+		if seen[redirect] {
+			return address, fmt.Errorf("redirect loop: %s", strings.Join(chain, " -> "))
+		}
+		seen[redirect] = true
+		address = redirect
+	}
+}
+
+// ProviderMove returns the location of a provider whose repository was renamed or transferred to movedTo
+// ("<owner>/<name>"). If the new repository is named like the repository of a provider, the provider is served from
+// the address of that repository instead, and true is returned. Otherwise the provider keeps its address and its
+// releases are read from the new repository.
+func (m *Mappings) ProviderMove(location ProviderLocation, movedTo string) (ProviderLocation, bool) {
+	owner, repository, ok := strings.Cut(movedTo, "/")
+	if !ok || owner == "" || repository == "" {
+		return location, false
+	}
+
+	// repositories holding several providers are not renamed to the name of a single one
+	if providerType, ok := providers.GetProviderType(repository); ok && location.TagPrefix == "" {
+		moved := m.Provider(strings.ToLower(owner), strings.ToLower(providerType))
+		if moved.Namespace != location.Namespace || moved.Type != location.Type {
+			return moved, true
+		}
+	}
+
+	location.Owner, location.Repository = owner, repository
+	return location, false
+}
+
+// ModuleMove returns the location of a module whose repository was renamed or transferred to movedTo ("<owner>/<name>").
+// The module keeps its address and its releases are read from the new repository.
+func (m *Mappings) ModuleMove(location ModuleLocation, movedTo string) ModuleLocation {
+	owner, repository, ok := strings.Cut(movedTo, "/")
+	if !ok || owner == "" || repository == "" {
+		return location
+	}
+
+	location.Owner, location.Repository = owner, repository
+	return location
+}
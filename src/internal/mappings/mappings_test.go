@@ -0,0 +1,211 @@
+package mappings_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry/internal/mappings"
+)
+
+func TestProvider(t *testing.T) {
+	m, err := mappings.New(mappings.File{
+		Providers: map[string]mappings.Mapping{
+			"legacy/aws":    {Redirect: "hashicorp/aws"},
+			"acme/widget":   {Owner: "acme-corp", Repository: "widget-tools", TagPrefix: "provider/"},
+			"renamed/thing": {Redirect: "acme/widget"},
+		},
+	}, map[string]string{"hashicorp": "opentofu", "old": "legacy"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		address  string
+		expected mappings.ProviderLocation
+	}{
+		{"foo/bar", mappings.ProviderLocation{Namespace: "foo", Type: "bar", Owner: "foo", Repository: "terraform-provider-bar"}},
+		{"hashicorp/aws", mappings.ProviderLocation{Namespace: "opentofu", Type: "aws", Owner: "opentofu", Repository: "terraform-provider-aws"}},
+		// old -> legacy by namespace, then legacy/aws -> hashicorp/aws -> opentofu/aws
+		{"old/aws", mappings.ProviderLocation{Namespace: "opentofu", Type: "aws", Owner: "opentofu", Repository: "terraform-provider-aws"}},
+		{"renamed/thing", mappings.ProviderLocation{Namespace: "acme", Type: "widget", Owner: "acme-corp", Repository: "widget-tools", TagPrefix: "provider/"}},
+	}
+	for _, tt := range tests {
+		namespace, providerType, _ := strings.Cut(tt.address, "/")
+		got := m.Provider(namespace, providerType)
+		if got != tt.expected {
+			t.Errorf("Provider(%s) = %+v, want %+v", tt.address, got, tt.expected)
+		}
+
+		// the effective address must resolve to the same location
+		if again := m.Provider(got.Namespace, got.Type); again != got {
+			t.Errorf("Provider(%s/%s) = %+v, want %+v", got.Namespace, got.Type, again, got)
+		}
+	}
+
+	if got := m.EffectiveNamespace("old"); got != "legacy" {
+		t.Errorf("EffectiveNamespace(old) = %s, want legacy", got)
+	}
+}
+
+func TestProvidersInRepository(t *testing.T) {
+	m, err := mappings.New(mappings.File{
+		Providers: map[string]mappings.Mapping{
+			"acme/widget":   {Owner: "acme-corp", Repository: "widget-tools", TagPrefix: "widget/"},
+			"acme/gadget":   {Owner: "acme-corp", Repository: "widget-tools", TagPrefix: "gadget/"},
+			"renamed/thing": {Redirect: "acme/widget"},
+			"acme/other":    {Repository: "other-provider"},
+		},
+	}, map[string]string{"hashicorp": "opentofu"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		owner, repository string
+		expected          []string
+	}{
+		{"Acme-Corp", "Widget-Tools", []string{"acme/gadget", "acme/widget"}},
+		{"foo", "terraform-provider-bar", []string{"foo/bar"}},
+		{"acme", "other-provider", []string{"acme/other"}},
+		// acme/other is read from another repository, and hashicorp providers from the opentofu namespace
+		{"acme", "terraform-provider-other", nil},
+		{"hashicorp", "terraform-provider-aws", nil},
+		{"acme", "widget-tools", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, location := range m.ProvidersInRepository(tt.owner, tt.repository) {
+			got = append(got, location.Namespace+"/"+location.Type)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("ProvidersInRepository(%s, %s) = %v, want %v", tt.owner, tt.repository, got, tt.expected)
+		}
+	}
+}
+
+func TestModule(t *testing.T) {
+	m, err := mappings.New(mappings.File{
+		Modules: map[string]mappings.Mapping{
+			"acme/vpc/aws":     {Repository: "infrastructure", TagPrefix: "modules/vpc/"},
+			"acme/network/aws": {Redirect: "acme/vpc/aws"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := mappings.ModuleLocation{Namespace: "acme", Name: "vpc", System: "aws", Owner: "acme", Repository: "infrastructure", TagPrefix: "modules/vpc/"}
+	if got := m.Module("acme", "network", "aws"); got != expected {
+		t.Errorf("Module(acme/network/aws) = %+v, want %+v", got, expected)
+	}
+
+	expected = mappings.ModuleLocation{Namespace: "foo", Name: "bar", System: "aws", Owner: "foo", Repository: "terraform-aws-bar"}
+	if got := m.Module("foo", "bar", "aws"); got != expected {
+		t.Errorf("Module(foo/bar/aws) = %+v, want %+v", got, expected)
+	}
+}
+
+func TestNilMappings(t *testing.T) {
+	var m *mappings.Mappings
+	expected := mappings.ProviderLocation{Namespace: "foo", Type: "bar", Owner: "foo", Repository: "terraform-provider-bar"}
+	if got := m.Provider("foo", "bar"); got != expected {
+		t.Errorf("Provider(foo/bar) = %+v, want %+v", got, expected)
+	}
+}
+
+func TestInvalidMappings(t *testing.T) {
+	tests := map[string]struct {
+		file      mappings.File
+		redirects map[string]string
+	}{
+		"provider loop": {file: mappings.File{Providers: map[string]mappings.Mapping{
+			"a/x": {Redirect: "b/x"},
+			"b/x": {Redirect: "c/x"},
+			"c/x": {Redirect: "a/x"},
+		}}},
+		"loop through namespace redirect": {
+			file:      mappings.File{Providers: map[string]mappings.Mapping{"b/x": {Redirect: "a/x"}}},
+			redirects: map[string]string{"a": "b"},
+		},
+		"namespace loop":       {redirects: map[string]string{"a": "b", "b": "a"}},
+		"invalid address":      {file: mappings.File{Providers: map[string]mappings.Mapping{"a": {Owner: "b"}}}},
+		"invalid redirect":     {file: mappings.File{Modules: map[string]mappings.Mapping{"a/b/c": {Redirect: "a/b"}}}},
+		"empty mapping":        {file: mappings.File{Providers: map[string]mappings.Mapping{"a/b": {}}}},
+		"redirect with owner":  {file: mappings.File{Providers: map[string]mappings.Mapping{"a/b": {Redirect: "c/b", Owner: "c"}}}},
+		"invalid namespace":    {redirects: map[string]string{"a/b": "c"}},
+		"module self redirect": {file: mappings.File{Modules: map[string]mappings.Mapping{"a/b/c": {Redirect: "a/b/c"}}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mappings.New(tt.file, tt.redirects); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.json")
+	contents := `{"providers": {"acme/widget": {"repository": "widget-tools"}}}`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mappings.Load(path, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := m.Provider("acme", "widget").Repository; got != "widget-tools" {
+		t.Errorf("expected repository widget-tools, got %s", got)
+	}
+
+	if _, err := mappings.Load(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestProviderMove(t *testing.T) {
+	m, err := mappings.New(mappings.File{
+		Providers: map[string]mappings.Mapping{
+			"acme/widget": {Owner: "acme-corp", Repository: "widget-tools", TagPrefix: "provider/"},
+		},
+	}, map[string]string{"hashicorp": "opentofu"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		address         string
+		movedTo         string
+		expected        mappings.ProviderLocation
+		expectedChanged bool
+	}{
+		// transferred to another owner, served from the address of the new repository
+		{"foo/bar", "Baz/terraform-provider-bar", mappings.ProviderLocation{Namespace: "baz", Type: "bar", Owner: "baz", Repository: "terraform-provider-bar"}, true},
+		// renamed to another provider type
+		{"foo/bar", "foo/terraform-provider-qux", mappings.ProviderLocation{Namespace: "foo", Type: "qux", Owner: "foo", Repository: "terraform-provider-qux"}, true},
+		// the new address is redirected too
+		{"foo/aws", "hashicorp/terraform-provider-aws", mappings.ProviderLocation{Namespace: "opentofu", Type: "aws", Owner: "opentofu", Repository: "terraform-provider-aws"}, true},
+		// only the case of the owner changed
+		{"foo/bar", "Foo/terraform-provider-bar", mappings.ProviderLocation{Namespace: "foo", Type: "bar", Owner: "Foo", Repository: "terraform-provider-bar"}, false},
+		// the new repository is not named like a provider repository
+		{"foo/bar", "foo/bar-provider", mappings.ProviderLocation{Namespace: "foo", Type: "bar", Owner: "foo", Repository: "bar-provider"}, false},
+		// monorepos keep their tag prefix
+		{"acme/widget", "acme/terraform-provider-tools", mappings.ProviderLocation{Namespace: "acme", Type: "widget", Owner: "acme", Repository: "terraform-provider-tools", TagPrefix: "provider/"}, false},
+		{"foo/bar", "invalid", mappings.ProviderLocation{Namespace: "foo", Type: "bar", Owner: "foo", Repository: "terraform-provider-bar"}, false},
+	}
+	for _, tt := range tests {
+		namespace, providerType, _ := strings.Cut(tt.address, "/")
+		got, changed := m.ProviderMove(m.Provider(namespace, providerType), tt.movedTo)
+		if got != tt.expected || changed != tt.expectedChanged {
+			t.Errorf("ProviderMove(%s, %s) = %+v, %t, want %+v, %t", tt.address, tt.movedTo, got, changed, tt.expected, tt.expectedChanged)
+		}
+	}
+
+	moved := m.ModuleMove(m.Module("acme", "vpc", "aws"), "acme-corp/vpc")
+	if moved.Namespace != "acme" || moved.Owner != "acme-corp" || moved.Repository != "vpc" {
+		t.Errorf("unexpected moved module location %+v", moved)
+	}
+}
@@ -1,9 +1,26 @@
 package modules
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // GetRepoName returns the repo name for a module
 // The repo name should match the format `terraform-<system>-<name>`
 func GetRepoName(system, name string) string {
 	return fmt.Sprintf("terraform-%s-%s", system, name)
 }
+
+// GetModuleSystemAndName returns the system and name of the module hosted in a repository, and false if the repository
+// name does not match the format `terraform-<system>-<name>`. The system cannot contain dashes, while the name can.
+func GetModuleSystemAndName(repoName string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(repoName, "terraform-")
+	if !ok {
+		return "", "", false
+	}
+	system, name, ok := strings.Cut(rest, "-")
+	if !ok || system == "" || name == "" {
+		return "", "", false
+	}
+	return system, name, true
+}
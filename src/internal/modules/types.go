@@ -2,6 +2,10 @@ package modules
 
 type Version struct {
 	Version string `json:"version"`
+
+	// IsPrerelease indicates if the version is a prerelease, by its version number or as marked by the release source.
+	// It is not part of the v1 API response.
+	IsPrerelease bool `json:"-"`
 }
 
 // VersionDetails provides comprehensive details about a specific provider version.
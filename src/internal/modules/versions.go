@@ -3,38 +3,105 @@ package modules
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-xray-sdk-go/xray"
-	"github.com/shurcooL/githubv4"
 	"golang.org/x/exp/slog"
 
-	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/semver"
+	"github.com/opentofu/registry/internal/source"
 )
 
-// GetVersions fetches a list of versions for a GitHub repository identified by its namespace and name.
-func GetVersions(ctx context.Context, ghClient *githubv4.Client, namespace string, name string, since *time.Time) (versions []Version, err error) {
+// GetVersions fetches a list of versions for a repository identified by its namespace and name from the given release source.
+// If the source can list git tags, the versions are taken from the tags instead of the releases.
+// The versions are sorted from the newest to the oldest, and a warning is returned for each tag that was skipped because it is
+// not a valid semantic version.
+func GetVersions(ctx context.Context, src source.ReleaseSource, namespace string, name string, since *time.Time) (versions []Version, warnings []string, err error) {
 	err = xray.Capture(ctx, "module.versions", func(tracedCtx context.Context) error {
 		xray.AddAnnotation(tracedCtx, "namespace", namespace)
 		xray.AddAnnotation(tracedCtx, "name", name)
 
-		slog.Info("Fetching releases")
-
-		releases, fetchErr := github.FetchReleases(tracedCtx, ghClient, namespace, name, since)
-		if err != nil {
-			return fmt.Errorf("failed to fetch releases: %w", fetchErr)
+		releases, fetchErr := fetchReleases(tracedCtx, src, namespace, name, since)
+		if fetchErr != nil {
+			return fetchErr
 		}
 
 		for _, release := range releases {
+			version, invalidErr := semver.Normalize(release.TagName)
+			if invalidErr != nil {
+				slog.Warn("Skipping tag with invalid version", "tag", release.TagName)
+				warnings = append(warnings, fmt.Sprintf("Skipped tag %s: %s", release.TagName, invalidErr))
+				continue
+			}
+
 			versions = append(versions, Version{
-				// Normalize the version string to remove the leading "v" if it exists.
-				Version: strings.TrimPrefix(release.TagName, "v"),
+				Version: version,
+				// the version number is preferred, but releases can also be marked as prereleases on the release source
+				IsPrerelease: semver.IsPrerelease(version) || release.IsPrerelease,
 			})
 		}
 
+		semver.SortDescending(versions, func(v Version) string { return v.Version })
 		return nil
 	})
 
-	return versions, err
+	return versions, warnings, err
+}
+
+// fetchReleases returns the releases of a repository, or its tags as releases without assets if the source can list tags.
+func fetchReleases(ctx context.Context, src source.ReleaseSource, namespace string, name string, since *time.Time) ([]source.Release, error) {
+	if tagSrc, ok := src.(source.TagSource); ok {
+		slog.Info("Fetching tags")
+
+		tags, err := tagSrc.FetchTags(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+		}
+
+		releases := make([]source.Release, 0, len(tags))
+		for _, tag := range tags {
+			releases = append(releases, source.Release{TagName: tag})
+		}
+		return releases, nil
+	}
+
+	slog.Info("Fetching releases")
+
+	releases, err := src.FetchReleases(ctx, namespace, name, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	return releases, nil
+}
+
+// ResolveTag returns the git tag of the given module version. Tags with a "v" prefix are preferred,
+// and if no matching tag or release can be found, the version itself is assumed to be the tag.
+func ResolveTag(ctx context.Context, src source.ReleaseSource, namespace string, name string, version string) (string, error) {
+	if tagSrc, ok := src.(source.TagSource); ok {
+		tags, err := tagSrc.FetchTags(ctx, namespace, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch tags: %w", err)
+		}
+
+		for _, tag := range tags {
+			if tag == fmt.Sprintf("v%s", version) {
+				return tag, nil
+			}
+		}
+		return version, nil
+	}
+
+	// First we check if a tag with "v" prefix exists in the release source
+	release, err := src.FindRelease(ctx, namespace, name, version)
+	if err != nil {
+		return "", err
+	}
+
+	// If the release exists, then the tag does have the "v" prefix
+	// If it does not, then we assume the tag exists without the "v" prefix
+	if release != nil {
+		return release.TagName, nil
+	}
+
+	return version, nil
 }
@@ -0,0 +1,523 @@
+// Package oci reads provider and module releases from an OCI distribution registry.
+//
+// Each version is expected to be pushed as an OCI artifact tagged with the version number (with or without a "v" prefix),
+// with one layer per release file and the filename in the layer's "org.opencontainers.image.title" annotation, as done by
+// tools like ORAS. For a provider these are the platform zips, SHA256SUMS, its signature and the manifest, for a module a
+// single .tar.gz, .tgz or .zip archive of its source.
+//
+// Clients cannot follow the token challenges registries answer blob requests with, so blob URLs are resolved to the
+// pre-signed storage URL the registry redirects to when they are served. Registries that serve blobs themselves can only
+// be used if they allow anonymous blob requests.
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	MediaTypeImageManifest  = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	AnnotationTitle   = "org.opencontainers.image.title"
+	AnnotationCreated = "org.opencontainers.image.created"
+
+	requestTimeout = 60 * time.Second
+	tagsPageSize   = 100
+)
+
+//nolint:gochecknoglobals // These should be treated as constants.
+var (
+	versionTagPattern = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+`)
+	challengePattern  = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	nextLinkPattern   = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// ReleaseSource implements source.ReleaseSource on top of the OCI distribution API.
+type ReleaseSource struct {
+	baseURL          *url.URL
+	credentials      string
+	repositoryPrefix string
+	httpClient       *http.Client
+	// resolveClient does not follow redirects, so that the storage URLs blobs are redirected to can be handed to clients
+	resolveClient *http.Client
+
+	// tokens caches bearer tokens obtained from the registry's token service by scope
+	tokens   map[string]string
+	tokensMu sync.Mutex
+}
+
+// NewReleaseSource creates a ReleaseSource for the registry at baseURL (for example https://registry.example.com).
+// Repositories are named "<repositoryPrefix>/<namespace>/<name>", or "<namespace>/<name>" without a prefix.
+//
+// credentials are either "<username>:<password>", used for basic authentication and to obtain tokens from the
+// registry's token service, or a token that is sent as is. Without credentials, anonymous tokens are requested
+// when the registry asks for them.
+func NewReleaseSource(baseURL, credentials, repositoryPrefix string) (*ReleaseSource, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI registry URL %q: %w", baseURL, err)
+	}
+
+	return &ReleaseSource{
+		baseURL:          parsed,
+		credentials:      credentials,
+		repositoryPrefix: strings.Trim(repositoryPrefix, "/"),
+		httpClient:       xray.Client(&http.Client{Timeout: requestTimeout}),
+		resolveClient: xray.Client(&http.Client{
+			Timeout: requestTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}),
+		tokens: make(map[string]string),
+	}, nil
+}
+
+// Manifest is an OCI image manifest, reduced to the fields used to find release files.
+type Manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Layers      []Descriptor      `json:"layers"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Descriptor describes a blob referenced by a manifest.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type tagList struct {
+	Tags []string `json:"tags"`
+}
+
+func (s *ReleaseSource) repository(namespace, name string) string {
+	if s.repositoryPrefix == "" {
+		return fmt.Sprintf("%s/%s", namespace, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.repositoryPrefix, namespace, name)
+}
+
+func (s *ReleaseSource) apiURL(repository string, elem ...string) string {
+	return fmt.Sprintf("%s/v2/%s/%s", s.baseURL, repository, strings.Join(elem, "/"))
+}
+
+func (s *ReleaseSource) RepositoryExists(ctx context.Context, namespace, name string) (exists bool, err error) {
+	err = xray.Capture(ctx, "oci.repository.exists", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		repository := s.repository(namespace, name)
+		resp, reqErr := s.get(tracedCtx, repository, s.apiURL(repository, "tags", "list")+fmt.Sprintf("?n=%d", tagsPageSize), "")
+		if reqErr != nil {
+			return fmt.Errorf("failed to list tags: %w", reqErr)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			exists = true
+			return nil
+		case http.StatusNotFound:
+			slog.Info("OCI repository does not exist")
+			return nil
+		default:
+			return fmt.Errorf("unexpected status code when listing tags: %d", resp.StatusCode)
+		}
+	})
+
+	return exists, err
+}
+
+func (s *ReleaseSource) FetchTags(ctx context.Context, namespace, name string) (tags []string, err error) {
+	err = xray.Capture(ctx, "oci.tags.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		repository := s.repository(namespace, name)
+		for next := s.apiURL(repository, "tags", "list") + fmt.Sprintf("?n=%d", tagsPageSize); next != ""; {
+			resp, reqErr := s.get(tracedCtx, repository, next, "")
+			if reqErr != nil {
+				return fmt.Errorf("failed to list tags: %w", reqErr)
+			}
+
+			var page tagList
+			decodeErr := decodeJSON(resp, &page)
+			if decodeErr != nil {
+				return fmt.Errorf("failed to list tags: %w", decodeErr)
+			}
+
+			tags = append(tags, page.Tags...)
+			next = s.nextPage(resp)
+		}
+		return nil
+	})
+
+	slog.Info("OCI tags fetched", "count", len(tags))
+	return tags, err
+}
+
+func (s *ReleaseSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) ([]source.Release, error) {
+	return s.FetchReleasesSkipping(ctx, namespace, name, since, nil)
+}
+
+// FetchReleasesSkipping reads the manifest of each tag that known does not return true for. Reading a release takes a
+// request per tag, so skipping the tags of known versions saves most requests when fetching new releases.
+func (s *ReleaseSource) FetchReleasesSkipping(ctx context.Context, namespace, name string, since *time.Time, known func(tag string) bool) (releases []source.Release, err error) {
+	tags, err := s.FetchTags(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = xray.Capture(ctx, "oci.releases.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		repository := s.repository(namespace, name)
+		for _, tag := range tags {
+			// skip tags like "latest" that do not name a version
+			if !versionTagPattern.MatchString(tag) {
+				continue
+			}
+			if known != nil && known(tag) {
+				continue
+			}
+
+			release, releaseErr := s.fetchRelease(tracedCtx, repository, tag)
+			if releaseErr != nil {
+				return releaseErr
+			}
+			if release == nil {
+				continue
+			}
+
+			// releases without a known creation time are always returned, as we cannot tell whether they are new
+			if since != nil && !release.CreatedAt.IsZero() && release.CreatedAt.Before(*since) {
+				continue
+			}
+			releases = append(releases, *release)
+		}
+
+		// the tags are listed in lexical order, so order by creation time to return the newest releases first
+		sort.SliceStable(releases, func(i, j int) bool {
+			return releases[i].CreatedAt.After(releases[j].CreatedAt)
+		})
+		return nil
+	})
+
+	slog.Info("OCI releases fetched", "count", len(releases))
+	return releases, err
+}
+
+func (s *ReleaseSource) FindRelease(ctx context.Context, namespace, name, version string) (release *source.Release, err error) {
+	err = xray.Capture(ctx, "oci.release.find", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+		xray.AddAnnotation(tracedCtx, "versionNumber", version)
+
+		repository := s.repository(namespace, name)
+		for _, tag := range []string{"v" + version, version} {
+			found, findErr := s.fetchRelease(tracedCtx, repository, tag)
+			if findErr != nil {
+				return findErr
+			}
+			if found != nil {
+				release = found
+				return nil
+			}
+		}
+
+		slog.Info("OCI release not found")
+		return nil
+	})
+
+	return release, err
+}
+
+func (s *ReleaseSource) DownloadAsset(ctx context.Context, asset source.Asset) (body io.ReadCloser, err error) {
+	err = xray.Capture(ctx, "oci.asset.download", func(tracedCtx context.Context) error {
+		slog.Info("Downloading asset", "url", asset.DownloadURL)
+
+		resp, reqErr := s.get(tracedCtx, s.repositoryOfBlob(asset.DownloadURL), asset.DownloadURL, "")
+		if reqErr != nil {
+			return fmt.Errorf("error downloading asset: %w", reqErr)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status code when downloading asset: %d", resp.StatusCode)
+		}
+
+		body = resp.Body
+		return nil
+	})
+
+	return body, err
+}
+
+// ModuleDownloadURL returns the URL of the module archive layer of the given tag, resolved like ResolveDownloadURL.
+// As these URLs have no file extension, the archive format is passed to the client in the "archive" query parameter.
+func (s *ReleaseSource) ModuleDownloadURL(ctx context.Context, namespace, name, tag string) (string, error) {
+	release, err := s.fetchRelease(ctx, s.repository(namespace, name), tag)
+	if err != nil {
+		return "", err
+	}
+	if release == nil {
+		return "", fmt.Errorf("no release found for tag %s", tag)
+	}
+
+	for _, format := range []struct{ suffix, archive string }{{".tar.gz", "tar.gz"}, {".tgz", "tar.gz"}, {".zip", "zip"}} {
+		if asset := source.FindAssetBySuffix(release.Assets, format.suffix); asset != nil {
+			downloadURL, resolveErr := s.ResolveDownloadURL(ctx, asset.DownloadURL)
+			if resolveErr != nil {
+				return "", resolveErr
+			}
+			separator := "?"
+			if strings.Contains(downloadURL, "?") {
+				separator = "&"
+			}
+			return fmt.Sprintf("%s%sarchive=%s", downloadURL, separator, format.archive), nil
+		}
+	}
+	return "", fmt.Errorf("no module archive found for tag %s", tag)
+}
+
+// ResolveDownloadURL resolves a blob URL to the storage URL the registry redirects blob requests to, which clients
+// can download from without credentials. Blob URLs of registries that serve blobs to anonymous requests themselves
+// are returned as is. It fails for registries that serve blobs themselves but require credentials to do so.
+func (s *ReleaseSource) ResolveDownloadURL(ctx context.Context, downloadURL string) (resolved string, err error) {
+	repository := s.repositoryOfBlob(downloadURL)
+	if repository == "" {
+		return downloadURL, nil
+	}
+
+	err = xray.Capture(ctx, "oci.blob.resolve", func(tracedCtx context.Context) error {
+		resp, reqErr := s.getWith(tracedCtx, s.resolveClient, repository, downloadURL, "")
+		if reqErr != nil {
+			return fmt.Errorf("failed to resolve blob: %w", reqErr)
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != "":
+			location, parseErr := resp.Request.URL.Parse(resp.Header.Get("Location"))
+			if parseErr != nil {
+				return fmt.Errorf("invalid blob redirect: %w", parseErr)
+			}
+			resolved = location.String()
+			return nil
+		case resp.StatusCode == http.StatusOK && resp.Request.Header.Get("Authorization") == "":
+			resolved = downloadURL
+			return nil
+		case resp.StatusCode == http.StatusOK:
+			return fmt.Errorf("the registry serves blobs only to authenticated requests and does not redirect them to storage, so clients cannot download %s", downloadURL)
+		default:
+			return fmt.Errorf("unexpected status code when resolving blob: %d", resp.StatusCode)
+		}
+	})
+
+	return resolved, err
+}
+
+// fetchRelease reads the manifest of a tag and turns its titled layers into release assets.
+// It returns nil if the tag does not exist.
+func (s *ReleaseSource) fetchRelease(ctx context.Context, repository, tag string) (*source.Release, error) {
+	resp, err := s.get(ctx, repository, s.apiURL(repository, "manifests", url.PathEscape(tag)), strings.Join([]string{MediaTypeImageManifest, MediaTypeDockerManifest}, ", "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest for %s: %w", tag, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil //nolint:nilnil // This is not an error, it just means there is no such tag.
+	}
+
+	var manifest Manifest
+	if err := decodeJSON(resp, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to get manifest for %s: %w", tag, err)
+	}
+
+	release := &source.Release{
+		TagName:      tag,
+		IsPrerelease: strings.Contains(tag, "-"),
+	}
+	if created, ok := manifest.Annotations[AnnotationCreated]; ok {
+		if createdAt, parseErr := time.Parse(time.RFC3339, created); parseErr == nil {
+			release.CreatedAt = createdAt
+		}
+	}
+
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[AnnotationTitle]
+		if title == "" {
+			continue
+		}
+		release.Assets = append(release.Assets, source.Asset{
+			Name:        title,
+			DownloadURL: s.apiURL(repository, "blobs", layer.Digest),
+		})
+	}
+
+	return release, nil
+}
+
+// repositoryOfBlob extracts the repository name from a blob URL, which is needed to request a token for it.
+func (s *ReleaseSource) repositoryOfBlob(blobURL string) string {
+	path := strings.TrimPrefix(blobURL, s.baseURL.String()+"/v2/")
+	if i := strings.LastIndex(path, "/blobs/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func (s *ReleaseSource) nextPage(resp *http.Response) string {
+	matches := nextLinkPattern.FindStringSubmatch(resp.Header.Get("Link"))
+	if matches == nil {
+		return ""
+	}
+
+	next, err := s.baseURL.Parse(matches[1])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+// get performs a GET request against the registry. If the registry responds with a bearer token challenge,
+// a token for pulling from the repository is obtained from the registry's token service and the request is retried.
+// Credentials are only sent to the configured registry host.
+func (s *ReleaseSource) get(ctx context.Context, repository, rawURL, accept string) (*http.Response, error) {
+	return s.getWith(ctx, s.httpClient, repository, rawURL, accept)
+}
+
+func (s *ReleaseSource) getWith(ctx context.Context, client *http.Client, repository, rawURL, accept string) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	resp, err := s.doGet(ctx, client, rawURL, accept, s.cachedToken(scope))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("unauthorized, and the registry did not ask for a bearer token")
+	}
+
+	token, err := s.fetchToken(ctx, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.doGet(ctx, client, rawURL, accept, token)
+}
+
+func (s *ReleaseSource) doGet(ctx context.Context, client *http.Client, rawURL, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if req.URL.Host == s.baseURL.Host {
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		case strings.Contains(s.credentials, ":"):
+			req.Header.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(s.credentials))))
+		case s.credentials != "":
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.credentials))
+		}
+	}
+
+	return client.Do(req)
+}
+
+func (s *ReleaseSource) cachedToken(scope string) string {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+	return s.tokens[scope]
+}
+
+// fetchToken obtains a token from the token service named in a "Bearer realm=...,service=...,scope=..." challenge.
+func (s *ReleaseSource) fetchToken(ctx context.Context, challenge, scope string) (string, error) {
+	params := make(map[string]string)
+	for _, match := range challengePattern.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid token realm in challenge %q", challenge)
+	}
+
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	if strings.Contains(s.credentials, ":") {
+		username, password, _ := strings.Cut(s.credentials, ":")
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := decodeJSON(resp, &tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+
+	token := tokenResponse.Token
+	if token == "" {
+		token = tokenResponse.AccessToken
+	}
+
+	s.tokensMu.Lock()
+	s.tokens[scope] = token
+	s.tokensMu.Unlock()
+
+	return token, nil
+}
+
+func decodeJSON(resp *http.Response, target interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
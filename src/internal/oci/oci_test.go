@@ -0,0 +1,332 @@
+package oci_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/oci"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+const (
+	testCredentials = "robot:secret"
+	testToken       = "registry-token"
+	tagsPerPage     = 2
+)
+
+// fakeRegistry is a minimal in-process OCI distribution registry that requires a token from its token service.
+type fakeRegistry struct {
+	t         *testing.T
+	server    *httptest.Server
+	manifests map[string]map[string][]byte // repository -> tag -> manifest
+	blobs     map[string][]byte            // digest -> contents
+
+	// redirectBlobs redirects blob requests to a pre-signed storage URL, like registries backed by object storage do.
+	redirectBlobs bool
+	// anonymousBlobs serves blobs to requests without a token.
+	anonymousBlobs bool
+	// manifestRequests counts the requests for the manifest of each tag.
+	manifestRequests map[string]int
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+
+	registry := &fakeRegistry{
+		t:                t,
+		manifests:        make(map[string]map[string][]byte),
+		blobs:            make(map[string][]byte),
+		manifestRequests: make(map[string]int),
+	}
+	registry.server = httptest.NewServer(http.HandlerFunc(registry.serveHTTP))
+	t.Cleanup(registry.server.Close)
+	return registry
+}
+
+// push stores an artifact with one titled layer per file under the given tag.
+func (r *fakeRegistry) push(repository, tag string, created time.Time, files map[string]string) {
+	manifest := oci.Manifest{
+		MediaType:   oci.MediaTypeImageManifest,
+		Annotations: map[string]string{oci.AnnotationCreated: created.Format(time.RFC3339)},
+	}
+	for name, contents := range files {
+		sum := sha256.Sum256([]byte(contents))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		r.blobs[digest] = []byte(contents)
+		manifest.Layers = append(manifest.Layers, oci.Descriptor{
+			MediaType:   "application/octet-stream",
+			Digest:      digest,
+			Size:        int64(len(contents)),
+			Annotations: map[string]string{oci.AnnotationTitle: name},
+		})
+	}
+
+	contents, err := json.Marshal(manifest)
+	if err != nil {
+		r.t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	if r.manifests[repository] == nil {
+		r.manifests[repository] = make(map[string][]byte)
+	}
+	r.manifests[repository][tag] = contents
+}
+
+func (r *fakeRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || fmt.Sprintf("%s:%s", username, password) != testCredentials || req.URL.Query().Get("service") != "fake-registry" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token": %q}`, testToken)
+		return
+	}
+
+	if strings.HasPrefix(req.URL.Path, "/storage/") {
+		// pre-signed storage URLs need no token, but are only valid with their signature
+		blob, ok := r.blobs[strings.TrimPrefix(req.URL.Path, "/storage/")]
+		if !ok || req.URL.Query().Get("signature") != "valid" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(blob)
+		return
+	}
+
+	anonymous := r.anonymousBlobs && strings.Contains(req.URL.Path, "/blobs/")
+	if req.Header.Get("Authorization") != "Bearer "+testToken && !anonymous {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake-registry"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		r.serveTags(w, req, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		r.manifestRequests[path[i+len("/manifests/"):]]++
+		manifest, ok := r.manifests[path[:i]][path[i+len("/manifests/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", oci.MediaTypeImageManifest)
+		_, _ = w.Write(manifest)
+	case strings.Contains(path, "/blobs/"):
+		digest := path[strings.LastIndex(path, "/blobs/")+len("/blobs/"):]
+		blob, ok := r.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.redirectBlobs {
+			http.Redirect(w, req, fmt.Sprintf("/storage/%s?signature=valid", digest), http.StatusTemporaryRedirect)
+			return
+		}
+		_, _ = w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveTags lists tags in lexical order, paginated with a Link header like the distribution spec describes.
+func (r *fakeRegistry) serveTags(w http.ResponseWriter, req *http.Request, repository string) {
+	manifests, ok := r.manifests[repository]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var tags []string
+	for tag := range manifests {
+		if tag > req.URL.Query().Get("last") {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	if len(tags) > tagsPerPage {
+		tags = tags[:tagsPerPage]
+		w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=%d&last=%s>; rel="next"`, repository, tagsPerPage, tags[len(tags)-1]))
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+}
+
+func providerFiles(version string) map[string]string {
+	prefix := "terraform-provider-dummy_" + version
+	return map[string]string{
+		prefix + "_linux_amd64.zip":  "linux binary",
+		prefix + "_darwin_arm64.zip": "darwin binary",
+		prefix + "_SHA256SUMS":       fmt.Sprintf("abcd  %s_linux_amd64.zip\nef01  %s_darwin_arm64.zip\n", prefix, prefix),
+		prefix + "_SHA256SUMS.sig":   "signature",
+	}
+}
+
+func newTestSource(t *testing.T) (*oci.ReleaseSource, *fakeRegistry) {
+	t.Helper()
+
+	registry := newFakeRegistry(t)
+	registry.push("terraform/internal/terraform-provider-dummy", "v1.0.0", time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC), providerFiles("1.0.0"))
+	registry.push("terraform/internal/terraform-provider-dummy", "v1.1.0", time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), providerFiles("1.1.0"))
+	registry.push("terraform/internal/terraform-provider-dummy", "latest", time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), providerFiles("1.1.0"))
+	registry.push("terraform/internal/terraform-aws-network", "2.0.0", time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), map[string]string{"terraform-aws-network.tar.gz": "archive"})
+
+	src, err := oci.NewReleaseSource(registry.server.URL, testCredentials, "terraform")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return src, registry
+}
+
+func TestRepositoryExists(t *testing.T) {
+	src, _ := newTestSource(t)
+
+	exists, err := src.RepositoryExists(context.Background(), "internal", "terraform-provider-dummy")
+	if err != nil || !exists {
+		t.Fatalf("expected repository to exist, got %v (error: %v)", exists, err)
+	}
+
+	exists, err = src.RepositoryExists(context.Background(), "internal", "terraform-provider-missing")
+	if err != nil || exists {
+		t.Fatalf("expected repository to not exist, got %v (error: %v)", exists, err)
+	}
+}
+
+func TestProviderVersions(t *testing.T) {
+	src, registry := newTestSource(t)
+
+	versions, _, err := providers.GetVersions(context.Background(), src, "internal", "terraform-provider-dummy", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions across both pages of tags, got %v", versions)
+	}
+
+	for _, v := range versions {
+		details := v.GetVersionDetails("linux", "amd64")
+		if details == nil || details.SHASum != "abcd" {
+			t.Fatalf("unexpected linux_amd64 download details for %s: %v", v.Version, details)
+		}
+		if !strings.HasPrefix(details.DownloadURL, registry.server.URL+"/v2/terraform/internal/terraform-provider-dummy/blobs/sha256:") {
+			t.Errorf("expected a blob URL, got %s", details.DownloadURL)
+		}
+		if !v.IsSigned() {
+			t.Errorf("expected version %s to be signed", v.Version)
+		}
+	}
+
+	since := time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC)
+	versions, _, err = providers.GetVersions(context.Background(), src, "internal", "terraform-provider-dummy", &since)
+	if err != nil || len(versions) != 1 || versions[0].Version != "1.1.0" {
+		t.Fatalf("expected only 1.1.0 since %s, got %v (error: %v)", since, versions, err)
+	}
+
+	registry.manifestRequests = make(map[string]int)
+	versions, _, err = providers.GetNewVersions(context.Background(), src, "internal", "terraform-provider-dummy", &since, types.VersionList{{Version: "1.0.0"}})
+	if err != nil || len(versions) != 1 || versions[0].Version != "1.1.0" {
+		t.Fatalf("expected only 1.1.0 besides the known version, got %v (error: %v)", versions, err)
+	}
+	if registry.manifestRequests["v1.0.0"] != 0 || registry.manifestRequests["v1.1.0"] != 1 {
+		t.Errorf("expected only the manifest of the new version to be read, got %v", registry.manifestRequests)
+	}
+
+	details, err := providers.GetVersion(context.Background(), src, "internal", "terraform-provider-dummy", "1.0.0", "darwin", "arm64", providers.UnsignedReleasePolicyRequire)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.SHASum != "ef01" {
+		t.Errorf("expected shasum ef01, got %s", details.SHASum)
+	}
+}
+
+func TestModuleVersions(t *testing.T) {
+	src, registry := newTestSource(t)
+	registry.redirectBlobs = true
+
+	versions, _, err := modules.GetVersions(context.Background(), src, "internal", "terraform-aws-network", nil)
+	if err != nil || len(versions) != 1 || versions[0].Version != "2.0.0" {
+		t.Fatalf("unexpected versions %v (error: %v)", versions, err)
+	}
+
+	tag, err := modules.ResolveTag(context.Background(), src, "internal", "terraform-aws-network", "2.0.0")
+	if err != nil || tag != "2.0.0" {
+		t.Fatalf("expected tag 2.0.0, got %q (error: %v)", tag, err)
+	}
+
+	downloadURL, err := src.ModuleDownloadURL(context.Background(), "internal", "terraform-aws-network", tag)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("archive"))
+	expected := fmt.Sprintf("%s/storage/sha256:%s?signature=valid&archive=tar.gz", registry.server.URL, hex.EncodeToString(sum[:]))
+	if downloadURL != expected {
+		t.Errorf("expected %s, got %s", expected, downloadURL)
+	}
+}
+
+func TestResolveDownloadURL(t *testing.T) {
+	src, registry := newTestSource(t)
+
+	release, err := src.FindRelease(context.Background(), "internal", "terraform-provider-dummy", "1.0.0")
+	if err != nil || release == nil {
+		t.Fatalf("expected a release, got %v (error: %v)", release, err)
+	}
+	blobURL := release.Assets[0].DownloadURL
+
+	// clients cannot follow the token challenge of blob URLs
+	resp, err := http.Get(blobURL)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the fake registry to challenge anonymous blob requests, got %d", resp.StatusCode)
+	}
+
+	registry.redirectBlobs = true
+	resolved, err := src.ResolveDownloadURL(context.Background(), blobURL)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp, err = http.Get(resolved)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if !strings.HasPrefix(resolved, registry.server.URL+"/storage/") || resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a storage URL clients can download from, got %s (status %d)", resolved, resp.StatusCode)
+	}
+
+	registry.redirectBlobs = false
+	if _, err := src.ResolveDownloadURL(context.Background(), blobURL); err == nil {
+		t.Error("expected an error for a registry that only serves blobs to authenticated requests")
+	}
+
+	anonymousSrc, err := oci.NewReleaseSource(registry.server.URL, "", "terraform")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	registry.anonymousBlobs = true
+	if resolved, err := anonymousSrc.ResolveDownloadURL(context.Background(), blobURL); err != nil || resolved != blobURL {
+		t.Errorf("expected anonymous blob URLs to be returned as is, got %s (error: %v)", resolved, err)
+	}
+
+	if resolved, err := src.ResolveDownloadURL(context.Background(), "https://example.com/file.zip"); err != nil || resolved != "https://example.com/file.zip" {
+		t.Errorf("expected URLs of other hosts to be returned as is, got %s (error: %v)", resolved, err)
+	}
+}
@@ -10,6 +10,7 @@ const (
 	ErrCodeSHASumsNotFound       FetchErrorCode = 3
 	ErrCodeManifestNotFound      FetchErrorCode = 4
 	ErrCodeCouldNotGetPublicKeys FetchErrorCode = 5
+	ErrCodeSignatureNotFound     FetchErrorCode = 6
 )
 
 type FetchError struct {
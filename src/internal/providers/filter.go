@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/semver"
+)
+
+// VersionFilter restricts the versions returned by the versions listing. The zero value keeps every version.
+type VersionFilter struct {
+	// ProtocolMajor keeps versions supporting a protocol with this major version, if not zero.
+	ProtocolMajor int
+	// OS and Arch keep versions built for the given operating system and/or architecture, if set.
+	OS   string
+	Arch string
+	// Since keeps versions published after the given time, if set. Versions with an unknown publication time are removed.
+	Since *time.Time
+	// ExcludePrereleases removes prerelease versions.
+	ExcludePrereleases bool
+}
+
+// ParseProtocolMajor parses a protocol version filter such as "5" or "5.0" into its major version.
+func ParseProtocolMajor(protocol string) (int, error) {
+	major, _, _ := strings.Cut(protocol, ".")
+	majorVersion, err := strconv.Atoi(major)
+	if err != nil || majorVersion < 1 {
+		return 0, fmt.Errorf("invalid protocol version %q", protocol)
+	}
+	return majorVersion, nil
+}
+
+// Apply returns the versions matching the filter, in their original order.
+func (f VersionFilter) Apply(versions types.VersionList) types.VersionList {
+	filtered := make(types.VersionList, 0, len(versions))
+	for _, v := range versions {
+		if f.matches(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func (f VersionFilter) matches(v types.CacheVersion) bool {
+	if f.ProtocolMajor != 0 && !supportsProtocol(v, f.ProtocolMajor) {
+		return false
+	}
+
+	if (f.OS != "" || f.Arch != "") && !hasPlatform(v, f.OS, f.Arch) {
+		return false
+	}
+
+	if f.Since != nil && (v.PublishedAt.IsZero() || !v.PublishedAt.After(*f.Since)) {
+		return false
+	}
+
+	if f.ExcludePrereleases && (v.IsPrerelease || semver.IsPrerelease(v.Version)) {
+		return false
+	}
+
+	return true
+}
+
+func supportsProtocol(v types.CacheVersion, major int) bool {
+	for _, protocol := range v.Protocols {
+		if protocolMajor, err := ParseProtocolMajor(protocol); err == nil && protocolMajor == major {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPlatform(v types.CacheVersion, os, arch string) bool {
+	for _, d := range v.DownloadDetails {
+		if (os == "" || d.Platform.OS == os) && (arch == "" || d.Platform.Arch == arch) {
+			return true
+		}
+	}
+	return false
+}
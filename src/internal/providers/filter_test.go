@@ -0,0 +1,88 @@
+package providers_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/platform"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+func TestVersionFilter(t *testing.T) {
+	linux := []types.CacheVersionDownloadDetails{{Platform: platform.Platform{OS: "linux", Arch: "amd64"}}}
+	darwin := []types.CacheVersionDownloadDetails{{Platform: platform.Platform{OS: "darwin", Arch: "arm64"}}}
+
+	versions := types.VersionList{
+		{Version: "2.0.0-beta1", Protocols: []string{"6.0"}, DownloadDetails: linux, PublishedAt: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{Version: "1.1.0", Protocols: []string{"5.0"}, DownloadDetails: darwin, PublishedAt: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{Version: "1.0.0", Protocols: []string{"5.0"}, DownloadDetails: linux},
+	}
+
+	since := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   providers.VersionFilter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			filter:   providers.VersionFilter{},
+			expected: []string{"2.0.0-beta1", "1.1.0", "1.0.0"},
+		},
+		{
+			name:     "protocol",
+			filter:   providers.VersionFilter{ProtocolMajor: 5},
+			expected: []string{"1.1.0", "1.0.0"},
+		},
+		{
+			name:     "platform",
+			filter:   providers.VersionFilter{OS: "linux", Arch: "amd64"},
+			expected: []string{"2.0.0-beta1", "1.0.0"},
+		},
+		{
+			name:     "architecture only",
+			filter:   providers.VersionFilter{Arch: "arm64"},
+			expected: []string{"1.1.0"},
+		},
+		{
+			name:     "since excludes versions without a publication time",
+			filter:   providers.VersionFilter{Since: &since},
+			expected: []string{"2.0.0-beta1", "1.1.0"},
+		},
+		{
+			name:     "exclude prereleases",
+			filter:   providers.VersionFilter{ExcludePrereleases: true},
+			expected: []string{"1.1.0", "1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, v := range tt.filter.Apply(versions) {
+				got = append(got, v.Version)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseProtocolMajor(t *testing.T) {
+	for input, expected := range map[string]int{"5": 5, "6.0": 6, "5.2": 5} {
+		got, err := providers.ParseProtocolMajor(input)
+		if err != nil || got != expected {
+			t.Errorf("ParseProtocolMajor(%q) = %d, %v, want %d", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "x", "0", "-1.0"} {
+		if _, err := providers.ParseProtocolMajor(input); err == nil {
+			t.Errorf("expected an error parsing %q", input)
+		}
+	}
+}
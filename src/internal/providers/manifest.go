@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"io"
 
-	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/source"
 	"golang.org/x/exp/slog"
 )
 
@@ -17,19 +17,19 @@ type ManifestMetadata struct {
 	ProtocolVersions []string `json:"protocol_versions"`
 }
 
-func findAndParseManifest(ctx context.Context, assets []github.ReleaseAsset) (*Manifest, error) {
-	manifestAsset := github.FindAssetBySuffix(assets, "_manifest.json")
+func findAndParseManifest(ctx context.Context, src source.ReleaseSource, assets []source.Asset) (*Manifest, error) {
+	manifestAsset := source.FindAssetBySuffix(assets, "_manifest.json")
 	if manifestAsset == nil {
 		slog.Warn("No manifest found in release assets")
 		return nil, nil //nolint:nilnil // This is not an error, it just means there is no manifest.
 	}
 
-	assetContents, err := github.DownloadAssetContents(ctx, manifestAsset.DownloadURL)
+	assetContents, err := src.DownloadAsset(ctx, *manifestAsset)
 	if err != nil {
 		return nil, err
 	}
 
-	manifest, err := parseManifestContents(assetContents)
+	manifest, err := ParseManifest(assetContents)
 	assetContents.Close()
 	if err != nil {
 		return nil, err
@@ -40,7 +40,8 @@ func findAndParseManifest(ctx context.Context, assets []github.ReleaseAsset) (*M
 	return manifest, nil
 }
 
-func parseManifestContents(assetContents io.ReadCloser) (*Manifest, error) {
+// ParseManifest parses the contents of a provider's "<name>_<version>_manifest.json" file.
+func ParseManifest(assetContents io.Reader) (*Manifest, error) {
 	contents, err := io.ReadAll(assetContents)
 	if err != nil {
 		slog.Error("Failed to read manifest contents")
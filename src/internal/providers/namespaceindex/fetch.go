@@ -0,0 +1,105 @@
+package namespaceindex
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/opentofu/registry/internal/providers/types"
+	"golang.org/x/exp/slog"
+)
+
+// GetItem returns the index of a namespace, or nil if the namespace has not been crawled.
+func (h *Handler) GetItem(ctx context.Context, namespace string) (*types.NamespaceIndex, error) {
+	slog.Info("Getting namespace index", "namespace", namespace)
+
+	paginator := dynamodb.NewQueryPaginator(h.Client, &dynamodb.QueryInput{
+		TableName:              h.TableName,
+		KeyConditionExpression: aws.String("#namespace = :namespace"),
+		ExpressionAttributeNames: map[string]string{
+			"#namespace": "namespace",
+		},
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":namespace": &ddbTypes.AttributeValueMemberS{Value: namespace},
+		},
+	})
+
+	var items []item
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			slog.Error("Failed to query namespace index", "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		var pageItems []item
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			slog.Error("Failed to unmarshal namespace index", "namespace", namespace, "error", err)
+			return nil, err
+		}
+		items = append(items, pageItems...)
+	}
+
+	if len(items) == 0 {
+		slog.Info("Namespace index not found", "namespace", namespace)
+		return nil, nil //nolint:nilnil // This is not an error, it just means the namespace has not been crawled.
+	}
+
+	return &groupByNamespace(items)[0], nil
+}
+
+// List returns the indexes of all crawled namespaces.
+func (h *Handler) List(ctx context.Context) ([]types.NamespaceIndex, error) {
+	paginator := dynamodb.NewScanPaginator(h.Client, &dynamodb.ScanInput{
+		TableName: h.TableName,
+	})
+
+	var items []item
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			slog.Error("Failed to scan namespace indexes", "error", err)
+			return nil, err
+		}
+
+		var pageItems []item
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			slog.Error("Failed to unmarshal namespace indexes", "error", err)
+			return nil, err
+		}
+		items = append(items, pageItems...)
+	}
+
+	indexes := groupByNamespace(items)
+	slog.Info("Listed namespace indexes", "namespaces", len(indexes))
+	return indexes, nil
+}
+
+// groupByNamespace collects the stored providers into the index of each namespace, ordered by namespace. An index was
+// last updated when its most recently crawled provider was.
+func groupByNamespace(items []item) []types.NamespaceIndex {
+	var indexes []types.NamespaceIndex
+	positions := make(map[string]int)
+	for _, it := range items {
+		position, ok := positions[it.Namespace]
+		if !ok {
+			position = len(indexes)
+			positions[it.Namespace] = position
+			indexes = append(indexes, types.NamespaceIndex{Namespace: it.Namespace})
+		}
+
+		index := &indexes[position]
+		index.Providers = append(index.Providers, it.IndexedProvider)
+		if it.LastUpdated.After(index.LastUpdated) {
+			index.LastUpdated = it.LastUpdated
+		}
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].Namespace < indexes[j].Namespace
+	})
+	return indexes
+}
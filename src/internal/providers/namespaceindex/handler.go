@@ -0,0 +1,42 @@
+// Package namespaceindex stores the providers found in each namespace by the namespace crawler.
+//
+// Each provider is stored as its own item, keyed by its namespace and type, so that the index of a namespace is not
+// bound by the size limit of a single item and is read with a query on the namespace.
+package namespaceindex
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+type Handler struct {
+	TableName *string
+	Client    *dynamodb.Client
+}
+
+func NewHandler(awsConfig aws.Config, tableName string) *Handler {
+	ddbClient := dynamodb.NewFromConfig(awsConfig)
+
+	return &Handler{
+		TableName: aws.String(tableName),
+		Client:    ddbClient,
+	}
+}
+
+// item is a provider of a namespace as it is stored in the table.
+type item struct {
+	Namespace string `dynamodbav:"namespace"`
+	types.IndexedProvider
+	LastUpdated time.Time `dynamodbav:"last_updated"`
+}
+
+func itemKey(namespace, providerType string) map[string]ddbTypes.AttributeValue {
+	return map[string]ddbTypes.AttributeValue{
+		"namespace": &ddbTypes.AttributeValueMemberS{Value: namespace},
+		"type":      &ddbTypes.AttributeValueMemberS{Value: providerType},
+	}
+}
@@ -0,0 +1,92 @@
+package namespaceindex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/opentofu/registry/internal/providers/types"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// maxBatchWriteRequests is the maximum number of requests DynamoDB accepts in a single BatchWriteItem request.
+	maxBatchWriteRequests = 25
+	// maxBatchWriteAttempts bounds how often unprocessed requests are retried, for example when the table is throttled.
+	maxBatchWriteAttempts = 5
+)
+
+// Store replaces the index of a namespace. The providers of the index are stored, and the providers that were
+// previously stored for the namespace but are no longer in it are deleted.
+func (h *Handler) Store(ctx context.Context, index types.NamespaceIndex) error {
+	previous, err := h.GetItem(ctx, index.Namespace)
+	if err != nil {
+		return fmt.Errorf("got error reading previous namespace index: %w", err)
+	}
+
+	requests := make([]ddbTypes.WriteRequest, 0, len(index.Providers))
+	for _, provider := range index.Providers {
+		marshalledItem, err := attributevalue.MarshalMap(item{
+			Namespace:       index.Namespace,
+			IndexedProvider: provider,
+			LastUpdated:     index.LastUpdated,
+		})
+		if err != nil {
+			slog.Error("got error marshalling namespace index", "error", err)
+			return fmt.Errorf("got error marshalling namespace index: %w", err)
+		}
+		requests = append(requests, ddbTypes.WriteRequest{PutRequest: &ddbTypes.PutRequest{Item: marshalledItem}})
+	}
+
+	if previous != nil {
+		for _, provider := range previous.Providers {
+			if index.Find(provider.Type) == nil {
+				requests = append(requests, ddbTypes.WriteRequest{DeleteRequest: &ddbTypes.DeleteRequest{Key: itemKey(index.Namespace, provider.Type)}})
+			}
+		}
+	}
+
+	slog.Info("Storing namespace index", "namespace", index.Namespace, "providers", len(index.Providers), "requests", len(requests))
+	for start := 0; start < len(requests); start += maxBatchWriteRequests {
+		end := start + maxBatchWriteRequests
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := h.batchWrite(ctx, requests[start:end]); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Successfully stored namespace index", "namespace", index.Namespace)
+	return nil
+}
+
+// batchWrite performs the given write requests, retrying the requests left unprocessed.
+func (h *Handler) batchWrite(ctx context.Context, requests []ddbTypes.WriteRequest) error {
+	requestItems := map[string][]ddbTypes.WriteRequest{*h.TableName: requests}
+	for attempt := 1; len(requestItems) > 0; attempt++ {
+		if attempt > maxBatchWriteAttempts {
+			return fmt.Errorf("failed to store namespace index after %d attempts", maxBatchWriteAttempts)
+		}
+
+		result, err := h.Client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requestItems})
+		if err != nil {
+			slog.Error("got error calling BatchWriteItem", "error", err)
+			return fmt.Errorf("got error calling BatchWriteItem: %w", err)
+		}
+
+		requestItems = result.UnprocessedItems
+		if len(requestItems) > 0 {
+			// back off before retrying, as requests are usually left unprocessed when the table is throttled
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			}
+		}
+	}
+	return nil
+}
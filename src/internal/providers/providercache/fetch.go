@@ -6,7 +6,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -51,29 +53,111 @@ func (p *Handler) GetItem(ctx context.Context, key string) (*providerTypes.Cache
 		return nil, nil //nolint:nilnil // This is not an error, it just means there is no manifest.
 	}
 
-	var compressedItem CompressedCacheItem
-	err = attributevalue.UnmarshalMap(result.Item, &compressedItem)
+	item, err := decodeItem(result.Item)
 	if err != nil {
-		slog.Error("Failed to unmarshal compressed item from cache", "key", key, "error", err)
+		slog.Error("Failed to decode item from cache", "key", key, "error", err)
 		return nil, err
 	}
 
-	decompressedData, err := decompress(compressedItem.Data)
-	if err != nil {
-		slog.Error("Failed to decompress item data", "key", key, "error", err)
-		return nil, err
+	slog.Info("Successfully decompressed and unmarshalled item from cache", "key", key)
+	return item, nil
+}
+
+// maxBatchGetKeys is the maximum number of keys DynamoDB accepts in a single BatchGetItem request.
+const maxBatchGetKeys = 100
+
+// maxBatchGetAttempts bounds how often unprocessed keys are retried, for example when the table is throttled.
+const maxBatchGetAttempts = 5
+
+// BatchGetItems returns the cached items of the given keys, keyed by provider. Keys that are not cached are missing from the result.
+// Items that cannot be decoded are also left out, so that they are treated as not cached.
+func (p *Handler) BatchGetItems(ctx context.Context, keys []string) (map[string]*providerTypes.CacheItem, error) {
+	slog.Info("Getting items from cache", "keys", len(keys))
+
+	items := make(map[string]*providerTypes.CacheItem, len(keys))
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := start + maxBatchGetKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		requestKeys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, key := range keys[start:end] {
+			requestKeys = append(requestKeys, map[string]types.AttributeValue{
+				"provider": &types.AttributeValueMemberS{Value: key},
+			})
+		}
+
+		requestItems := map[string]types.KeysAndAttributes{*p.TableName: {Keys: requestKeys}}
+		for attempt := 1; len(requestItems) > 0; attempt++ {
+			if attempt > maxBatchGetAttempts {
+				return nil, fmt.Errorf("failed to get all items from cache after %d attempts", maxBatchGetAttempts)
+			}
+
+			result, err := p.Client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+			if err != nil {
+				slog.Error("Failed to get items from cache", "error", err)
+				return nil, err
+			}
+
+			for _, rawItem := range result.Responses[*p.TableName] {
+				item, err := decodeItem(rawItem)
+				if err != nil {
+					slog.Error("Failed to decode item from cache, skipping", "error", err)
+					continue
+				}
+				items[item.Provider] = item
+			}
+
+			requestItems = result.UnprocessedKeys
+			if len(requestItems) > 0 {
+				// back off before retrying, as keys are usually left unprocessed when the table is throttled
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+				}
+			}
+		}
 	}
 
-	var item providerTypes.CacheItem
-	err = json.Unmarshal(decompressedData, &item.Versions)
+	slog.Info("Successfully got items from cache", "keys", len(keys), "found", len(items))
+	return items, nil
+}
+
+// decodeItem unmarshals and decompresses a raw cache item.
+func decodeItem(rawItem map[string]types.AttributeValue) (*providerTypes.CacheItem, error) {
+	var compressedItem CompressedCacheItem
+	if err := attributevalue.UnmarshalMap(rawItem, &compressedItem); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compressed item: %w", err)
+	}
+
+	item := providerTypes.CacheItem{
+		Provider:    compressedItem.Provider,
+		LastUpdated: compressedItem.LastUpdated,
+		Description: compressedItem.Description,
+		SourceURL:   compressedItem.SourceURL,
+		Archived:    compressedItem.Archived,
+		Disabled:    compressedItem.Disabled,
+		MovedTo:     compressedItem.MovedTo,
+
+		IngestionWarnings: compressedItem.IngestionWarnings,
+		SchemaVersion:     compressedItem.SchemaVersion,
+	}
+
+	// items that only hold repository metadata do not have any versions yet
+	if compressedItem.Data == "" {
+		return &item, nil
+	}
+
+	decompressedData, err := decompress(compressedItem.Data)
 	if err != nil {
-		slog.Error("Failed to unmarshal decompressed item to CacheItem", "key", key, "error", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to decompress item data: %w", err)
 	}
 
-	item.Provider = compressedItem.Provider
-	item.LastUpdated = compressedItem.LastUpdated
+	if err := json.Unmarshal(decompressedData, &item.Versions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decompressed item data: %w", err)
+	}
 
-	slog.Info("Successfully decompressed and unmarshalled item from cache", "key", key)
 	return &item, nil
 }
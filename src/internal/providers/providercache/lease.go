@@ -0,0 +1,217 @@
+package providercache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrLeaseHeld is returned when another worker holds the lease of a provider.
+	ErrLeaseHeld = errors.New("the lease is held by another worker")
+	// ErrLeaseNotHeld is returned when a rerun is requested of a provider whose lease is not held by any worker.
+	ErrLeaseNotHeld = errors.New("the lease is not held by any worker")
+)
+
+// UpdateItemAPI is the part of the DynamoDB client used to update leases.
+type UpdateItemAPI interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// Leases makes sure a single worker populates the versions of a provider at a time. The leases are stored in their
+// own table, keyed by provider, whose time to live attribute is lease_expires_at. Leases expire on their own, so that
+// the lease of a worker that crashed before releasing it is taken over once it expires.
+type Leases struct {
+	TableName *string
+	Client    UpdateItemAPI
+}
+
+func NewLeases(awsConfig aws.Config, tableName string) *Leases {
+	return &Leases{
+		TableName: aws.String(tableName),
+		Client:    dynamodb.NewFromConfig(awsConfig),
+	}
+}
+
+// Acquire takes the lease of a provider until expiresAt. It returns ErrLeaseHeld if another owner holds a lease that
+// has not expired yet.
+func (l *Leases) Acquire(ctx context.Context, key, owner string, expiresAt time.Time) error {
+	_, err := l.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           l.TableName,
+		Key:                 leaseKey(key),
+		UpdateExpression:    aws.String("SET lease_owner = :owner, lease_expires_at = :expires_at REMOVE rerun_requested"),
+		ConditionExpression: aws.String("attribute_not_exists(lease_owner) OR lease_expires_at <= :now OR lease_owner = :owner"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":owner":      &ddbTypes.AttributeValueMemberS{Value: owner},
+			":expires_at": unixTime(expiresAt),
+			":now":        unixTime(time.Now()),
+		},
+	})
+
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return ErrLeaseHeld
+	}
+	if err != nil {
+		slog.Error("got error acquiring lease", "key", key, "error", err)
+		return fmt.Errorf("got error acquiring lease: %w", err)
+	}
+
+	slog.Info("Acquired lease", "key", key, "owner", owner, "expires_at", expiresAt)
+	return nil
+}
+
+// RequestRerun asks the owner of the lease of a provider to populate its versions again before releasing the lease,
+// such as when a new release is reported while they are being populated. It returns ErrLeaseNotHeld if no unexpired
+// lease is held, in which case the lease can be acquired instead.
+func (l *Leases) RequestRerun(ctx context.Context, key string) error {
+	_, err := l.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           l.TableName,
+		Key:                 leaseKey(key),
+		UpdateExpression:    aws.String("SET rerun_requested = :true"),
+		ConditionExpression: aws.String("attribute_exists(lease_owner) AND lease_expires_at > :now"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":true": &ddbTypes.AttributeValueMemberBOOL{Value: true},
+			":now":  unixTime(time.Now()),
+		},
+	})
+
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return ErrLeaseNotHeld
+	}
+	if err != nil {
+		slog.Error("got error requesting rerun", "key", key, "error", err)
+		return fmt.Errorf("got error requesting rerun: %w", err)
+	}
+
+	slog.Info("Requested rerun from the lease owner", "key", key)
+	return nil
+}
+
+// maxReleaseAttempts bounds how often releasing a lease is retried when reruns are requested concurrently.
+const maxReleaseAttempts = 3
+
+// Release gives up the lease of a provider held by owner, unless a rerun was requested while it was held. In that
+// case the lease is kept until expiresAt and true is returned, and the owner must populate the versions again before
+// releasing it. A lease that expired and was taken over by another owner in the meantime is left alone.
+func (l *Leases) Release(ctx context.Context, key, owner string, expiresAt time.Time) (rerun bool, err error) {
+	for attempt := 0; attempt < maxReleaseAttempts; attempt++ {
+		rerun, err = l.claimRerun(ctx, key, owner, expiresAt)
+		if err != nil || rerun {
+			return rerun, err
+		}
+
+		released, err := l.release(ctx, key, owner)
+		if err != nil || released {
+			return false, err
+		}
+		// either a rerun was requested since it was checked, or the lease was taken over
+	}
+
+	slog.Info("Lease was taken over by another worker", "key", key, "owner", owner)
+	return false, nil
+}
+
+// Abandon gives up the lease of a provider held by owner, along with any rerun requested, such as when populating
+// the versions failed and will be retried. A lease taken over by another owner is left alone.
+func (l *Leases) Abandon(ctx context.Context, key, owner string) error {
+	_, err := l.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           l.TableName,
+		Key:                 leaseKey(key),
+		UpdateExpression:    aws.String("SET lease_expires_at = :now REMOVE lease_owner, rerun_requested"),
+		ConditionExpression: aws.String("lease_owner = :owner"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":owner": &ddbTypes.AttributeValueMemberS{Value: owner},
+			":now":   unixTime(time.Now()),
+		},
+	})
+
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		slog.Info("Lease was taken over by another worker", "key", key, "owner", owner)
+		return nil
+	}
+	if err != nil {
+		slog.Error("got error abandoning lease", "key", key, "error", err)
+		return fmt.Errorf("got error abandoning lease: %w", err)
+	}
+
+	slog.Info("Abandoned lease", "key", key, "owner", owner)
+	return nil
+}
+
+// claimRerun clears the rerun requested of a lease held by owner and extends the lease, returning false if no rerun
+// was requested.
+func (l *Leases) claimRerun(ctx context.Context, key, owner string, expiresAt time.Time) (bool, error) {
+	_, err := l.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           l.TableName,
+		Key:                 leaseKey(key),
+		UpdateExpression:    aws.String("SET lease_expires_at = :expires_at REMOVE rerun_requested"),
+		ConditionExpression: aws.String("lease_owner = :owner AND rerun_requested = :true"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":owner":      &ddbTypes.AttributeValueMemberS{Value: owner},
+			":expires_at": unixTime(expiresAt),
+			":true":       &ddbTypes.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return false, nil
+	}
+	if err != nil {
+		slog.Error("got error checking for rerun requests", "key", key, "error", err)
+		return false, fmt.Errorf("got error checking for rerun requests: %w", err)
+	}
+
+	slog.Info("Rerun was requested while the lease was held", "key", key, "owner", owner)
+	return true, nil
+}
+
+// release removes the owner of a lease held by owner without a rerun requested, returning false otherwise. The lease
+// is left expired, so that it is deleted by the table's time to live.
+func (l *Leases) release(ctx context.Context, key, owner string) (bool, error) {
+	now := time.Now()
+	_, err := l.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           l.TableName,
+		Key:                 leaseKey(key),
+		UpdateExpression:    aws.String("SET lease_expires_at = :now REMOVE lease_owner"),
+		ConditionExpression: aws.String("lease_owner = :owner AND attribute_not_exists(rerun_requested)"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":owner": &ddbTypes.AttributeValueMemberS{Value: owner},
+			":now":   unixTime(now),
+		},
+	})
+
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return false, nil
+	}
+	if err != nil {
+		slog.Error("got error releasing lease", "key", key, "error", err)
+		return false, fmt.Errorf("got error releasing lease: %w", err)
+	}
+
+	slog.Info("Released lease", "key", key, "owner", owner)
+	return true, nil
+}
+
+func leaseKey(key string) map[string]ddbTypes.AttributeValue {
+	return map[string]ddbTypes.AttributeValue{
+		"provider": &ddbTypes.AttributeValueMemberS{Value: key},
+	}
+}
+
+// unixTime stores times as numbers of seconds, so that they can be compared in condition expressions and used as
+// the time to live of the leases.
+func unixTime(t time.Time) ddbTypes.AttributeValue {
+	return &ddbTypes.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
@@ -0,0 +1,227 @@
+package providercache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type fakeLease struct {
+	owner          string
+	expiresAt      int64
+	rerunRequested bool
+}
+
+// fakeLeaseTable evaluates the update and condition expressions used by Leases.
+type fakeLeaseTable struct {
+	leases map[string]*fakeLease
+	// beforeUpdate, if set, is called with the update expression before each update is evaluated.
+	beforeUpdate func(expression string)
+}
+
+func (f *fakeLeaseTable) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	expression := aws.ToString(params.UpdateExpression)
+	if f.beforeUpdate != nil {
+		f.beforeUpdate(expression)
+	}
+
+	key := params.Key["provider"].(*ddbTypes.AttributeValueMemberS).Value
+	lease, ok := f.leases[key]
+	if !ok {
+		lease = &fakeLease{}
+	}
+	values := params.ExpressionAttributeValues
+
+	var conditionMet bool
+	switch aws.ToString(params.ConditionExpression) {
+	case "attribute_not_exists(lease_owner) OR lease_expires_at <= :now OR lease_owner = :owner":
+		conditionMet = lease.owner == "" || lease.expiresAt <= number(values[":now"]) || lease.owner == str(values[":owner"])
+	case "attribute_exists(lease_owner) AND lease_expires_at > :now":
+		conditionMet = lease.owner != "" && lease.expiresAt > number(values[":now"])
+	case "lease_owner = :owner AND rerun_requested = :true":
+		conditionMet = lease.owner == str(values[":owner"]) && lease.rerunRequested
+	case "lease_owner = :owner":
+		conditionMet = lease.owner == str(values[":owner"])
+	case "lease_owner = :owner AND attribute_not_exists(rerun_requested)":
+		conditionMet = lease.owner == str(values[":owner"]) && !lease.rerunRequested
+	default:
+		return nil, errors.New("unexpected condition " + aws.ToString(params.ConditionExpression))
+	}
+	if !conditionMet {
+		return nil, &ddbTypes.ConditionalCheckFailedException{}
+	}
+
+	switch expression {
+	case "SET lease_owner = :owner, lease_expires_at = :expires_at REMOVE rerun_requested":
+		*lease = fakeLease{owner: str(values[":owner"]), expiresAt: number(values[":expires_at"])}
+	case "SET rerun_requested = :true":
+		lease.rerunRequested = true
+	case "SET lease_expires_at = :expires_at REMOVE rerun_requested":
+		lease.expiresAt, lease.rerunRequested = number(values[":expires_at"]), false
+	case "SET lease_expires_at = :now REMOVE lease_owner":
+		lease.expiresAt, lease.owner = number(values[":now"]), ""
+	case "SET lease_expires_at = :now REMOVE lease_owner, rerun_requested":
+		*lease = fakeLease{expiresAt: number(values[":now"])}
+	default:
+		return nil, errors.New("unexpected update " + expression)
+	}
+	f.leases[key] = lease
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func str(value ddbTypes.AttributeValue) string {
+	return value.(*ddbTypes.AttributeValueMemberS).Value
+}
+
+func number(value ddbTypes.AttributeValue) int64 {
+	n, _ := strconv.ParseInt(value.(*ddbTypes.AttributeValueMemberN).Value, 10, 64)
+	return n
+}
+
+func newTestLeases() (*Leases, *fakeLeaseTable) {
+	table := &fakeLeaseTable{leases: map[string]*fakeLease{}}
+	return &Leases{TableName: aws.String("leases"), Client: table}, table
+}
+
+func TestAcquireLease(t *testing.T) {
+	leases, table := newTestLeases()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt); err != nil {
+		t.Fatalf("expected to acquire a free lease, got %v", err)
+	}
+	if err := leases.Acquire(ctx, "acme/widget", "b", expiresAt); !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("expected ErrLeaseHeld while the lease is held by another owner, got %v", err)
+	}
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt.Add(time.Minute)); err != nil {
+		t.Errorf("expected the owner to acquire the lease again, got %v", err)
+	}
+	if err := leases.Acquire(ctx, "acme/gadget", "b", expiresAt); err != nil {
+		t.Errorf("expected the leases of other providers to be free, got %v", err)
+	}
+
+	table.leases["acme/widget"].expiresAt = time.Now().Add(-time.Second).Unix()
+	if err := leases.Acquire(ctx, "acme/widget", "b", expiresAt); err != nil {
+		t.Fatalf("expected to take over an expired lease, got %v", err)
+	}
+	if owner := table.leases["acme/widget"].owner; owner != "b" {
+		t.Errorf("expected b to own the lease, got %q", owner)
+	}
+}
+
+func TestReleaseLease(t *testing.T) {
+	leases, table := newTestLeases()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	rerun, err := leases.Release(ctx, "acme/widget", "b", expiresAt)
+	if err != nil || rerun {
+		t.Fatalf("expected releasing as a non-owner to do nothing, got %v (error: %v)", rerun, err)
+	}
+	if owner := table.leases["acme/widget"].owner; owner != "a" {
+		t.Errorf("expected a to still own the lease, got %q", owner)
+	}
+
+	rerun, err = leases.Release(ctx, "acme/widget", "a", expiresAt)
+	if err != nil || rerun {
+		t.Fatalf("expected the lease to be released, got %v (error: %v)", rerun, err)
+	}
+	if err := leases.Acquire(ctx, "acme/widget", "b", expiresAt); err != nil {
+		t.Errorf("expected a released lease to be free, got %v", err)
+	}
+}
+
+func TestRequestRerun(t *testing.T) {
+	leases, _ := newTestLeases()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := leases.RequestRerun(ctx, "acme/widget"); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Errorf("expected ErrLeaseNotHeld without a lease, got %v", err)
+	}
+
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := leases.RequestRerun(ctx, "acme/widget"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// the owner keeps the lease to populate the versions again, then releases it
+	rerun, err := leases.Release(ctx, "acme/widget", "a", expiresAt)
+	if err != nil || !rerun {
+		t.Fatalf("expected a rerun, got %v (error: %v)", rerun, err)
+	}
+	if err := leases.Acquire(ctx, "acme/widget", "b", expiresAt); !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("expected the lease to be kept for the rerun, got %v", err)
+	}
+	rerun, err = leases.Release(ctx, "acme/widget", "a", expiresAt)
+	if err != nil || rerun {
+		t.Fatalf("expected the lease to be released, got %v (error: %v)", rerun, err)
+	}
+}
+
+func TestRequestRerunWhileReleasing(t *testing.T) {
+	leases, table := newTestLeases()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	// a rerun is requested between checking for reruns and releasing the lease
+	requested := false
+	table.beforeUpdate = func(expression string) {
+		if expression == "SET lease_expires_at = :now REMOVE lease_owner" && !requested {
+			requested = true
+			table.leases["acme/widget"].rerunRequested = true
+		}
+	}
+
+	rerun, err := leases.Release(ctx, "acme/widget", "a", expiresAt)
+	if err != nil || !rerun {
+		t.Fatalf("expected the concurrent request to cause a rerun, got %v (error: %v)", rerun, err)
+	}
+}
+
+func TestAbandonLease(t *testing.T) {
+	leases, table := newTestLeases()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := leases.Acquire(ctx, "acme/widget", "a", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := leases.RequestRerun(ctx, "acme/widget"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := leases.Abandon(ctx, "acme/widget", "b"); err != nil {
+		t.Fatalf("expected abandoning as a non-owner to do nothing, got %v", err)
+	}
+	if owner := table.leases["acme/widget"].owner; owner != "a" {
+		t.Errorf("expected a to still own the lease, got %q", owner)
+	}
+
+	if err := leases.Abandon(ctx, "acme/widget", "a"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := leases.Acquire(ctx, "acme/widget", "b", expiresAt); err != nil {
+		t.Errorf("expected an abandoned lease to be free, got %v", err)
+	}
+	if table.leases["acme/widget"].rerunRequested {
+		t.Error("expected the rerun request to be abandoned with the lease")
+	}
+}
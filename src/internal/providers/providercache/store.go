@@ -6,12 +6,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
 	"golang.org/x/exp/slog"
 )
 
@@ -19,6 +24,14 @@ type CompressedCacheItem struct {
 	Provider    string    `dynamodbav:"provider"`
 	Data        string    `dynamodbav:"data"`
 	LastUpdated time.Time `dynamodbav:"last_updated"`
+	Description string    `dynamodbav:"description"`
+	SourceURL   string    `dynamodbav:"source_url"`
+	Archived    bool      `dynamodbav:"archived"`
+	Disabled    bool      `dynamodbav:"disabled"`
+	MovedTo     string    `dynamodbav:"moved_to"`
+
+	IngestionWarnings []string `dynamodbav:"ingestion_warnings"`
+	SchemaVersion     int      `dynamodbav:"schema_version"`
 }
 
 func compress(data []byte) (string, error) {
@@ -35,7 +48,39 @@ func compress(data []byte) (string, error) {
 	return base64.StdEncoding.EncodeToString(b.Bytes()), nil
 }
 
+// ErrConcurrentUpdate is returned by StoreIfUnchanged when the versions were stored by another writer since they were read.
+var ErrConcurrentUpdate = errors.New("the cached versions were updated concurrently")
+
 func (p *Handler) Store(ctx context.Context, key string, versions types.VersionList) error {
+	return p.store(ctx, key, versions, nil, false)
+}
+
+// StoreFetchedInFull stores versions that were all fetched from the release source, and records that they were
+// fetched with the current types.SchemaVersion.
+func (p *Handler) StoreFetchedInFull(ctx context.Context, key string, versions types.VersionList) error {
+	return p.store(ctx, key, versions, nil, true)
+}
+
+// StoreIfUnchanged stores the versions only if they were last updated at lastUpdated, the LastUpdated of the item they
+// were read from, or if no versions are stored yet when lastUpdated is zero. It returns ErrConcurrentUpdate otherwise.
+func (p *Handler) StoreIfUnchanged(ctx context.Context, key string, versions types.VersionList, lastUpdated time.Time) error {
+	condition := &storeCondition{expression: "attribute_not_exists(last_updated)"}
+	if !lastUpdated.IsZero() {
+		previous, err := attributevalue.Marshal(lastUpdated)
+		if err != nil {
+			return fmt.Errorf("got error marshalling last updated time: %w", err)
+		}
+		condition = &storeCondition{expression: "last_updated = :previous_last_updated", previousLastUpdated: previous}
+	}
+	return p.store(ctx, key, versions, condition, false)
+}
+
+type storeCondition struct {
+	expression          string
+	previousLastUpdated ddbTypes.AttributeValue
+}
+
+func (p *Handler) store(ctx context.Context, key string, versions types.VersionList, condition *storeCondition, fetchedInFull bool) error {
 	jsonData, err := json.Marshal(versions)
 	if err != nil {
 		slog.Error("got error marshalling item to JSON", "error", err)
@@ -48,31 +93,103 @@ func (p *Handler) Store(ctx context.Context, key string, versions types.VersionL
 		return fmt.Errorf("got error compressing JSON data: %w", err)
 	}
 
-	// make an anonymous type to satisfy the MarshalMap function
-	toCache := CompressedCacheItem{
-		Provider:    key,
-		Data:        compressedData,
-		LastUpdated: time.Now(),
-	}
-
-	marshalledItem, err := attributevalue.MarshalMap(toCache)
+	lastUpdated, err := attributevalue.Marshal(time.Now())
 	if err != nil {
-		slog.Error("got error marshalling dynamodb item", "error", err)
-		return fmt.Errorf("got error marshalling dynamodb item: %w", err)
+		slog.Error("got error marshalling last updated time", "error", err)
+		return fmt.Errorf("got error marshalling last updated time: %w", err)
 	}
 
-	putItemInput := &dynamodb.PutItemInput{
-		Item:      marshalledItem,
+	// the versions are updated in place rather than replacing the whole item, so that the repository metadata
+	// stored alongside them is kept
+	updateItemInput := &dynamodb.UpdateItemInput{
 		TableName: p.TableName,
+		Key: map[string]ddbTypes.AttributeValue{
+			"provider": &ddbTypes.AttributeValueMemberS{Value: key},
+		},
+		UpdateExpression: aws.String("SET #data = :data, last_updated = :last_updated"),
+		// "data" is a reserved word in DynamoDB expressions
+		ExpressionAttributeNames: map[string]string{"#data": "data"},
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":data":         &ddbTypes.AttributeValueMemberS{Value: compressedData},
+			":last_updated": lastUpdated,
+		},
+	}
+	if fetchedInFull {
+		updateItemInput.UpdateExpression = aws.String("SET #data = :data, last_updated = :last_updated, schema_version = :schema_version")
+		updateItemInput.ExpressionAttributeValues[":schema_version"] = &ddbTypes.AttributeValueMemberN{Value: strconv.Itoa(types.SchemaVersion)}
+	}
+	if condition != nil {
+		updateItemInput.ConditionExpression = aws.String(condition.expression)
+		if condition.previousLastUpdated != nil {
+			updateItemInput.ExpressionAttributeValues[":previous_last_updated"] = condition.previousLastUpdated
+		}
 	}
 
 	slog.Info("Storing provider versions", "key", key, "versions", len(versions))
-	_, err = p.Client.PutItem(ctx, putItemInput)
+	_, err = p.Client.UpdateItem(ctx, updateItemInput)
+	var conditionErr *ddbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		slog.Info("Provider versions were updated concurrently", "key", key)
+		return ErrConcurrentUpdate
+	}
 	if err != nil {
-		slog.Error("got error calling PutItem", "error", err)
-		return fmt.Errorf("got error calling PutItem: %w", err)
+		slog.Error("got error calling UpdateItem", "error", err)
+		return fmt.Errorf("got error calling UpdateItem: %w", err)
 	}
 
 	slog.Info("Successfully stored provider versions", "key", key, "versions", len(versions))
 	return nil
 }
+
+// StoreRepositoryMetadata stores the description, web URL and state of the provider's repository alongside its versions.
+func (p *Handler) StoreRepositoryMetadata(ctx context.Context, key string, metadata source.RepositoryMetadata) error {
+	_, err := p.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: p.TableName,
+		Key: map[string]ddbTypes.AttributeValue{
+			"provider": &ddbTypes.AttributeValueMemberS{Value: key},
+		},
+		UpdateExpression: aws.String("SET description = :description, source_url = :source_url, archived = :archived, disabled = :disabled, moved_to = :moved_to"),
+		ExpressionAttributeValues: map[string]ddbTypes.AttributeValue{
+			":description": &ddbTypes.AttributeValueMemberS{Value: metadata.Description},
+			":source_url":  &ddbTypes.AttributeValueMemberS{Value: metadata.URL},
+			":archived":    &ddbTypes.AttributeValueMemberBOOL{Value: metadata.Archived},
+			":disabled":    &ddbTypes.AttributeValueMemberBOOL{Value: metadata.Disabled},
+			":moved_to":    &ddbTypes.AttributeValueMemberS{Value: metadata.MovedTo},
+		},
+	})
+	if err != nil {
+		slog.Error("got error storing repository metadata", "key", key, "error", err)
+		return fmt.Errorf("got error storing repository metadata: %w", err)
+	}
+
+	slog.Info("Successfully stored repository metadata", "key", key)
+	return nil
+}
+
+// StoreIngestionWarnings replaces the warnings about releases of the provider that could not be added to its versions.
+func (p *Handler) StoreIngestionWarnings(ctx context.Context, key string, warnings []string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: p.TableName,
+		Key: map[string]ddbTypes.AttributeValue{
+			"provider": &ddbTypes.AttributeValueMemberS{Value: key},
+		},
+		UpdateExpression: aws.String("REMOVE ingestion_warnings"),
+	}
+
+	if len(warnings) > 0 {
+		warningsValue, err := attributevalue.Marshal(warnings)
+		if err != nil {
+			return fmt.Errorf("got error marshalling ingestion warnings: %w", err)
+		}
+		input.UpdateExpression = aws.String("SET ingestion_warnings = :warnings")
+		input.ExpressionAttributeValues = map[string]ddbTypes.AttributeValue{":warnings": warningsValue}
+	}
+
+	if _, err := p.Client.UpdateItem(ctx, input); err != nil {
+		slog.Error("got error storing ingestion warnings", "key", key, "error", err)
+		return fmt.Errorf("got error storing ingestion warnings: %w", err)
+	}
+
+	slog.Info("Successfully stored ingestion warnings", "key", key, "warnings", len(warnings))
+	return nil
+}
@@ -1,9 +1,22 @@
 package providers
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // GetRepoName returns the repo name for a provider
 // The repo name should match the format `terraform-provider-<name>`
 func GetRepoName(name string) string {
 	return fmt.Sprintf("terraform-provider-%s", name)
 }
+
+// GetProviderType returns the provider type hosted in a repository, and false if the repository name
+// does not match the format `terraform-provider-<name>`.
+func GetProviderType(repoName string) (string, bool) {
+	providerType, ok := strings.CutPrefix(repoName, "terraform-provider-")
+	if !ok || providerType == "" {
+		return "", false
+	}
+	return providerType, true
+}
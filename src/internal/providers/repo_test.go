@@ -0,0 +1,25 @@
+package providers
+
+import "testing"
+
+func TestGetProviderType(t *testing.T) {
+	tests := []struct {
+		repoName     string
+		expectedType string
+		expectedOk   bool
+	}{
+		{repoName: "terraform-provider-aws", expectedType: "aws", expectedOk: true},
+		{repoName: "terraform-provider-", expectedOk: false},
+		{repoName: "terraform-aws-vpc", expectedOk: false},
+		{repoName: "website", expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoName, func(t *testing.T) {
+			providerType, ok := GetProviderType(tt.repoName)
+			if providerType != tt.expectedType || ok != tt.expectedOk {
+				t.Errorf("GetProviderType(%q) = %q, %v, want %q, %v", tt.repoName, providerType, ok, tt.expectedType, tt.expectedOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+// VerifyShaSumsSignature checks that signature is a valid detached GPG signature of the SHA256SUMS contents,
+// made by one of the given public keys. Both binary and ASCII-armored signatures are accepted.
+func VerifyShaSumsSignature(publicKeys []types.GPGPublicKey, shaSums []byte, signature []byte) error {
+	if len(publicKeys) == 0 {
+		return errors.New("no public keys registered for the namespace")
+	}
+
+	keyRing, err := crypto.NewKeyRing(nil)
+	if err != nil {
+		return fmt.Errorf("could not create key ring: %w", err)
+	}
+
+	for _, publicKey := range publicKeys {
+		key, keyErr := crypto.NewKeyFromArmored(publicKey.ASCIIArmor)
+		if keyErr != nil {
+			return fmt.Errorf("could not parse public key %s: %w", publicKey.KeyID, keyErr)
+		}
+		if addErr := keyRing.AddKey(key); addErr != nil {
+			return fmt.Errorf("could not add public key %s: %w", publicKey.KeyID, addErr)
+		}
+	}
+
+	var pgpSignature *crypto.PGPSignature
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		pgpSignature, err = crypto.NewPGPSignatureFromArmored(string(signature))
+		if err != nil {
+			return fmt.Errorf("could not parse signature: %w", err)
+		}
+	} else {
+		pgpSignature = crypto.NewPGPSignature(signature)
+	}
+
+	if err := keyRing.VerifyDetached(crypto.NewPlainMessage(shaSums), pgpSignature, crypto.GetUnixTime()); err != nil {
+		return fmt.Errorf("signature does not match any of the namespace's public keys: %w", err)
+	}
+	return nil
+}
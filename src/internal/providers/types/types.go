@@ -3,16 +3,19 @@ package types
 import (
 	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/opentofu/registry/internal/platform"
+	"github.com/opentofu/registry/internal/semver"
 )
 
 // Version represents an individual provider version.
 // It provides details such as the version number, supported Terraform protocol versions, and platforms the provider is available for.
 // This is made to match the registry v1 API response format for listing provider versions.
 type Version struct {
-	Version   string              `json:"version"`   // The version number of the provider.
-	Protocols []string            `json:"protocols"` // The protocol versions the provider supports.
-	Platforms []platform.Platform `json:"platforms"` // A list of platforms for which this provider version is available.
+	Version     string              `json:"version"`                // The version number of the provider.
+	Protocols   []string            `json:"protocols"`              // The protocol versions the provider supports.
+	Platforms   []platform.Platform `json:"platforms"`              // A list of platforms for which this provider version is available.
+	PublishedAt *time.Time          `json:"published_at,omitempty"` // The time the version was released, if known. This is an extension of the v1 API.
 }
 
 // VersionDetails provides comprehensive details about a specific provider version.
@@ -47,6 +50,47 @@ type CacheItem struct {
 	Provider    string      `dynamodbav:"provider"`
 	Versions    VersionList `dynamodbav:"versions"`
 	LastUpdated time.Time   `dynamodbav:"last_updated"`
+	Description string      `dynamodbav:"description"` // The description of the provider's repository.
+	SourceURL   string      `dynamodbav:"source_url"`  // The web URL of the provider's repository.
+	Archived    bool        `dynamodbav:"archived"`    // Whether the provider's repository is archived.
+	Disabled    bool        `dynamodbav:"disabled"`    // Whether the provider's repository has been disabled.
+	MovedTo     string      `dynamodbav:"moved_to"`    // The "<owner>/<name>" the provider's repository has moved to, if any.
+
+	// IngestionWarnings describe the releases that could not be added to Versions, such as tags that are not valid versions.
+	IngestionWarnings []string `dynamodbav:"ingestion_warnings"`
+	// SchemaVersion is the SchemaVersion the versions were last fetched in full with, or 0 if they never were.
+	SchemaVersion int `dynamodbav:"schema_version"`
+}
+
+// SchemaVersion is increased whenever the details recorded for cached versions change, such as when the publication
+// times of versions started to be recorded. Versions cached with an older schema version are fetched in full once to
+// fill in the new details, rather than only fetching the new releases.
+const SchemaVersion = 1
+
+// NamespaceIndex lists the providers discovered in a namespace by crawling its repositories.
+type NamespaceIndex struct {
+	Namespace   string
+	Providers   []IndexedProvider
+	LastUpdated time.Time
+}
+
+// IndexedProvider describes a single provider in a NamespaceIndex, as found on its repository.
+type IndexedProvider struct {
+	Type        string   `dynamodbav:"type"`        // The type of the provider.
+	Repository  string   `dynamodbav:"repository"`  // The name of the provider's repository.
+	Description string   `dynamodbav:"description"` // The description of the provider's repository.
+	SourceURL   string   `dynamodbav:"source_url"`  // The web URL of the provider's repository.
+	Topics      []string `dynamodbav:"topics"`      // The topics the provider's repository is tagged with.
+}
+
+// Find returns the provider of the given type, or nil if it is not in the index.
+func (i *NamespaceIndex) Find(providerType string) *IndexedProvider {
+	for j := range i.Providers {
+		if i.Providers[j].Type == providerType {
+			return &i.Providers[j]
+		}
+	}
+	return nil
 }
 
 const allowedAge = (1 * time.Hour) - (5 * time.Minute) //nolint:gomnd // 55 minutes
@@ -66,21 +110,81 @@ func (l VersionList) ToVersions() []Version {
 	return versionsToReturn
 }
 
+// Latest returns the highest version in the list, preferring stable versions over prereleases.
+// Versions that cannot be parsed are ignored. It returns nil if the list contains no valid versions.
+func (l VersionList) Latest() *CacheVersion {
+	var latest, latestPrerelease *CacheVersion
+	var latestVersion, latestPrereleaseVersion *version.Version
+	for i := range l {
+		v, err := version.NewVersion(l[i].Version)
+		if err != nil {
+			continue
+		}
+
+		if l[i].IsPrerelease || v.Prerelease() != "" {
+			if latestPrereleaseVersion == nil || v.GreaterThan(latestPrereleaseVersion) {
+				latestPrerelease, latestPrereleaseVersion = &l[i], v
+			}
+			continue
+		}
+
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = &l[i], v
+		}
+	}
+
+	if latest == nil {
+		return latestPrerelease
+	}
+	return latest
+}
+
+// SortDescending sorts the list from the highest to the lowest version. Versions that cannot be parsed are moved to the end.
+func (l VersionList) SortDescending() {
+	semver.SortDescending(l, func(v CacheVersion) string { return v.Version })
+}
+
+// Resolve returns the highest version in the list matching the constraints, or nil if none matches.
+func (l VersionList) Resolve(constraints semver.Constraints) *CacheVersion {
+	i := semver.Highest(l, constraints, func(v CacheVersion) string { return v.Version })
+	if i < 0 {
+		return nil
+	}
+	return &l[i]
+}
+
+// After returns the versions following the given version in a list sorted by SortDescending, which are the versions
+// lower than it. The version does not need to be in the list, such as when it was yanked since, and false is only
+// returned if it cannot be parsed.
+func (l VersionList) After(after string) (VersionList, bool) {
+	cursor, err := version.NewVersion(after)
+	if err != nil {
+		return nil, false
+	}
+
+	for i := range l {
+		// versions that cannot be parsed are sorted last
+		v, err := version.NewVersion(l[i].Version)
+		if err != nil || v.LessThan(cursor) {
+			return l[i:], true
+		}
+	}
+	return nil, true
+}
+
 func (l VersionList) Deduplicate() VersionList {
 	if len(l) == 0 {
 		return l
 	}
-	versions := make(map[string]CacheVersion)
-	for _, v := range l {
-		if _, ok := versions[v.Version]; !ok {
-			versions[v.Version] = v
-		}
-	}
+	seen := make(map[string]bool)
 
-	// Convert the map back into a list
+	// keep the first occurrence of each version, in the original order
 	var versionsToReturn VersionList
-	for _, v := range versions {
-		versionsToReturn = append(versionsToReturn, v)
+	for _, v := range l {
+		if !seen[v.Version] {
+			seen[v.Version] = true
+			versionsToReturn = append(versionsToReturn, v)
+		}
 	}
 	return versionsToReturn
 }
@@ -104,7 +208,9 @@ func (i *CacheItem) GetVersionDetails(version string, os string, arch string) (*
 type CacheVersion struct {
 	Version         string                        `json:"version"` // The version number of the provider.
 	DownloadDetails []CacheVersionDownloadDetails `json:"download_details"`
-	Protocols       []string                      `json:"protocols"` // The protocol versions the provider supports.
+	Protocols       []string                      `json:"protocols"`     // The protocol versions the provider supports.
+	PublishedAt     time.Time                     `json:"published_at"`  // The time the version was released, zero if unknown.
+	IsPrerelease    bool                          `json:"is_prerelease"` // Indicates if the version is a prerelease, by its version number or as marked by the release source.
 }
 
 // ToVersion converts a CacheVersion to a Version to be used in the provider version listing endpoint.
@@ -114,11 +220,29 @@ func (v *CacheVersion) ToVersion() Version {
 		platforms[i] = d.Platform
 	}
 
-	return Version{
+	result := Version{
 		Version:   v.Version,
 		Protocols: v.Protocols,
 		Platforms: platforms,
 	}
+	if !v.PublishedAt.IsZero() {
+		publishedAt := v.PublishedAt
+		result.PublishedAt = &publishedAt
+	}
+	return result
+}
+
+// IsSigned returns true if every platform of this version has a SHA256SUMS signature attached.
+func (v *CacheVersion) IsSigned() bool {
+	if len(v.DownloadDetails) == 0 {
+		return false
+	}
+	for _, d := range v.DownloadDetails {
+		if d.SHASumsSignatureURL == "" {
+			return false
+		}
+	}
+	return true
 }
 
 // GetVersionDetails gets the VersionDetails for a specific OS and architecture.
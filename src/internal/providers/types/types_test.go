@@ -50,3 +50,93 @@ func TestDeduplicate(t *testing.T) {
 		})
 	}
 }
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    VersionList
+		expected string
+	}{
+		{
+			name:     "empty",
+			input:    VersionList{},
+			expected: "",
+		},
+		{
+			name:     "highest version",
+			input:    VersionList{{Version: "1.2.0"}, {Version: "1.10.0"}, {Version: "1.9.1"}},
+			expected: "1.10.0",
+		},
+		{
+			name:     "stable versions are preferred over prereleases",
+			input:    VersionList{{Version: "2.0.0-beta1"}, {Version: "1.10.0"}},
+			expected: "1.10.0",
+		},
+		{
+			name:     "only prereleases",
+			input:    VersionList{{Version: "2.0.0-alpha1"}, {Version: "2.0.0-beta1"}},
+			expected: "2.0.0-beta1",
+		},
+		{
+			name:     "invalid versions are ignored",
+			input:    VersionList{{Version: "latest"}, {Version: "0.1.0"}},
+			expected: "0.1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if latest := tt.input.Latest(); latest != nil {
+				got = latest.Version
+			}
+			if got != tt.expected {
+				t.Errorf("Latest() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortDescending(t *testing.T) {
+	input := VersionList{{Version: "1.2.0"}, {Version: "invalid"}, {Version: "1.10.0"}, {Version: "2.0.0-beta1"}, {Version: "1.9.1"}}
+	input.SortDescending()
+
+	got := make([]string, 0, len(input))
+	for _, v := range input {
+		got = append(got, v.Version)
+	}
+
+	expected := []string{"2.0.0-beta1", "1.10.0", "1.9.1", "1.2.0", "invalid"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("SortDescending() = %v, want %v", got, expected)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	input := VersionList{{Version: "1.2.0"}, {Version: "1.1.0"}, {Version: "1.0.0"}}
+
+	got, ok := input.After("1.2.0")
+	if !ok || !reflect.DeepEqual(got, VersionList{{Version: "1.1.0"}, {Version: "1.0.0"}}) {
+		t.Errorf("After(1.2.0) = %v, %v", got, ok)
+	}
+
+	got, ok = input.After("1.0.0")
+	if !ok || len(got) != 0 {
+		t.Errorf("After(1.0.0) = %v, %v", got, ok)
+	}
+
+	// versions that are no longer listed are sought by their order
+	got, ok = input.After("1.1.5")
+	if !ok || !reflect.DeepEqual(got, VersionList{{Version: "1.1.0"}, {Version: "1.0.0"}}) {
+		t.Errorf("After(1.1.5) = %v, %v", got, ok)
+	}
+
+	got, ok = input.After("0.9.0")
+	if !ok || len(got) != 0 {
+		t.Errorf("After(0.9.0) = %v, %v", got, ok)
+	}
+
+	if _, ok = input.After("invalid"); ok {
+		t.Errorf("expected an invalid version to be rejected")
+	}
+}
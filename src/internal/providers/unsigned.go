@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+// UnsignedReleasePolicy controls how provider releases without a signed SHA256SUMS file are served.
+type UnsignedReleasePolicy string
+
+const (
+	// UnsignedReleasePolicyRequire refuses to serve releases that are missing a SHA256SUMS signature.
+	UnsignedReleasePolicyRequire UnsignedReleasePolicy = "require"
+	// UnsignedReleasePolicyAllow serves unsigned releases as if they were signed.
+	UnsignedReleasePolicyAllow UnsignedReleasePolicy = "allow"
+	// UnsignedReleasePolicyWarn serves unsigned releases, but adds a warning to the versions listing.
+	UnsignedReleasePolicyWarn UnsignedReleasePolicy = "warn"
+)
+
+// DefaultUnsignedReleasePolicy is the policy used for namespaces that have not been configured explicitly.
+// Unsigned releases are only served from namespaces that opt into them.
+const DefaultUnsignedReleasePolicy = UnsignedReleasePolicyRequire
+
+// ParseUnsignedReleasePolicy converts a configuration value into an UnsignedReleasePolicy.
+func ParseUnsignedReleasePolicy(value string) (UnsignedReleasePolicy, error) {
+	switch policy := UnsignedReleasePolicy(value); policy {
+	case UnsignedReleasePolicyRequire, UnsignedReleasePolicyAllow, UnsignedReleasePolicyWarn:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid unsigned release policy %q, expected one of: require, allow, warn", value)
+}
+
+// AllowsUnsigned returns true if releases without a signature may be served under this policy.
+func (p UnsignedReleasePolicy) AllowsUnsigned() bool {
+	return p != UnsignedReleasePolicyRequire
+}
+
+// ApplyUnsignedReleasePolicy filters the given versions according to the policy.
+// Under the "require" policy unsigned versions are removed, and under the "warn" policy
+// they are kept and a warning naming them is returned alongside the versions.
+func ApplyUnsignedReleasePolicy(versions types.VersionList, policy UnsignedReleasePolicy) (types.VersionList, []string) {
+	var unsigned []string
+	filtered := make(types.VersionList, 0, len(versions))
+
+	for _, v := range versions {
+		if v.IsSigned() {
+			filtered = append(filtered, v)
+			continue
+		}
+
+		unsigned = append(unsigned, v.Version)
+		if policy.AllowsUnsigned() {
+			filtered = append(filtered, v)
+		}
+	}
+
+	if policy != UnsignedReleasePolicyWarn || len(unsigned) == 0 {
+		return filtered, nil
+	}
+
+	return filtered, []string{
+		fmt.Sprintf("The following versions of this provider are not signed and their checksums cannot be verified: %s", strings.Join(unsigned, ", ")),
+	}
+}
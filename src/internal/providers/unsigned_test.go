@@ -0,0 +1,66 @@
+package providers_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+func TestApplyUnsignedReleasePolicy(t *testing.T) {
+	signed := types.CacheVersion{
+		Version:         "1.0.0",
+		DownloadDetails: []types.CacheVersionDownloadDetails{{SHASumsSignatureURL: "https://example.com/SHA256SUMS.sig"}},
+	}
+	unsigned := types.CacheVersion{
+		Version:         "1.1.0",
+		DownloadDetails: []types.CacheVersionDownloadDetails{{SHASumsSignatureURL: ""}},
+	}
+	versions := types.VersionList{signed, unsigned}
+
+	tests := []struct {
+		name             string
+		policy           providers.UnsignedReleasePolicy
+		expectedVersions types.VersionList
+		expectWarning    bool
+	}{
+		{
+			name:             "require drops unsigned versions",
+			policy:           providers.UnsignedReleasePolicyRequire,
+			expectedVersions: types.VersionList{signed},
+		},
+		{
+			name:             "allow keeps unsigned versions silently",
+			policy:           providers.UnsignedReleasePolicyAllow,
+			expectedVersions: types.VersionList{signed, unsigned},
+		},
+		{
+			name:             "warn keeps unsigned versions and warns",
+			policy:           providers.UnsignedReleasePolicyWarn,
+			expectedVersions: types.VersionList{signed, unsigned},
+			expectWarning:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := providers.ApplyUnsignedReleasePolicy(versions, tt.policy)
+			if !reflect.DeepEqual(got, tt.expectedVersions) {
+				t.Errorf("expected versions %v, got %v", tt.expectedVersions, got)
+			}
+			if tt.expectWarning != (len(warnings) > 0) {
+				t.Errorf("expected warning: %v, got %v", tt.expectWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestParseUnsignedReleasePolicy(t *testing.T) {
+	if _, err := providers.ParseUnsignedReleasePolicy("warn"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := providers.ParseUnsignedReleasePolicy("sometimes"); err == nil {
+		t.Fatalf("expected an error for an unknown policy")
+	}
+}
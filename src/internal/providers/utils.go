@@ -7,22 +7,23 @@ import (
 	"strings"
 
 	"github.com/aws/aws-xray-sdk-go/xray"
-	"github.com/opentofu/registry/internal/github"
 	"github.com/opentofu/registry/internal/platform"
+	"github.com/opentofu/registry/internal/source"
 	"golang.org/x/exp/slog"
 )
 
-func getShaSum(ctx context.Context, downloadURL string, filename string) (shaSum string, err error) {
+func getShaSum(ctx context.Context, src source.ReleaseSource, asset source.Asset, filename string) (shaSum string, err error) {
 	err = xray.Capture(ctx, "filename.shasum", func(tracedCtx context.Context) error {
 		xray.AddAnnotation(tracedCtx, "filename", filename)
 
-		assetContents, assetErr := github.DownloadAssetContents(tracedCtx, downloadURL)
+		assetContents, assetErr := src.DownloadAsset(tracedCtx, asset)
 		if assetErr != nil {
 			return fmt.Errorf("failed to download asset contents: %w", assetErr)
 		}
+		defer assetContents.Close()
 
 		contents, contentsErr := io.ReadAll(assetContents)
-		if err != nil {
+		if contentsErr != nil {
 			return fmt.Errorf("failed to read asset contents: %w", contentsErr)
 		}
 
@@ -46,7 +47,7 @@ func findShaSum(contents []byte, filename string, shaSum string) string {
 	return shaSum
 }
 
-func getSupportedArchAndOS(assets []github.ReleaseAsset) []platform.Platform {
+func getSupportedArchAndOS(assets []source.Asset) []platform.Platform {
 	var platforms []platform.Platform
 	slog.Info("Finding supported platforms", "assets", len(assets))
 	for _, asset := range assets {
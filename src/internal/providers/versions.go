@@ -4,15 +4,16 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-xray-sdk-go/xray"
-	"github.com/opentofu/registry/internal/github"
 	"github.com/opentofu/registry/internal/platform"
 	"github.com/opentofu/registry/internal/providers/types"
-	"github.com/shurcooL/githubv4"
+	"github.com/opentofu/registry/internal/semver"
+	"github.com/opentofu/registry/internal/source"
 	"golang.org/x/exp/slog"
 )
 
@@ -21,25 +22,44 @@ type versionResult struct {
 	Err     error
 }
 
-// GetVersions fetches and returns a list of available versions of a given provider hosted on GitHub.
+// GetVersions fetches and returns a list of available versions of a given provider from its release source.
 // The returned versions also include information about supported platforms and the Terraform protocol versions they are compatible with.
 //
 // Parameters:
 // - ctx: The context used to control cancellations and timeouts.
-// - ghClient: The GitHub GraphQL client to interact with the GitHub GraphQL API.
-// - namespace: The GitHub namespace (typically, the organization or user) under which the provider repository is hosted.
+// - src: The release source hosting the provider repository.
+// - namespace: The namespace (typically, the organization or user) under which the provider repository is hosted.
 // - name: The name of the provider repository.
 // - since: The time after which to fetch versions. If nil, it fetches all versions.
 //
-// Returns a slice of Version structures detailing each available version. If an error occurs during fetching or processing, it returns an error.
-func GetVersions(ctx context.Context, ghClient *githubv4.Client, namespace string, name string, since *time.Time) (versions types.VersionList, err error) {
+// Returns the available versions sorted from the newest to the oldest, and a warning for each release that was skipped because
+// its tag is not a valid semantic version. If an error occurs during fetching or processing, it returns an error.
+func GetVersions(ctx context.Context, src source.ReleaseSource, namespace string, name string, since *time.Time) (types.VersionList, []string, error) {
+	return GetNewVersions(ctx, src, namespace, name, since, nil)
+}
+
+// GetNewVersions is like GetVersions, but lets release sources that read each release with its own request skip the
+// releases of the known versions. The known versions may still be returned by other release sources.
+func GetNewVersions(ctx context.Context, src source.ReleaseSource, namespace string, name string, since *time.Time, known types.VersionList) (versions types.VersionList, warnings []string, err error) {
+	var isKnown func(tag string) bool
+	if len(known) > 0 {
+		knownVersions := make(map[string]bool, len(known))
+		for _, v := range known {
+			knownVersions[v.Version] = true
+		}
+		isKnown = func(tag string) bool {
+			v, normalizeErr := semver.Normalize(tag)
+			return normalizeErr == nil && knownVersions[v]
+		}
+	}
+
 	err = xray.Capture(ctx, "provider.versions", func(tracedCtx context.Context) error {
 		xray.AddAnnotation(tracedCtx, "namespace", namespace)
 		xray.AddAnnotation(tracedCtx, "name", name)
 
 		slog.Info("Fetching versions")
 
-		releases, releasesErr := github.FetchReleases(tracedCtx, ghClient, namespace, name, since)
+		releases, releasesErr := source.FetchNewReleases(tracedCtx, src, namespace, name, since, isKnown)
 		if releasesErr != nil {
 			return fmt.Errorf("failed to fetch releases: %w", releasesErr)
 		}
@@ -56,10 +76,16 @@ func GetVersions(ctx context.Context, ghClient *githubv4.Client, namespace strin
 		var wg sync.WaitGroup
 
 		for _, release := range releases {
+			if _, invalidErr := semver.Normalize(release.TagName); invalidErr != nil {
+				slog.Warn("Skipping release with invalid version", "release", release.TagName)
+				warnings = append(warnings, fmt.Sprintf("Skipped release %s: %s", release.TagName, invalidErr))
+				continue
+			}
+
 			wg.Add(1)
-			go func(r github.GHRelease) {
+			go func(r source.Release) {
 				defer wg.Done()
-				getVersionFromGithubRelease(tracedCtx, r, versionCh)
+				getVersionFromRelease(tracedCtx, src, r, versionCh)
 			}(release)
 		}
 
@@ -82,23 +108,25 @@ func GetVersions(ctx context.Context, ghClient *githubv4.Client, namespace strin
 				versions = append(versions, vr.Version)
 			}
 		}
+		// versions are collected in the order they were processed in, which is not stable
+		versions.SortDescending()
 		return nil
 	})
 
-	slog.Info("Successfully found versions", "versions", len(versions))
-	return versions, nil
+	slog.Info("Successfully found versions", "versions", len(versions), "warnings", len(warnings))
+	return versions, warnings, nil
 }
 
-// getVersionFromGithubRelease fetches and returns detailed information about a specific version of a provider hosted on GitHub.
+// getVersionFromRelease fetches and returns detailed information about a specific version of a provider from its release.
 // all results are passed back to the versionCh channel.
-func getVersionFromGithubRelease(ctx context.Context, r github.GHRelease, versionCh chan versionResult) {
+func getVersionFromRelease(ctx context.Context, src source.ReleaseSource, r source.Release, versionCh chan versionResult) {
 	result := versionResult{}
 
 	logger := slog.Default().With("version", r.TagName)
 
 	logger.Info("Processing release")
 
-	assets := r.ReleaseAssets.Nodes
+	assets := r.Assets
 	platforms := getSupportedArchAndOS(assets)
 
 	// if there are no platforms, we can't do anything with this release
@@ -111,7 +139,7 @@ func getVersionFromGithubRelease(ctx context.Context, r github.GHRelease, versio
 
 	logger.Info("Fetching manifest")
 	// Read the manifest so that we can get the protocol versions.
-	manifest, manifestErr := findAndParseManifest(ctx, assets)
+	manifest, manifestErr := findAndParseManifest(ctx, src, assets)
 	if manifestErr != nil {
 		logger.Error("Failed to find and parse manifest", "error", manifestErr)
 		result.Err = fmt.Errorf("failed to find and parse manifest: %w", manifestErr)
@@ -127,7 +155,7 @@ func getVersionFromGithubRelease(ctx context.Context, r github.GHRelease, versio
 
 	slog.Info("Fetching shasums")
 	// download the shasums file so that we can get the checksum for each platform
-	shaSums, err := downloadShaSums(ctx, assets)
+	shaSums, err := downloadShaSums(ctx, src, assets)
 	if err != nil {
 		slog.Error("Failed to download shasums", "error", err)
 		result.Err = fmt.Errorf("failed to download shasums: %w", err)
@@ -137,12 +165,12 @@ func getVersionFromGithubRelease(ctx context.Context, r github.GHRelease, versio
 
 	slog.Info("Found shasums", "shasums", len(shaSums))
 
-	shaSumsURL := github.FindAssetBySuffix(assets, "_SHA256SUMS")
-	shaSumsSignatureURL := github.FindAssetBySuffix(assets, "_SHA256SUMS.sig")
+	shaSumsURL := source.FindAssetBySuffix(assets, "_SHA256SUMS")
+	shaSumsSignatureURL := source.FindAssetBySuffix(assets, "_SHA256SUMS.sig")
 
 	if shaSumsSignatureURL == nil {
 		// make an empty one
-		shaSumsSignatureURL = &github.ReleaseAsset{
+		shaSumsSignatureURL = &source.Asset{
 			DownloadURL: "",
 		}
 	}
@@ -160,19 +188,24 @@ func getVersionFromGithubRelease(ctx context.Context, r github.GHRelease, versio
 		}
 	}
 
+	version, _ := semver.Normalize(r.TagName)
+
 	// only populate the version if we have all download details
 	result.Version = types.CacheVersion{
-		Version:         strings.TrimPrefix(r.TagName, "v"),
+		Version:         version,
 		Protocols:       protocols,
 		DownloadDetails: downloadDetails,
+		PublishedAt:     r.CreatedAt,
+		// the version number is preferred, but releases can also be marked as prereleases on the release source
+		IsPrerelease: semver.IsPrerelease(version) || r.IsPrerelease,
 	}
 
 	versionCh <- result
 }
 
-func getVersionDownloadDetails(platform platform.Platform, assets []github.ReleaseAsset, shaSums map[string]string) *types.CacheVersionDownloadDetails {
+func getVersionDownloadDetails(platform platform.Platform, assets []source.Asset, shaSums map[string]string) *types.CacheVersionDownloadDetails {
 	// find the asset for the given platform
-	asset := github.FindAssetBySuffix(assets, fmt.Sprintf("_%s_%s.zip", platform.OS, platform.Arch))
+	asset := source.FindAssetBySuffix(assets, fmt.Sprintf("_%s_%s.zip", platform.OS, platform.Arch))
 	if asset == nil {
 		slog.Warn("Could not find asset for platform", "platform", platform)
 		return nil
@@ -195,19 +228,24 @@ func getVersionDownloadDetails(platform platform.Platform, assets []github.Relea
 	}
 }
 
-func downloadShaSums(ctx context.Context, assets []github.ReleaseAsset) (map[string]string, error) {
-	asset := github.FindAssetBySuffix(assets, "_SHA256SUMS")
+func downloadShaSums(ctx context.Context, src source.ReleaseSource, assets []source.Asset) (map[string]string, error) {
+	asset := source.FindAssetBySuffix(assets, "_SHA256SUMS")
 	if asset == nil {
 		return nil, fmt.Errorf("could not find shasums asset")
 	}
 
 	// download the asset
-	sumsContent, assetErr := github.DownloadAssetContents(ctx, asset.DownloadURL)
+	sumsContent, assetErr := src.DownloadAsset(ctx, *asset)
 	if assetErr != nil {
 		return nil, fmt.Errorf("failed to download asset: %w", assetErr)
 	}
 	defer sumsContent.Close()
 
+	return ParseShaSums(sumsContent)
+}
+
+// ParseShaSums parses the contents of a SHA256SUMS file into a map of filename to checksum.
+func ParseShaSums(sumsContent io.Reader) (map[string]string, error) {
 	sums := make(map[string]string)
 
 	// read the contents of the shasums file
@@ -233,21 +271,21 @@ func downloadShaSums(ctx context.Context, assets []github.ReleaseAsset) (map[str
 	return sums, nil
 }
 
-// GetVersion fetches and returns detailed information about a specific version of a provider hosted on GitHub.
+// GetVersion fetches and returns detailed information about a specific version of a provider from its release source.
 // The returned information includes the download URL, the filename, SHA sums, and more details pertinent to the specific version, OS, and architecture.
 //
 // Parameters:
 // - ctx: The context used to control cancellations and timeouts.
-// - ghClient: The GitHub GraphQL client to interact with the GitHub GraphQL API.
-// - namespace: The GitHub namespace (typically, the organization or user) under which the provider repository is hosted.
+// - src: The release source hosting the provider repository.
+// - namespace: The namespace (typically, the organization or user) under which the provider repository is hosted.
 // - name: The name of the provider without the "terraform-provider-" prefix.
 // - version: The specific version of the Terraform provider to fetch details for.
 // - os: The operating system for which the provider binary is intended.
 // - arch: The architecture for which the provider binary is intended.
+// - policy: The policy deciding whether a release without a SHA256SUMS signature may be served.
 //
 // Returns a VersionDetails structure with detailed information about the specified version. If an error occurs during fetching or processing, it returns an error.
-
-func GetVersion(ctx context.Context, ghClient *githubv4.Client, namespace string, name string, version string, os string, arch string) (versionDetails *types.VersionDetails, err error) {
+func GetVersion(ctx context.Context, src source.ReleaseSource, namespace string, name string, version string, os string, arch string, policy UnsignedReleasePolicy) (versionDetails *types.VersionDetails, err error) {
 	err = xray.Capture(ctx, "provider.versiondetails", func(tracedCtx context.Context) error {
 		xray.AddAnnotation(tracedCtx, "namespace", namespace)
 		xray.AddAnnotation(tracedCtx, "name", name)
@@ -259,7 +297,7 @@ func GetVersion(ctx context.Context, ghClient *githubv4.Client, namespace string
 
 		// TODO: Replace this with a GetRelease, iterating all the releases is not efficient at all!
 		// Fetch the specific release for the given version.
-		release, releaseErr := github.FindRelease(tracedCtx, ghClient, namespace, name, version)
+		release, releaseErr := src.FindRelease(tracedCtx, namespace, name, version)
 		if releaseErr != nil {
 			return fmt.Errorf("failed to find release: %w", releaseErr)
 		}
@@ -275,7 +313,7 @@ func GetVersion(ctx context.Context, ghClient *githubv4.Client, namespace string
 		}
 
 		// Find and parse the manifest from the release assets.
-		manifest, manifestErr := findAndParseManifest(tracedCtx, release.ReleaseAssets.Nodes)
+		manifest, manifestErr := findAndParseManifest(tracedCtx, src, release.Assets)
 		if manifestErr != nil {
 			return newFetchError("failed to find and parse manifest", ErrCodeManifestNotFound, manifestErr)
 		}
@@ -287,7 +325,7 @@ func GetVersion(ctx context.Context, ghClient *githubv4.Client, namespace string
 		}
 
 		// Identify the appropriate asset for download based on OS and architecture.
-		assetToDownload := github.FindAssetBySuffix(release.ReleaseAssets.Nodes, fmt.Sprintf("_%s_%s.zip", os, arch))
+		assetToDownload := source.FindAssetBySuffix(release.Assets, fmt.Sprintf("_%s_%s.zip", os, arch))
 		if assetToDownload == nil {
 			return newFetchError("failed to find asset to download", ErrCodeAssetNotFound, nil)
 		}
@@ -295,19 +333,25 @@ func GetVersion(ctx context.Context, ghClient *githubv4.Client, namespace string
 		versionDetails.DownloadURL = assetToDownload.DownloadURL
 
 		// Locate the SHA256 checksums and its signature from the release assets.
-		shaSumsAsset := github.FindAssetBySuffix(release.ReleaseAssets.Nodes, "_SHA256SUMS")
-		shasumsSigAsset := github.FindAssetBySuffix(release.ReleaseAssets.Nodes, "_SHA256SUMS.sig")
+		shaSumsAsset := source.FindAssetBySuffix(release.Assets, "_SHA256SUMS")
+		shasumsSigAsset := source.FindAssetBySuffix(release.Assets, "_SHA256SUMS.sig")
 
-		if shaSumsAsset == nil || shasumsSigAsset == nil {
-			slog.Error("Could not find shasums or its signature asset")
-			return newFetchError("failed to find shasums or its signature asset", ErrCodeSHASumsNotFound, nil)
+		if shaSumsAsset == nil {
+			slog.Error("Could not find shasums asset")
+			return newFetchError("failed to find shasums asset", ErrCodeSHASumsNotFound, nil)
 		}
 
 		versionDetails.SHASumsURL = shaSumsAsset.DownloadURL
-		versionDetails.SHASumsSignatureURL = shasumsSigAsset.DownloadURL
+
+		if shasumsSigAsset != nil {
+			versionDetails.SHASumsSignatureURL = shasumsSigAsset.DownloadURL
+		} else if !policy.AllowsUnsigned() {
+			slog.Error("Could not find shasums signature asset", "policy", policy)
+			return newFetchError("failed to find shasums signature asset", ErrCodeSignatureNotFound, nil)
+		}
 
 		// Extract the SHA256 checksum for the asset to download.
-		shaSum, shaSumErr := getShaSum(tracedCtx, shaSumsAsset.DownloadURL, versionDetails.Filename)
+		shaSum, shaSumErr := getShaSum(tracedCtx, src, *shaSumsAsset, versionDetails.Filename)
 		if shaSumErr != nil {
 			slog.Error("Could not get shasum", "error", shaSumErr)
 			return newFetchError("failed to get shasum: %w", ErrCodeSHASumsNotFound, shaSumErr)
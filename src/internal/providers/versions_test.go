@@ -0,0 +1,165 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/source"
+)
+
+// fakeReleaseSource serves a fixed set of releases, with asset contents keyed by download URL.
+type fakeReleaseSource struct {
+	releases []source.Release
+	contents map[string]string
+}
+
+func (f *fakeReleaseSource) RepositoryExists(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeReleaseSource) FetchReleases(_ context.Context, _, _ string, _ *time.Time) ([]source.Release, error) {
+	return f.releases, nil
+}
+
+func (f *fakeReleaseSource) FindRelease(_ context.Context, _, _, version string) (*source.Release, error) {
+	for _, r := range f.releases {
+		if r.TagName == "v"+version {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeReleaseSource) DownloadAsset(_ context.Context, asset source.Asset) (io.ReadCloser, error) {
+	contents, ok := f.contents[asset.DownloadURL]
+	if !ok {
+		return nil, fmt.Errorf("unexpected download of %s", asset.DownloadURL)
+	}
+	return io.NopCloser(strings.NewReader(contents)), nil
+}
+
+func (f *fakeReleaseSource) ModuleDownloadURL(_ context.Context, namespace, name, tag string) (string, error) {
+	return fmt.Sprintf("git::https://example.com/%s/%s?ref=%s", namespace, name, tag), nil
+}
+
+func newFakeRelease(version string, signed bool) (source.Release, map[string]string) {
+	prefix := fmt.Sprintf("terraform-provider-random_%s", version)
+	asset := func(name string) source.Asset {
+		return source.Asset{Name: name, DownloadURL: "https://example.com/" + name}
+	}
+
+	assets := []source.Asset{
+		asset(prefix + "_linux_amd64.zip"),
+		asset(prefix + "_darwin_arm64.zip"),
+		asset(prefix + "_SHA256SUMS"),
+		asset(prefix + "_manifest.json"),
+	}
+	if signed {
+		assets = append(assets, asset(prefix+"_SHA256SUMS.sig"))
+	}
+
+	contents := map[string]string{
+		"https://example.com/" + prefix + "_SHA256SUMS":    fmt.Sprintf("aaaa  %s_linux_amd64.zip\nbbbb  %s_darwin_arm64.zip\n", prefix, prefix),
+		"https://example.com/" + prefix + "_manifest.json": `{"version": 1, "metadata": {"protocol_versions": ["6.0"]}}`,
+	}
+
+	return source.Release{TagName: "v" + version, Assets: assets}, contents
+}
+
+func newFakeReleaseSource() *fakeReleaseSource {
+	src := &fakeReleaseSource{contents: map[string]string{}}
+	for _, r := range []struct {
+		version string
+		signed  bool
+	}{{"1.0.0", true}, {"1.1.0", false}} {
+		release, contents := newFakeRelease(r.version, r.signed)
+		src.releases = append(src.releases, release)
+		for url, c := range contents {
+			src.contents[url] = c
+		}
+	}
+	return src
+}
+
+func TestGetVersions(t *testing.T) {
+	versions, _, err := providers.GetVersions(context.Background(), newFakeReleaseSource(), "hashicorp", "terraform-provider-random", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+
+	for _, v := range versions {
+		if len(v.DownloadDetails) != 2 {
+			t.Errorf("expected 2 platforms for version %s, got %d", v.Version, len(v.DownloadDetails))
+		}
+		if len(v.Protocols) != 1 || v.Protocols[0] != "6.0" {
+			t.Errorf("expected protocols from the manifest for version %s, got %v", v.Version, v.Protocols)
+		}
+		if v.IsSigned() != (v.Version == "1.0.0") {
+			t.Errorf("unexpected signature state for version %s", v.Version)
+		}
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	src := newFakeReleaseSource()
+
+	details, err := providers.GetVersion(context.Background(), src, "baconsoft", "terraform-provider-random", "1.0.0", "linux", "amd64", providers.UnsignedReleasePolicyRequire)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.SHASum != "aaaa" {
+		t.Errorf("expected shasum aaaa, got %s", details.SHASum)
+	}
+	if details.Filename != "terraform-provider-random_1.0.0_linux_amd64.zip" {
+		t.Errorf("unexpected filename %s", details.Filename)
+	}
+
+	_, err = providers.GetVersion(context.Background(), src, "baconsoft", "terraform-provider-random", "1.1.0", "linux", "amd64", providers.UnsignedReleasePolicyRequire)
+	var fetchErr *providers.FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Code != providers.ErrCodeSignatureNotFound {
+		t.Fatalf("expected a signature not found error, got %v", err)
+	}
+
+	details, err = providers.GetVersion(context.Background(), src, "baconsoft", "terraform-provider-random", "1.1.0", "linux", "amd64", providers.UnsignedReleasePolicyAllow)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.SHASumsSignatureURL != "" {
+		t.Errorf("expected no signature URL, got %s", details.SHASumsSignatureURL)
+	}
+}
+
+func TestGetVersionsSkipsInvalidTags(t *testing.T) {
+	src := newFakeReleaseSource()
+	src.releases = append(src.releases, source.Release{TagName: "nightly"})
+	prerelease, contents := newFakeRelease("1.2.0-beta.1", true)
+	src.releases = append(src.releases, prerelease)
+	for url, c := range contents {
+		src.contents[url] = c
+	}
+
+	versions, warnings, err := providers.GetVersions(context.Background(), src, "hashicorp", "terraform-provider-random", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(versions) != 3 || versions[0].Version != "1.2.0-beta.1" || versions[2].Version != "1.0.0" {
+		t.Fatalf("expected versions sorted descending, got %v", versions)
+	}
+	if !versions[0].IsPrerelease || versions[1].IsPrerelease {
+		t.Errorf("unexpected prerelease flags %v", versions)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "nightly") {
+		t.Errorf("expected a warning about the nightly tag, got %v", warnings)
+	}
+}
@@ -0,0 +1,119 @@
+package publish
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/modules"
+	"golang.org/x/exp/slog"
+)
+
+// ModuleVersion is a module version as uploaded to the publishing API.
+type ModuleVersion struct {
+	Namespace string
+	Name      string
+	System    string
+	Version   string
+
+	// Archive is the gzipped tarball of the module's source.
+	Archive []byte
+}
+
+// PublishModule validates the uploaded module archive and stores it. It returns the URL the archive is served from.
+func (p *Publisher) PublishModule(ctx context.Context, mv ModuleVersion) (downloadURL string, err error) {
+	err = xray.Capture(ctx, "publish.module", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", mv.Namespace)
+		xray.AddAnnotation(tracedCtx, "name", mv.Name)
+		xray.AddAnnotation(tracedCtx, "system", mv.System)
+		xray.AddAnnotation(tracedCtx, "version", mv.Version)
+
+		if validateErr := validateAddress(mv.Version, mv.Namespace, mv.Name, mv.System); validateErr != nil {
+			return validateErr
+		}
+		if validateErr := validateModuleArchive(mv.Archive); validateErr != nil {
+			return validateErr
+		}
+
+		repoName := modules.GetRepoName(mv.System, mv.Name)
+
+		index, _, indexErr := p.readIndex(tracedCtx, mv.Namespace, repoName)
+		if indexErr != nil {
+			return indexErr
+		}
+		if indexHasVersion(index, mv.Version) {
+			return ErrVersionExists
+		}
+
+		// the archive is named like the release files of providers, so that it can also be found in a directory listing
+		filename := fmt.Sprintf("%s_%s.tar.gz", repoName, mv.Version)
+		files := map[string][]byte{filename: mv.Archive}
+		upload := uploadID(files)
+		if _, storeErr := p.storeUpload(tracedCtx, mv.Namespace, repoName, mv.Version, upload, files); storeErr != nil {
+			return storeErr
+		}
+
+		if indexErr := p.addToIndex(tracedCtx, mv.Namespace, repoName, mv.Version, upload, []string{filename}); indexErr != nil {
+			return indexErr
+		}
+
+		downloadURL = p.Store.URL(repositoryKey(mv.Namespace, repoName, mv.Version, upload, filename))
+		slog.Info("Published module version", "namespace", mv.Namespace, "name", mv.Name, "system", mv.System, "version", mv.Version)
+		return nil
+	})
+
+	return downloadURL, err
+}
+
+// validateModuleArchive checks that the archive is a gzipped tarball containing at least one .tf file,
+// and that all of its entries are regular files or directories within the directory it is extracted to.
+func validateModuleArchive(archive []byte) error {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return newValidationError("module archive is not gzipped: %s", err)
+	}
+	defer gzipReader.Close()
+
+	hasConfiguration := false
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, nextErr := tarReader.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return newValidationError("module archive is not a valid tarball: %s", nextErr)
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return newValidationError("module archive contains a file outside of the module directory: %s", header.Name)
+		}
+
+		// links could point outside of the module directory, and devices or pipes have no place in a module
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		case tar.TypeXGlobalHeader:
+			// metadata, such as the commit that "git archive" adds
+			continue
+		default:
+			return newValidationError("module archive contains an entry that is not a regular file or directory: %s", header.Name)
+		}
+
+		if header.Typeflag == tar.TypeReg && (strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")) {
+			hasConfiguration = true
+		}
+	}
+
+	if !hasConfiguration {
+		return newValidationError("module archive does not contain any .tf files")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package publish_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/publish"
+	"github.com/opentofu/registry/internal/static"
+)
+
+func moduleArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			t.Fatalf("failed to write contents: %v", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPublishModule(t *testing.T) {
+	publisher, store, _, _ := newTestPublisher(t)
+
+	mv := publish.ModuleVersion{
+		Namespace: "internal",
+		Name:      "network",
+		System:    "aws",
+		Version:   "1.2.0",
+		Archive:   moduleArchive(t, map[string]string{"main.tf": `resource "aws_vpc" "this" {}`, "modules/subnet/main.tf": ""}),
+	}
+
+	downloadURL, err := publisher.PublishModule(context.Background(), mv)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	key := strings.TrimPrefix(downloadURL, "https://artifacts.example.com/")
+	if !strings.HasPrefix(key, "internal/terraform-aws-network/1.2.0/") || !strings.HasSuffix(key, "/terraform-aws-network_1.2.0.tar.gz") {
+		t.Errorf("unexpected download URL %s", downloadURL)
+	}
+	if _, ok := store.objects[key]; !ok {
+		t.Error("expected archive to be stored")
+	}
+	if _, ok := store.objects["internal/terraform-aws-network/index.json"]; !ok {
+		t.Error("expected index to be stored")
+	}
+
+	if _, err := publisher.PublishModule(context.Background(), mv); !errors.Is(err, publish.ErrVersionExists) {
+		t.Errorf("expected ErrVersionExists when publishing twice, got %v", err)
+	}
+}
+
+// TestServePublishedModule checks that published modules can be read back by the static source the "registry"
+// namespace source type is built on.
+func TestServePublishedModule(t *testing.T) {
+	publisher, store, _, _ := newTestPublisher(t)
+
+	_, err := publisher.PublishModule(context.Background(), publish.ModuleVersion{
+		Namespace: "internal",
+		Name:      "network",
+		System:    "aws",
+		Version:   "1.2.0",
+		Archive:   moduleArchive(t, map[string]string{"main.tf": ""}),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := store.objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(contents)
+	}))
+	defer server.Close()
+
+	src, err := static.NewReleaseSource(server.URL+"/internal", static.IndexFormatJSON, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tag, err := modules.ResolveTag(context.Background(), src, "internal", "terraform-aws-network", "1.2.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	downloadURL, err := src.ModuleDownloadURL(context.Background(), "internal", "terraform-aws-network", tag)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := store.objects[strings.TrimPrefix(downloadURL, server.URL+"/")]; !ok {
+		t.Errorf("expected %s to be served from the store", downloadURL)
+	}
+}
+
+// moduleArchiveWithLink builds a module archive containing a main.tf file and a link of the given type.
+func moduleArchiveWithLink(t *testing.T, typeflag byte, name, target string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "main.tf", Mode: 0o644, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("failed to write header: %v", err)
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: name, Linkname: target, Mode: 0o777, Typeflag: typeflag}); err != nil {
+		t.Fatalf("failed to write header: %v", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPublishModuleValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{
+			name:    "not gzipped",
+			archive: []byte("resource \"aws_vpc\" \"this\" {}"),
+		},
+		{
+			name:    "no configuration files",
+			archive: moduleArchive(t, map[string]string{"README.md": "# network"}),
+		},
+		{
+			name:    "file outside of the module directory",
+			archive: moduleArchive(t, map[string]string{"main.tf": "", "../../etc/passwd": ""}),
+		},
+		{
+			name:    "symlink",
+			archive: moduleArchiveWithLink(t, tar.TypeSymlink, "passwd", "/etc/passwd"),
+		},
+		{
+			name:    "hardlink",
+			archive: moduleArchiveWithLink(t, tar.TypeLink, "passwd", "../../etc/passwd"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher, store, _, _ := newTestPublisher(t)
+
+			_, err := publisher.PublishModule(context.Background(), publish.ModuleVersion{
+				Namespace: "internal",
+				Name:      "network",
+				System:    "aws",
+				Version:   "1.2.0",
+				Archive:   tt.archive,
+			})
+
+			var validationErr *publish.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if len(store.objects) != 0 {
+				t.Errorf("expected nothing to be stored, got %d objects", len(store.objects))
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/platform"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/providercache"
+	"github.com/opentofu/registry/internal/providers/types"
+	"golang.org/x/exp/slog"
+)
+
+// ProviderVersion is a provider version as uploaded to the publishing API.
+type ProviderVersion struct {
+	Namespace string
+	Type      string
+	Version   string
+
+	// Files maps the uploaded filenames to their contents. These are expected to be named like GoReleaser names
+	// release assets: "terraform-provider-<type>_<version>_<os>_<arch>.zip" for each platform, along with
+	// "terraform-provider-<type>_<version>_SHA256SUMS", its ".sig" signature and an optional "_manifest.json".
+	Files map[string][]byte
+}
+
+// PublishProvider validates the uploaded provider version, stores its files and adds it to the provider version cache.
+//
+// The zips are validated against the SHA256SUMS file, which in turn must be signed by one of the namespace's
+// public keys unless the unsigned release policy allows unsigned releases.
+func (p *Publisher) PublishProvider(ctx context.Context, pv ProviderVersion, policy providers.UnsignedReleasePolicy) (version *types.CacheVersion, err error) {
+	err = xray.Capture(ctx, "publish.provider", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", pv.Namespace)
+		xray.AddAnnotation(tracedCtx, "type", pv.Type)
+		xray.AddAnnotation(tracedCtx, "version", pv.Version)
+
+		if validateErr := validateAddress(pv.Version, pv.Namespace, pv.Type); validateErr != nil {
+			return validateErr
+		}
+
+		repoName := providers.GetRepoName(pv.Type)
+		cacheKey := fmt.Sprintf("%s/%s", pv.Namespace, pv.Type)
+
+		validated, validateErr := p.validateProviderFiles(pv, repoName, policy)
+		if validateErr != nil {
+			return validateErr
+		}
+
+		cached, cacheErr := p.Cache.GetItem(tracedCtx, cacheKey)
+		if cacheErr != nil {
+			return fmt.Errorf("failed to read provider versions from cache: %w", cacheErr)
+		}
+		if cached != nil {
+			for _, v := range cached.Versions {
+				if v.Version == pv.Version {
+					return ErrVersionExists
+				}
+			}
+		}
+
+		index, _, indexErr := p.readIndex(tracedCtx, pv.Namespace, repoName)
+		if indexErr != nil {
+			return indexErr
+		}
+		if indexHasVersion(index, pv.Version) {
+			return ErrVersionExists
+		}
+
+		upload := uploadID(pv.Files)
+		filenames, storeErr := p.storeUpload(tracedCtx, pv.Namespace, repoName, pv.Version, upload, pv.Files)
+		if storeErr != nil {
+			return storeErr
+		}
+
+		if indexErr := p.addToIndex(tracedCtx, pv.Namespace, repoName, pv.Version, upload, filenames); indexErr != nil {
+			return indexErr
+		}
+
+		version = p.providerCacheVersion(pv, repoName, upload, validated)
+		if storeErr := p.addToCache(tracedCtx, cacheKey, *version, cached); storeErr != nil {
+			return storeErr
+		}
+
+		slog.Info("Published provider version", "namespace", pv.Namespace, "type", pv.Type, "version", pv.Version, "platforms", len(version.DownloadDetails))
+		return nil
+	})
+
+	return version, err
+}
+
+// addToCache adds a published version to the cached versions of its provider, starting from the item read before
+// publishing it. The versions are only stored if no other writer stored versions in the meantime, and are read again
+// otherwise, so that versions published concurrently are not lost.
+func (p *Publisher) addToCache(ctx context.Context, key string, version types.CacheVersion, cached *types.CacheItem) error {
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		versions := types.VersionList{version}
+		var lastUpdated time.Time
+		if cached != nil {
+			// the version may already have been read from the index by a concurrent populate of the provider
+			for _, v := range cached.Versions {
+				if v.Version != version.Version {
+					versions = append(versions, v)
+				}
+			}
+			lastUpdated = cached.LastUpdated
+		}
+
+		err := p.Cache.StoreIfUnchanged(ctx, key, versions, lastUpdated)
+		if !errors.Is(err, providercache.ErrConcurrentUpdate) {
+			if err != nil {
+				return fmt.Errorf("failed to store provider versions in cache: %w", err)
+			}
+			return nil
+		}
+
+		slog.Info("Provider versions were updated concurrently, retrying", "key", key, "attempt", attempt)
+		if cached, err = p.Cache.GetItem(ctx, key); err != nil {
+			return fmt.Errorf("failed to read provider versions from cache: %w", err)
+		}
+	}
+
+	return fmt.Errorf("failed to store provider versions in cache: they were updated concurrently %d times", maxUpdateAttempts)
+}
+
+// validatedProvider holds the details extracted from the uploaded files while validating them.
+type validatedProvider struct {
+	shaSumsName   string
+	signatureName string
+	protocols     []string
+	zips          map[string]platform.Platform
+	shaSums       map[string]string
+}
+
+func (p *Publisher) validateProviderFiles(pv ProviderVersion, repoName string, policy providers.UnsignedReleasePolicy) (*validatedProvider, error) {
+	prefix := fmt.Sprintf("%s_%s", repoName, pv.Version)
+	validated := &validatedProvider{
+		shaSumsName: prefix + "_SHA256SUMS",
+		protocols:   []string{"5.0"},
+		zips:        make(map[string]platform.Platform),
+	}
+	signatureName := validated.shaSumsName + ".sig"
+	manifestName := prefix + "_manifest.json"
+
+	shaSumsContents, ok := pv.Files[validated.shaSumsName]
+	if !ok {
+		return nil, newValidationError("missing %s", validated.shaSumsName)
+	}
+
+	shaSums, err := providers.ParseShaSums(bytes.NewReader(shaSumsContents))
+	if err != nil {
+		return nil, newValidationError("could not parse %s: %s", validated.shaSumsName, err)
+	}
+	validated.shaSums = shaSums
+
+	for filename, contents := range pv.Files {
+		switch filename {
+		case validated.shaSumsName, signatureName, manifestName:
+			continue
+		}
+
+		platformName := strings.TrimSuffix(strings.TrimPrefix(filename, prefix+"_"), ".zip")
+		parts := strings.Split(platformName, "_")
+		if !strings.HasPrefix(filename, prefix+"_") || !strings.HasSuffix(filename, ".zip") || len(parts) != 2 { //nolint:gomnd // os and arch
+			return nil, newValidationError("unexpected file %s, expected %s_<os>_<arch>.zip", filename, prefix)
+		}
+
+		expected, listed := shaSums[filename]
+		if !listed {
+			return nil, newValidationError("%s is not listed in %s", filename, validated.shaSumsName)
+		}
+		actual := sha256.Sum256(contents)
+		if hex.EncodeToString(actual[:]) != expected {
+			return nil, newValidationError("checksum of %s does not match %s", filename, validated.shaSumsName)
+		}
+
+		validated.zips[filename] = platform.Platform{OS: parts[0], Arch: parts[1]}
+	}
+
+	for filename := range shaSums {
+		if _, uploaded := pv.Files[filename]; strings.HasSuffix(filename, ".zip") && !uploaded {
+			return nil, newValidationError("%s is listed in %s but was not uploaded", filename, validated.shaSumsName)
+		}
+	}
+
+	if len(validated.zips) == 0 {
+		return nil, newValidationError("no provider zips uploaded")
+	}
+
+	if signature, signed := pv.Files[signatureName]; signed {
+		publicKeys, keysErr := p.KeysForNamespace(pv.Namespace)
+		if keysErr != nil {
+			return nil, fmt.Errorf("failed to get public keys: %w", keysErr)
+		}
+		if verifyErr := providers.VerifyShaSumsSignature(publicKeys, shaSumsContents, signature); verifyErr != nil {
+			return nil, newValidationError("invalid %s: %s", signatureName, verifyErr)
+		}
+		validated.signatureName = signatureName
+	} else if !policy.AllowsUnsigned() {
+		return nil, newValidationError("missing %s, which is required for this namespace", signatureName)
+	}
+
+	if manifestContents, hasManifest := pv.Files[manifestName]; hasManifest {
+		manifest, manifestErr := providers.ParseManifest(bytes.NewReader(manifestContents))
+		if manifestErr != nil || manifest == nil {
+			return nil, newValidationError("could not parse %s", manifestName)
+		}
+		if len(manifest.Metadata.ProtocolVersions) > 0 {
+			validated.protocols = manifest.Metadata.ProtocolVersions
+		}
+	}
+
+	return validated, nil
+}
+
+func (p *Publisher) providerCacheVersion(pv ProviderVersion, repoName, upload string, validated *validatedProvider) *types.CacheVersion {
+	fileURL := func(filename string) string {
+		return p.Store.URL(repositoryKey(pv.Namespace, repoName, pv.Version, upload, filename))
+	}
+
+	signatureURL := ""
+	if validated.signatureName != "" {
+		signatureURL = fileURL(validated.signatureName)
+	}
+
+	zipNames := make([]string, 0, len(validated.zips))
+	for filename := range validated.zips {
+		zipNames = append(zipNames, filename)
+	}
+	sort.Strings(zipNames)
+
+	downloadDetails := make([]types.CacheVersionDownloadDetails, 0, len(zipNames))
+	for _, filename := range zipNames {
+		downloadDetails = append(downloadDetails, types.CacheVersionDownloadDetails{
+			Platform:            validated.zips[filename],
+			Filename:            filename,
+			DownloadURL:         fileURL(filename),
+			SHASumsURL:          fileURL(validated.shaSumsName),
+			SHASumsSignatureURL: signatureURL,
+			SHASum:              validated.shaSums[filename],
+		})
+	}
+
+	return &types.CacheVersion{
+		Version:         pv.Version,
+		DownloadDetails: downloadDetails,
+		Protocols:       validated.protocols,
+		PublishedAt:     time.Now().UTC(),
+	}
+}
@@ -0,0 +1,264 @@
+package publish_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/publish"
+)
+
+// providerFiles builds a valid set of uploaded files for terraform-provider-dummy, signed with keyRing.
+func providerFiles(t *testing.T, keyRing *crypto.KeyRing, version string) map[string][]byte {
+	t.Helper()
+
+	prefix := "terraform-provider-dummy_" + version
+	files := map[string][]byte{
+		prefix + "_linux_amd64.zip":  []byte("linux binary"),
+		prefix + "_darwin_arm64.zip": []byte("darwin binary"),
+		prefix + "_manifest.json":    []byte(`{"version": 1, "metadata": {"protocol_versions": ["6.0"]}}`),
+	}
+
+	var sums strings.Builder
+	for _, platform := range []string{"darwin_arm64", "linux_amd64"} {
+		filename := fmt.Sprintf("%s_%s.zip", prefix, platform)
+		fmt.Fprintf(&sums, "%s  %s\n", sha256Hex(files[filename]), filename)
+	}
+	files[prefix+"_SHA256SUMS"] = []byte(sums.String())
+
+	signature, err := keyRing.SignDetached(crypto.NewPlainMessage(files[prefix+"_SHA256SUMS"]))
+	if err != nil {
+		t.Fatalf("failed to sign shasums: %v", err)
+	}
+	files[prefix+"_SHA256SUMS.sig"] = signature.GetBinary()
+
+	return files
+}
+
+func TestPublishProvider(t *testing.T) {
+	publisher, store, cache, keyRing := newTestPublisher(t)
+	cache.store("internal/dummy", types.VersionList{{Version: "0.9.0"}})
+
+	version, err := publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+		Namespace: "internal",
+		Type:      "dummy",
+		Version:   "1.0.0",
+		Files:     providerFiles(t, keyRing, "1.0.0"),
+	}, providers.UnsignedReleasePolicyRequire)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(version.Protocols) != 1 || version.Protocols[0] != "6.0" {
+		t.Errorf("expected protocols from the manifest, got %v", version.Protocols)
+	}
+	if len(version.DownloadDetails) != 2 {
+		t.Fatalf("expected 2 platforms, got %d", len(version.DownloadDetails))
+	}
+
+	details := version.GetVersionDetails("linux", "amd64")
+	if details == nil {
+		t.Fatal("expected linux_amd64 download details")
+	}
+	zipKey := strings.TrimPrefix(details.DownloadURL, "https://artifacts.example.com/")
+	if !strings.HasPrefix(zipKey, "internal/terraform-provider-dummy/1.0.0/") || !strings.HasSuffix(zipKey, "/terraform-provider-dummy_1.0.0_linux_amd64.zip") {
+		t.Errorf("unexpected download URL %s", details.DownloadURL)
+	}
+	if details.SHASum != sha256Hex([]byte("linux binary")) {
+		t.Errorf("unexpected shasum %s", details.SHASum)
+	}
+	if !version.IsSigned() {
+		t.Error("expected version to be signed")
+	}
+
+	if contents := store.objects[zipKey]; string(contents) != "linux binary" {
+		t.Errorf("expected zip to be stored, got %q", contents)
+	}
+	if index := string(store.objects["internal/terraform-provider-dummy/index.json"]); !strings.Contains(index, `"version":"1.0.0"`) {
+		t.Errorf("expected version to be added to the index, got %s", index)
+	}
+
+	cached := cache.items["internal/dummy"]
+	if len(cached) != 2 || cached[0].Version != "1.0.0" || cached[1].Version != "0.9.0" {
+		t.Errorf("expected published version to be added to cached versions, got %v", cached)
+	}
+
+	_, err = publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+		Namespace: "internal",
+		Type:      "dummy",
+		Version:   "1.0.0",
+		Files:     providerFiles(t, keyRing, "1.0.0"),
+	}, providers.UnsignedReleasePolicyRequire)
+	if !errors.Is(err, publish.ErrVersionExists) {
+		t.Errorf("expected ErrVersionExists when publishing twice, got %v", err)
+	}
+}
+
+func TestPublishProviderConcurrently(t *testing.T) {
+	publisher, store, cache, keyRing := newTestPublisher(t)
+	cache.store("internal/dummy", types.VersionList{{Version: "0.8.0"}})
+
+	// another version is published between reading the index and the cached versions and storing them again
+	store.beforeConditionalPut = func(key string) {
+		store.beforeConditionalPut = nil
+		store.objects[key] = []byte(`{"versions": [{"version": "0.9.0"}]}`)
+	}
+	cache.beforeStore = func(key string) {
+		cache.beforeStore = nil
+		cache.store(key, append(types.VersionList{{Version: "0.9.0"}}, cache.items[key]...))
+	}
+
+	if _, err := publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+		Namespace: "internal",
+		Type:      "dummy",
+		Version:   "1.0.0",
+		Files:     providerFiles(t, keyRing, "1.0.0"),
+	}, providers.UnsignedReleasePolicyRequire); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	index := string(store.objects["internal/terraform-provider-dummy/index.json"])
+	if !strings.Contains(index, `"version":"1.0.0"`) || !strings.Contains(index, `"version":"0.9.0"`) {
+		t.Errorf("expected both versions in the index, got %s", index)
+	}
+
+	var cached []string
+	for _, v := range cache.items["internal/dummy"] {
+		cached = append(cached, v.Version)
+	}
+	if strings.Join(cached, ",") != "1.0.0,0.9.0,0.8.0" {
+		t.Errorf("expected no cached version to be lost, got %v", cached)
+	}
+}
+
+func TestPublishProviderLosingClaim(t *testing.T) {
+	publisher, store, _, keyRing := newTestPublisher(t)
+
+	winner := providerFiles(t, keyRing, "1.0.0")
+	loser := providerFiles(t, keyRing, "1.0.0")
+	loser["terraform-provider-dummy_1.0.0_manifest.json"] = []byte(`{"version": 1, "metadata": {"protocol_versions": ["5.0"]}}`)
+
+	// the same version is published with other files after this publish checked it was not published yet
+	var published *types.CacheVersion
+	store.beforePut = func(string) {
+		store.beforePut = nil
+		var err error
+		published, err = publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+			Namespace: "internal", Type: "dummy", Version: "1.0.0", Files: winner,
+		}, providers.UnsignedReleasePolicyRequire)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	_, err := publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+		Namespace: "internal", Type: "dummy", Version: "1.0.0", Files: loser,
+	}, providers.UnsignedReleasePolicyRequire)
+	if !errors.Is(err, publish.ErrVersionExists) {
+		t.Fatalf("expected ErrVersionExists, got %v", err)
+	}
+
+	manifestURL := strings.Replace(published.GetVersionDetails("linux", "amd64").DownloadURL, "linux_amd64.zip", "manifest.json", 1)
+	if manifest := string(store.objects[strings.TrimPrefix(manifestURL, "https://artifacts.example.com/")]); !strings.Contains(manifest, `"6.0"`) {
+		t.Errorf("expected the published files not to be overwritten, got manifest %s", manifest)
+	}
+}
+
+func TestPublishProviderValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		version   string
+		modify    func(files map[string][]byte)
+	}{
+		{
+			name:    "invalid version",
+			version: "v1.0.0",
+		},
+		{
+			name: "checksum mismatch",
+			modify: func(files map[string][]byte) {
+				files["terraform-provider-dummy_1.0.0_linux_amd64.zip"] = []byte("tampered")
+			},
+		},
+		{
+			name: "zip not listed in shasums",
+			modify: func(files map[string][]byte) {
+				files["terraform-provider-dummy_1.0.0_windows_amd64.zip"] = []byte("windows binary")
+			},
+		},
+		{
+			name:   "zip listed in shasums but missing",
+			modify: func(files map[string][]byte) { delete(files, "terraform-provider-dummy_1.0.0_linux_amd64.zip") },
+		},
+		{
+			name:   "unexpected file",
+			modify: func(files map[string][]byte) { files["README.md"] = []byte("hello") },
+		},
+		{
+			name:   "missing shasums",
+			modify: func(files map[string][]byte) { delete(files, "terraform-provider-dummy_1.0.0_SHA256SUMS") },
+		},
+		{
+			name:      "signed with a key of another namespace",
+			namespace: "other",
+		},
+		{
+			name:   "missing signature when required",
+			modify: func(files map[string][]byte) { delete(files, "terraform-provider-dummy_1.0.0_SHA256SUMS.sig") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher, store, _, keyRing := newTestPublisher(t)
+
+			files := providerFiles(t, keyRing, "1.0.0")
+			if tt.modify != nil {
+				tt.modify(files)
+			}
+
+			pv := publish.ProviderVersion{Namespace: "internal", Type: "dummy", Version: "1.0.0", Files: files}
+			if tt.namespace != "" {
+				pv.Namespace = tt.namespace
+			}
+			if tt.version != "" {
+				pv.Version = tt.version
+			}
+
+			_, err := publisher.PublishProvider(context.Background(), pv, providers.UnsignedReleasePolicyRequire)
+			var validationErr *publish.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if len(store.objects) != 0 {
+				t.Errorf("expected nothing to be stored, got %d objects", len(store.objects))
+			}
+		})
+	}
+}
+
+func TestPublishUnsignedProvider(t *testing.T) {
+	publisher, _, _, keyRing := newTestPublisher(t)
+
+	files := providerFiles(t, keyRing, "1.0.0")
+	delete(files, "terraform-provider-dummy_1.0.0_SHA256SUMS.sig")
+
+	version, err := publisher.PublishProvider(context.Background(), publish.ProviderVersion{
+		Namespace: "internal",
+		Type:      "dummy",
+		Version:   "1.0.0",
+		Files:     files,
+	}, providers.UnsignedReleasePolicyAllow)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version.IsSigned() {
+		t.Error("expected version to be unsigned")
+	}
+}
@@ -0,0 +1,223 @@
+// Package publish validates and stores provider and module versions that are uploaded directly to the registry,
+// rather than being read from a release source.
+//
+// Published files are laid out in the object store as "<namespace>/<repository>/<version>/<upload>/<filename>", next
+// to a "<namespace>/<repository>/index.json" in the format read by the static package. This means a namespace whose
+// versions are published can be served by a static release source rooted at "<store URL>/<namespace>".
+//
+// The upload directory is derived from the uploaded files, so that concurrent publishes of the same version with
+// different files never overwrite each other's files. Only the publish that adds the version to the index is served.
+package publish
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/static"
+	"github.com/opentofu/registry/internal/storage"
+	"golang.org/x/exp/slog"
+)
+
+// ErrVersionExists is returned when publishing a version that has already been published.
+// Published versions are immutable, as clients may have recorded their checksums.
+var ErrVersionExists = errors.New("version has already been published")
+
+// ValidationError is returned when the uploaded files do not make up a valid version.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+func newValidationError(format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// VersionCache is the subset of the provider version cache used to make published provider versions available
+// without waiting for the cache to be repopulated.
+type VersionCache interface {
+	GetItem(ctx context.Context, key string) (*types.CacheItem, error)
+	// StoreIfUnchanged stores the versions only if the item was last updated at lastUpdated (zero if there was no item),
+	// and returns providercache.ErrConcurrentUpdate otherwise.
+	StoreIfUnchanged(ctx context.Context, key string, versions types.VersionList, lastUpdated time.Time) error
+}
+
+type Publisher struct {
+	Store storage.ObjectStore
+	Cache VersionCache
+
+	// KeysForNamespace returns the public keys that provider signatures are verified against.
+	KeysForNamespace func(namespace string) ([]types.GPGPublicKey, error)
+}
+
+func NewPublisher(store storage.ObjectStore, cache VersionCache) *Publisher {
+	return &Publisher{
+		Store:            store,
+		Cache:            cache,
+		KeysForNamespace: providers.KeysForNamespace,
+	}
+}
+
+//nolint:gochecknoglobals // These should be treated as constants.
+var (
+	namePattern    = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+	versionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+)
+
+func validateAddress(version string, names ...string) error {
+	for _, name := range names {
+		if !namePattern.MatchString(name) {
+			return newValidationError("invalid name %q", name)
+		}
+	}
+	if !versionPattern.MatchString(version) {
+		return newValidationError("invalid version %q, expected a semantic version without a \"v\" prefix", version)
+	}
+	return nil
+}
+
+func repositoryKey(namespace, repoName string, elem ...string) string {
+	return path.Join(append([]string{namespace, repoName}, elem...)...)
+}
+
+// uploadID identifies a set of uploaded files by their names and contents.
+func uploadID(files map[string][]byte) string {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	hash := sha256.New()
+	for _, filename := range filenames {
+		fmt.Fprintf(hash, "%s\x00%d\x00", filename, len(files[filename]))
+		hash.Write(files[filename])
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// storeUpload stores the uploaded files of a version under their upload directory, returning their sorted names.
+// The files are not served until the version is added to the index.
+func (p *Publisher) storeUpload(ctx context.Context, namespace, repoName, version, upload string, files map[string][]byte) ([]string, error) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		key := repositoryKey(namespace, repoName, version, upload, filename)
+		if err := p.Store.Put(ctx, key, files[filename], contentType(filename)); err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", filename, err)
+		}
+	}
+	return filenames, nil
+}
+
+// maxUpdateAttempts bounds how often the index and the cached versions are re-read and updated again when another
+// version of the same repository is published concurrently.
+const maxUpdateAttempts = 5
+
+// readIndex reads the index of a repository from the store along with its entity tag, returning an empty index and
+// no entity tag if there is none yet.
+func (p *Publisher) readIndex(ctx context.Context, namespace, repoName string) (*static.Index, string, error) {
+	contents, etag, err := p.Store.GetWithETag(ctx, repositoryKey(namespace, repoName, static.IndexFileName))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	index := &static.Index{}
+	if contents == nil {
+		return index, "", nil
+	}
+	if err := json.Unmarshal(contents, index); err != nil {
+		return nil, "", fmt.Errorf("failed to parse index: %w", err)
+	}
+	return index, etag, nil
+}
+
+// addToIndex adds a version to the index of a repository, pointing at the files in its upload directory, and fails
+// with ErrVersionExists if it is already listed. This claims the version: files uploaded by a concurrent publish that
+// loses the claim are never referenced. The index is only replaced if it was not changed since it was read, and is
+// read again otherwise, so that versions published concurrently are not lost.
+func (p *Publisher) addToIndex(ctx context.Context, namespace, repoName, version, upload string, filenames []string) error {
+	assets := make([]static.IndexAsset, 0, len(filenames))
+	for _, filename := range filenames {
+		assets = append(assets, static.IndexAsset{
+			Name: filename,
+			URL:  path.Join(version, upload, filename),
+		})
+	}
+
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		index, etag, err := p.readIndex(ctx, namespace, repoName)
+		if err != nil {
+			return err
+		}
+		if indexHasVersion(index, version) {
+			return ErrVersionExists
+		}
+
+		// the newest version is listed first, like in the other release sources
+		index.Versions = append([]static.IndexVersion{{
+			Version:    version,
+			CreatedAt:  time.Now().UTC(),
+			Prerelease: strings.Contains(version, "-"),
+			Assets:     assets,
+		}}, index.Versions...)
+
+		contents, err := json.Marshal(index)
+		if err != nil {
+			return fmt.Errorf("failed to marshal index: %w", err)
+		}
+
+		err = p.Store.PutIfMatch(ctx, repositoryKey(namespace, repoName, static.IndexFileName), contents, "application/json", etag)
+		if errors.Is(err, storage.ErrPreconditionFailed) {
+			slog.Info("Index was changed concurrently, retrying", "namespace", namespace, "repository", repoName, "attempt", attempt)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store index: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("failed to store index: it was changed concurrently %d times", maxUpdateAttempts)
+}
+
+func indexHasVersion(index *static.Index, version string) bool {
+	for _, v := range index.Versions {
+		if strings.TrimPrefix(v.Version, "v") == version {
+			return true
+		}
+	}
+	return false
+}
+
+func contentType(filename string) string {
+	switch {
+	case strings.HasSuffix(filename, ".zip"):
+		return "application/zip"
+	case strings.HasSuffix(filename, ".tar.gz"), strings.HasSuffix(filename, ".tgz"):
+		return "application/gzip"
+	case strings.HasSuffix(filename, ".json"):
+		return "application/json"
+	case strings.HasSuffix(filename, ".sig"):
+		return "application/pgp-signature"
+	default:
+		return "text/plain"
+	}
+}
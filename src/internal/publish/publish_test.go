@@ -0,0 +1,127 @@
+package publish_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/registry/internal/providers/providercache"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/publish"
+	"github.com/opentofu/registry/internal/storage"
+)
+
+type memoryStore struct {
+	objects map[string][]byte
+
+	// beforePut is called before an unconditional write, to simulate concurrent writes.
+	beforePut func(key string)
+	// beforeConditionalPut is called before checking the condition of a conditional write, to simulate concurrent writes.
+	beforeConditionalPut func(key string)
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	return s.objects[key], nil
+}
+
+func (s *memoryStore) GetWithETag(_ context.Context, key string) ([]byte, string, error) {
+	contents, ok := s.objects[key]
+	if !ok {
+		return nil, "", nil
+	}
+	return contents, sha256Hex(contents), nil
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, contents []byte, _ string) error {
+	if s.beforePut != nil {
+		s.beforePut(key)
+	}
+	s.objects[key] = contents
+	return nil
+}
+
+func (s *memoryStore) PutIfMatch(ctx context.Context, key string, contents []byte, contentType, etag string) error {
+	if s.beforeConditionalPut != nil {
+		s.beforeConditionalPut(key)
+	}
+	if _, current, _ := s.GetWithETag(ctx, key); current != etag {
+		return storage.ErrPreconditionFailed
+	}
+	s.objects[key] = contents
+	return nil
+}
+
+func (s *memoryStore) URL(key string) string {
+	return "https://artifacts.example.com/" + key
+}
+
+type memoryCache struct {
+	items       map[string]types.VersionList
+	lastUpdated map[string]time.Time
+
+	// beforeStore is called before checking the condition of a conditional store, to simulate concurrent writes.
+	beforeStore func(key string)
+}
+
+func (c *memoryCache) GetItem(_ context.Context, key string) (*types.CacheItem, error) {
+	versions, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+	return &types.CacheItem{Provider: key, Versions: versions, LastUpdated: c.lastUpdated[key]}, nil
+}
+
+func (c *memoryCache) StoreIfUnchanged(_ context.Context, key string, versions types.VersionList, lastUpdated time.Time) error {
+	if c.beforeStore != nil {
+		c.beforeStore(key)
+	}
+	if !c.lastUpdated[key].Equal(lastUpdated) {
+		return providercache.ErrConcurrentUpdate
+	}
+	c.store(key, versions)
+	return nil
+}
+
+func (c *memoryCache) store(key string, versions types.VersionList) {
+	c.items[key] = versions
+	// every store is at a distinct time, even if the clock did not advance
+	c.lastUpdated[key] = c.lastUpdated[key].Add(time.Second)
+}
+
+func newTestPublisher(t *testing.T) (*publish.Publisher, *memoryStore, *memoryCache, *crypto.KeyRing) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey("Test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatalf("failed to armor key: %v", err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+
+	store := &memoryStore{objects: make(map[string][]byte)}
+	cache := &memoryCache{items: make(map[string]types.VersionList), lastUpdated: make(map[string]time.Time)}
+
+	publisher := publish.NewPublisher(store, cache)
+	publisher.KeysForNamespace = func(namespace string) ([]types.GPGPublicKey, error) {
+		if namespace != "internal" {
+			return nil, nil
+		}
+		return []types.GPGPublicKey{{KeyID: key.GetHexKeyID(), ASCIIArmor: armored}}, nil
+	}
+
+	return publisher, store, cache, keyRing
+}
+
+func sha256Hex(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
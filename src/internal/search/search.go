@@ -0,0 +1,249 @@
+// Package search implements an in-memory full-text index of providers, built from the namespace indexes
+// recorded by the namespace crawler.
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/opentofu/registry/internal/providers/types"
+)
+
+const (
+	TierOfficial  = "official"
+	TierPartner   = "partner"
+	TierCommunity = "community"
+)
+
+// Weights of a query term matching the different fields of a provider.
+const (
+	weightExactName   = 20
+	weightNameTerm    = 8
+	weightNamePrefix  = 4
+	weightTopic       = 3
+	weightDescription = 1
+)
+
+// ParseTier validates a tier name.
+func ParseTier(tier string) (string, error) {
+	switch tier {
+	case TierOfficial, TierPartner, TierCommunity:
+		return tier, nil
+	default:
+		return "", fmt.Errorf("unknown tier %q, expected %q, %q or %q", tier, TierOfficial, TierPartner, TierCommunity)
+	}
+}
+
+// tierRank orders tiers for breaking ties between equally relevant results.
+func tierRank(tier string) int {
+	switch tier {
+	case TierOfficial:
+		return 0
+	case TierPartner:
+		return 1
+	default:
+		return 2 //nolint:gomnd // community and unknown tiers rank last
+	}
+}
+
+// Document is a single provider in the index.
+type Document struct {
+	Namespace   string
+	Type        string
+	Description string
+	SourceURL   string
+	Topics      []string
+	Tier        string
+}
+
+// Query describes a search. Empty fields do not restrict the results.
+type Query struct {
+	Text      string
+	Namespace string
+	Tier      string
+	Offset    int
+	Limit     int
+}
+
+// Hit is a single search result.
+type Hit struct {
+	Document
+	Score int
+}
+
+// Results is a single page of search results.
+type Results struct {
+	Hits  []Hit
+	Total int // The total number of matching documents, across all pages.
+}
+
+type posting struct {
+	document int
+	weight   int
+}
+
+// Index is an inverted index over the name, topics and description of providers.
+type Index struct {
+	documents []Document
+	terms     map[string][]posting
+	nameTerms []string // the distinct terms of all provider names, for prefix matching
+}
+
+// NewIndex builds an index of the providers in the given namespace indexes. The tier of each namespace
+// is looked up with tierFor.
+func NewIndex(namespaces []types.NamespaceIndex, tierFor func(namespace string) string) *Index {
+	index := &Index{terms: make(map[string][]posting)}
+	nameTerms := make(map[string]bool)
+
+	for _, namespace := range namespaces {
+		for _, provider := range namespace.Providers {
+			id := len(index.documents)
+			index.documents = append(index.documents, Document{
+				Namespace:   namespace.Namespace,
+				Type:        provider.Type,
+				Description: provider.Description,
+				SourceURL:   provider.SourceURL,
+				Topics:      provider.Topics,
+				Tier:        tierFor(namespace.Namespace),
+			})
+
+			// each term only counts once per document, with the weight of the most relevant field it appears in
+			weights := make(map[string]int)
+			addTerms := func(text string, weight int) {
+				for _, term := range tokenize(text) {
+					if weights[term] < weight {
+						weights[term] = weight
+					}
+				}
+			}
+			addTerms(provider.Description, weightDescription)
+			for _, topic := range provider.Topics {
+				addTerms(topic, weightTopic)
+			}
+			addTerms(provider.Type, weightNameTerm)
+			for _, term := range tokenize(provider.Type) {
+				nameTerms[term] = true
+			}
+
+			for term, weight := range weights {
+				index.terms[term] = append(index.terms[term], posting{document: id, weight: weight})
+			}
+		}
+	}
+
+	for term := range nameTerms {
+		index.nameTerms = append(index.nameTerms, term)
+	}
+	sort.Strings(index.nameTerms)
+
+	return index
+}
+
+// Len returns the number of documents in the index.
+func (i *Index) Len() int {
+	return len(i.documents)
+}
+
+// Search returns the documents matching the query, most relevant first. Every term of the query
+// must match a document for it to be returned. Without any query text, all documents matching the
+// filters are returned by name.
+func (i *Index) Search(query Query) Results {
+	scores := make(map[int]int)
+
+	queryTerms := tokenize(query.Text)
+	if len(queryTerms) == 0 {
+		for id := range i.documents {
+			scores[id] = 0
+		}
+	}
+
+	for n, term := range queryTerms {
+		termScores := i.scoreTerm(term)
+		if n == 0 {
+			scores = termScores
+			continue
+		}
+
+		for id := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] += termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	normalizedQuery := strings.Join(queryTerms, "-")
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		document := i.documents[id]
+		if query.Namespace != "" && !strings.EqualFold(document.Namespace, query.Namespace) {
+			continue
+		}
+		if query.Tier != "" && document.Tier != query.Tier {
+			continue
+		}
+		if normalizedQuery != "" && strings.Join(tokenize(document.Type), "-") == normalizedQuery {
+			score += weightExactName
+		}
+		hits = append(hits, Hit{Document: document, Score: score})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		if tierRank(hits[a].Tier) != tierRank(hits[b].Tier) {
+			return tierRank(hits[a].Tier) < tierRank(hits[b].Tier)
+		}
+		if hits[a].Type != hits[b].Type {
+			return hits[a].Type < hits[b].Type
+		}
+		return hits[a].Namespace < hits[b].Namespace
+	})
+
+	results := Results{Total: len(hits)}
+	if query.Offset < len(hits) {
+		end := len(hits)
+		if query.Limit > 0 && query.Offset+query.Limit < end {
+			end = query.Offset + query.Limit
+		}
+		results.Hits = hits[query.Offset:end]
+	}
+	return results
+}
+
+// scoreTerm returns the score of every document matching a single query term. Terms also match
+// provider names they are a prefix of, so that partial names still find the provider.
+func (i *Index) scoreTerm(term string) map[int]int {
+	scores := make(map[int]int)
+	for _, p := range i.terms[term] {
+		scores[p.document] = p.weight
+	}
+
+	start := sort.SearchStrings(i.nameTerms, term)
+	for _, nameTerm := range i.nameTerms[start:] {
+		if !strings.HasPrefix(nameTerm, term) {
+			break
+		}
+		if nameTerm == term {
+			continue
+		}
+		for _, p := range i.terms[nameTerm] {
+			if p.weight == weightNameTerm && scores[p.document] < weightNamePrefix {
+				scores[p.document] = weightNamePrefix
+			}
+		}
+	}
+	return scores
+}
+
+// tokenize splits text into lowercase terms of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,120 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/search"
+)
+
+func newTestIndex() *search.Index {
+	namespaces := []types.NamespaceIndex{
+		{
+			Namespace: "opentofu",
+			Providers: []types.IndexedProvider{
+				{Type: "aws", Description: "Lifecycle management of AWS resources", Topics: []string{"aws", "cloud"}},
+				{Type: "random", Description: "Generates random values"},
+			},
+		},
+		{
+			Namespace: "someone",
+			Providers: []types.IndexedProvider{
+				{Type: "aws-extras", Description: "Extra resources for AWS"},
+				{Type: "cloudflare", Description: "Manage Cloudflare zones", Topics: []string{"dns"}},
+			},
+		},
+	}
+
+	return search.NewIndex(namespaces, func(namespace string) string {
+		if namespace == "opentofu" {
+			return search.TierOfficial
+		}
+		return search.TierCommunity
+	})
+}
+
+func hitIDs(results search.Results) []string {
+	ids := make([]string, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		ids = append(ids, hit.Namespace+"/"+hit.Type)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	index := newTestIndex()
+
+	tests := []struct {
+		name     string
+		query    search.Query
+		expected []string
+	}{
+		{
+			name:     "exact name ranks first",
+			query:    search.Query{Text: "aws"},
+			expected: []string{"opentofu/aws", "someone/aws-extras"},
+		},
+		{
+			name:     "name prefix",
+			query:    search.Query{Text: "cloudf"},
+			expected: []string{"someone/cloudflare"},
+		},
+		{
+			name:     "topic",
+			query:    search.Query{Text: "DNS"},
+			expected: []string{"someone/cloudflare"},
+		},
+		{
+			name:     "all terms must match",
+			query:    search.Query{Text: "extra aws"},
+			expected: []string{"someone/aws-extras"},
+		},
+		{
+			name:     "namespace filter",
+			query:    search.Query{Text: "aws", Namespace: "someone"},
+			expected: []string{"someone/aws-extras"},
+		},
+		{
+			name:     "tier filter without text",
+			query:    search.Query{Tier: search.TierOfficial},
+			expected: []string{"opentofu/aws", "opentofu/random"},
+		},
+		{
+			name:     "no match",
+			query:    search.Query{Text: "kubernetes"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitIDs(index.Search(tt.query))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	index := newTestIndex()
+
+	results := index.Search(search.Query{Offset: 1, Limit: 2})
+	if results.Total != 4 {
+		t.Errorf("expected 4 results in total, got %d", results.Total)
+	}
+	got := hitIDs(results)
+	if len(got) != 2 || got[0] != "opentofu/random" || got[1] != "someone/aws-extras" {
+		t.Errorf("unexpected page %v", got)
+	}
+
+	results = index.Search(search.Query{Offset: 10, Limit: 2})
+	if len(results.Hits) != 0 || results.Total != 4 {
+		t.Errorf("expected an empty page of 4 results, got %v of %d", hitIDs(results), results.Total)
+	}
+}
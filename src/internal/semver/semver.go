@@ -0,0 +1,109 @@
+// Package semver validates and orders version numbers following Semantic Versioning 2.0.0 (https://semver.org).
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// The regular expression suggested by the specification, see https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+//
+//nolint:gochecknoglobals // This should be treated as a constant.
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Normalize validates a tag name as a semantic version, optionally prefixed with "v", and returns the version without the prefix.
+func Normalize(tag string) (string, error) {
+	v := strings.TrimPrefix(tag, "v")
+	if !semverPattern.MatchString(v) {
+		return "", fmt.Errorf("%q is not a valid semantic version", tag)
+	}
+	return v, nil
+}
+
+// IsPrerelease returns true if the given valid semantic version has a prerelease part, such as "1.0.0-beta1".
+func IsPrerelease(v string) bool {
+	matches := semverPattern.FindStringSubmatch(strings.TrimPrefix(v, "v"))
+	return matches != nil && matches[4] != ""
+}
+
+// SortDescending sorts items from the highest to the lowest version, as returned by versionOf.
+// Items whose version cannot be parsed are moved to the end, keeping their order.
+func SortDescending[T any](items []T, versionOf func(T) string) {
+	parsed := make([]*version.Version, len(items))
+	for i, item := range items {
+		if v, err := version.NewVersion(versionOf(item)); err == nil {
+			parsed[i] = v
+		}
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		va, vb := parsed[indexes[a]], parsed[indexes[b]]
+		if va == nil || vb == nil {
+			return vb == nil && va != nil
+		}
+		return va.GreaterThan(vb)
+	})
+
+	sorted := make([]T, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+}
+
+// Constraints is a set of version constraints as written in OpenTofu configurations, such as "~> 5.0" or ">= 1.2.0, < 2.0.0".
+// As in OpenTofu, prerelease versions only match constraints naming a prerelease of the same version, such as "= 1.0.0-beta1".
+type Constraints struct {
+	constraints version.Constraints
+}
+
+// ParseConstraints parses a comma separated list of version constraints. An empty string matches every version but prereleases.
+func ParseConstraints(s string) (Constraints, error) {
+	if strings.TrimSpace(s) == "" {
+		return Constraints{}, nil
+	}
+
+	constraints, err := version.NewConstraint(s)
+	if err != nil {
+		return Constraints{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+	}
+	return Constraints{constraints: constraints}, nil
+}
+
+// Check returns true if the given version satisfies all the constraints. Versions that cannot be parsed never match.
+func (c Constraints) Check(v string) bool {
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return false
+	}
+	if c.constraints == nil {
+		return parsed.Prerelease() == ""
+	}
+	return c.constraints.Check(parsed)
+}
+
+// Highest returns the index of the highest version matching the constraints, as returned by versionOf, or -1 if none matches.
+func Highest[T any](items []T, c Constraints, versionOf func(T) string) int {
+	highest := -1
+	var highestVersion *version.Version
+	for i, item := range items {
+		if !c.Check(versionOf(item)) {
+			continue
+		}
+
+		// Check has already parsed the version successfully
+		v, _ := version.NewVersion(versionOf(item))
+		if highestVersion == nil || v.GreaterThan(highestVersion) {
+			highest, highestVersion = i, v
+		}
+	}
+	return highest
+}
@@ -0,0 +1,83 @@
+package semver_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/registry/internal/semver"
+)
+
+func TestNormalize(t *testing.T) {
+	valid := map[string]string{
+		"1.0.0":                  "1.0.0",
+		"v1.2.3":                 "1.2.3",
+		"v2.0.0-beta.1":          "2.0.0-beta.1",
+		"1.0.0-rc1+build.5":      "1.0.0-rc1+build.5",
+		"0.0.1-alpha-2":          "0.0.1-alpha-2",
+		"10.20.30+20231001.sha1": "10.20.30+20231001.sha1",
+	}
+	for tag, expected := range valid {
+		got, err := semver.Normalize(tag)
+		if err != nil || got != expected {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tag, got, err, expected)
+		}
+	}
+
+	for _, tag := range []string{"", "latest", "1.0", "v1", "01.0.0", "1.0.0-", "1.0.0-01", "vv1.0.0", "release-1.0.0", "1.0.0.0"} {
+		if got, err := semver.Normalize(tag); err == nil {
+			t.Errorf("expected Normalize(%q) to fail, got %q", tag, got)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for v, expected := range map[string]bool{"1.0.0": false, "1.0.0-beta1": true, "v2.0.0-rc.1": true, "1.0.0+build": false, "invalid": false} {
+		if got := semver.IsPrerelease(v); got != expected {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", v, got, expected)
+		}
+	}
+}
+
+func TestSortDescending(t *testing.T) {
+	versions := []string{"1.2.0", "invalid", "1.10.0", "2.0.0-beta1", "2.0.0", "1.9.1"}
+	semver.SortDescending(versions, func(v string) string { return v })
+
+	expected := []string{"2.0.0", "2.0.0-beta1", "1.10.0", "1.9.1", "1.2.0", "invalid"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("SortDescending() = %v, want %v", versions, expected)
+	}
+}
+
+func TestHighest(t *testing.T) {
+	versions := []string{"4.9.0", "5.0.0", "5.2.1", "5.3.0-beta1", "6.0.0", "invalid"}
+	identity := func(v string) string { return v }
+
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{"", "6.0.0"},
+		{"~> 5.0", "5.2.1"},
+		{">= 4.0, < 5.0", "4.9.0"},
+		{"= 5.3.0-beta1", "5.3.0-beta1"},
+		{"~> 7.0", ""},
+	}
+	for _, tt := range tests {
+		constraints, err := semver.ParseConstraints(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraints(%q) failed: %v", tt.constraint, err)
+		}
+
+		got := ""
+		if i := semver.Highest(versions, constraints, identity); i >= 0 {
+			got = versions[i]
+		}
+		if got != tt.expected {
+			t.Errorf("Highest(%q) = %q, want %q", tt.constraint, got, tt.expected)
+		}
+	}
+
+	if _, err := semver.ParseConstraints("~> banana"); err == nil {
+		t.Error("expected an invalid constraint to fail")
+	}
+}
@@ -0,0 +1,100 @@
+package source
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// WithTagPrefix returns a release source that only sees the releases and tags starting with the given prefix,
+// such as "aws/" for a provider released from a monorepo, with the prefix removed from their names.
+// Tags passed back to the source, for example to ModuleDownloadURL, get the prefix added again.
+// The source is returned as is if the prefix is empty.
+func WithTagPrefix(src ReleaseSource, prefix string) ReleaseSource {
+	if prefix == "" {
+		return src
+	}
+
+	prefixed := &tagPrefixSource{ReleaseSource: src, prefix: prefix}
+	// modules are versioned by tags only if the underlying source supports them
+	if tagSrc, ok := src.(TagSource); ok {
+		return &tagPrefixTagSource{tagPrefixSource: prefixed, tagSource: tagSrc}
+	}
+	return prefixed
+}
+
+type tagPrefixSource struct {
+	ReleaseSource
+	prefix string
+}
+
+func (s *tagPrefixSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) ([]Release, error) {
+	releases, err := s.ReleaseSource.FetchReleases(ctx, namespace, name, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.withoutPrefix(releases), nil
+}
+
+// FetchReleasesSkipping also skips the tags without the prefix, if the underlying source can skip tags.
+func (s *tagPrefixSource) FetchReleasesSkipping(ctx context.Context, namespace, name string, since *time.Time, known func(tag string) bool) ([]Release, error) {
+	releases, err := FetchNewReleases(ctx, s.ReleaseSource, namespace, name, since, func(tag string) bool {
+		tag, ok := strings.CutPrefix(tag, s.prefix)
+		return !ok || known(tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withoutPrefix(releases), nil
+}
+
+// withoutPrefix returns the releases whose tags start with the prefix, with the prefix removed.
+func (s *tagPrefixSource) withoutPrefix(releases []Release) []Release {
+	var prefixed []Release
+	for _, release := range releases {
+		if tag, ok := strings.CutPrefix(release.TagName, s.prefix); ok {
+			release.TagName = tag
+			prefixed = append(prefixed, release)
+		}
+	}
+	return prefixed
+}
+
+// FindRelease lists all releases, as the underlying source can only find releases tagged "v<version>" without a prefix.
+func (s *tagPrefixSource) FindRelease(ctx context.Context, namespace, name, version string) (*Release, error) {
+	releases, err := s.FetchReleases(ctx, namespace, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range releases {
+		if releases[i].TagName == "v"+version {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *tagPrefixSource) ModuleDownloadURL(ctx context.Context, namespace, name, tag string) (string, error) {
+	return s.ReleaseSource.ModuleDownloadURL(ctx, namespace, name, s.prefix+tag)
+}
+
+type tagPrefixTagSource struct {
+	*tagPrefixSource
+	tagSource TagSource
+}
+
+func (s *tagPrefixTagSource) FetchTags(ctx context.Context, namespace, name string) ([]string, error) {
+	tags, err := s.tagSource.FetchTags(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixed []string
+	for _, tag := range tags {
+		if tag, ok := strings.CutPrefix(tag, s.prefix); ok {
+			prefixed = append(prefixed, tag)
+		}
+	}
+	return prefixed, nil
+}
@@ -0,0 +1,109 @@
+package source_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry/internal/source"
+)
+
+// fakeTagSource serves releases and tags with the given names.
+type fakeTagSource struct {
+	tags []string
+}
+
+func (f *fakeTagSource) RepositoryExists(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeTagSource) FetchReleases(_ context.Context, _, _ string, _ *time.Time) ([]source.Release, error) {
+	releases := make([]source.Release, 0, len(f.tags))
+	for _, tag := range f.tags {
+		releases = append(releases, source.Release{TagName: tag})
+	}
+	return releases, nil
+}
+
+func (f *fakeTagSource) FindRelease(_ context.Context, _, _, _ string) (*source.Release, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (f *fakeTagSource) DownloadAsset(_ context.Context, _ source.Asset) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (f *fakeTagSource) ModuleDownloadURL(_ context.Context, namespace, name, tag string) (string, error) {
+	return fmt.Sprintf("git::https://example.com/%s/%s?ref=%s", namespace, name, tag), nil
+}
+
+func (f *fakeTagSource) FetchTags(_ context.Context, _, _ string) ([]string, error) {
+	return f.tags, nil
+}
+
+func TestWithTagPrefix(t *testing.T) {
+	fake := &fakeTagSource{tags: []string{"vpc/v1.0.0", "vpc/v1.1.0", "subnet/v2.0.0", "v3.0.0"}}
+	if src := source.WithTagPrefix(fake, ""); src != fake {
+		t.Fatal("expected the source to be returned as is without a prefix")
+	}
+
+	src := source.WithTagPrefix(fake, "vpc/")
+	ctx := context.Background()
+
+	tagSrc, ok := src.(source.TagSource)
+	if !ok {
+		t.Fatal("expected the prefixed source to list tags")
+	}
+	tags, err := tagSrc.FetchTags(ctx, "acme", "infrastructure")
+	if err != nil || !reflect.DeepEqual(tags, []string{"v1.0.0", "v1.1.0"}) {
+		t.Errorf("unexpected tags %v (error: %v)", tags, err)
+	}
+
+	release, err := src.FindRelease(ctx, "acme", "infrastructure", "1.1.0")
+	if err != nil || release == nil || release.TagName != "v1.1.0" {
+		t.Errorf("unexpected release %v (error: %v)", release, err)
+	}
+	if release, _ := src.FindRelease(ctx, "acme", "infrastructure", "2.0.0"); release != nil {
+		t.Errorf("expected releases of other prefixes to be hidden, got %v", release)
+	}
+
+	url, err := src.ModuleDownloadURL(ctx, "acme", "infrastructure", "v1.1.0")
+	if err != nil || url != "git::https://example.com/acme/infrastructure?ref=vpc/v1.1.0" {
+		t.Errorf("unexpected download URL %s (error: %v)", url, err)
+	}
+}
+
+// fakeSkippingSource reads the releases of the tags that are not known, recording the tags it read.
+type fakeSkippingSource struct {
+	fakeTagSource
+	read []string
+}
+
+func (f *fakeSkippingSource) FetchReleasesSkipping(_ context.Context, _, _ string, _ *time.Time, known func(tag string) bool) ([]source.Release, error) {
+	var releases []source.Release
+	for _, tag := range f.tags {
+		if !known(tag) {
+			f.read = append(f.read, tag)
+			releases = append(releases, source.Release{TagName: tag})
+		}
+	}
+	return releases, nil
+}
+
+func TestWithTagPrefixSkipping(t *testing.T) {
+	fake := &fakeSkippingSource{fakeTagSource: fakeTagSource{tags: []string{"vpc/v1.0.0", "vpc/v1.1.0", "subnet/v2.0.0"}}}
+	src := source.WithTagPrefix(fake, "vpc/")
+
+	releases, err := source.FetchNewReleases(context.Background(), src, "acme", "infrastructure", nil, func(tag string) bool {
+		return tag == "v1.0.0"
+	})
+	if err != nil || len(releases) != 1 || releases[0].TagName != "v1.1.0" {
+		t.Errorf("expected only the new release without its prefix, got %v (error: %v)", releases, err)
+	}
+	if !reflect.DeepEqual(fake.read, []string{"vpc/v1.1.0"}) {
+		t.Errorf("expected known tags and tags of other prefixes to be skipped, read %v", fake.read)
+	}
+}
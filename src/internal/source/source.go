@@ -0,0 +1,117 @@
+// Package source defines where provider and module releases are read from, independent of the hosting service.
+package source
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// ReleaseSource is implemented by every hosting backend that the registry can read releases from.
+// Namespaces and names are passed exactly as they are used by the backend (for example the GitHub owner and repository name).
+type ReleaseSource interface {
+	// RepositoryExists checks whether the given repository exists on the backend.
+	RepositoryExists(ctx context.Context, namespace, name string) (bool, error)
+
+	// FetchReleases returns the published releases of a repository, newest first.
+	// If since is provided, only releases created after that time need to be returned.
+	FetchReleases(ctx context.Context, namespace, name string, since *time.Time) ([]Release, error)
+
+	// FindRelease returns the release tagged "v<version>", or nil if no such release exists.
+	FindRelease(ctx context.Context, namespace, name, version string) (*Release, error)
+
+	// DownloadAsset opens the contents of a release asset. The caller must close the returned reader.
+	DownloadAsset(ctx context.Context, asset Asset) (io.ReadCloser, error)
+
+	// ModuleDownloadURL returns the location OpenTofu should fetch a module from at the given tag,
+	// as returned in the X-Terraform-Get header.
+	ModuleDownloadURL(ctx context.Context, namespace, name, tag string) (string, error)
+}
+
+// TagSource is implemented by release sources that can list plain git tags. Modules hosted on
+// such a source are versioned by their tags rather than by their releases.
+type TagSource interface {
+	// FetchTags returns the names of all tags in a repository.
+	FetchTags(ctx context.Context, namespace, name string) ([]string, error)
+}
+
+// MetadataSource is implemented by release sources that can describe a repository, for example to show
+// its description and web page in the provider metadata endpoints.
+type MetadataSource interface {
+	// RepositoryMetadata returns the metadata of a repository, or nil if the repository does not exist.
+	RepositoryMetadata(ctx context.Context, namespace, name string) (*RepositoryMetadata, error)
+}
+
+// RepositoryLister is implemented by release sources that can list the repositories of a namespace,
+// which is used to discover providers before anyone requests them.
+type RepositoryLister interface {
+	// ListRepositories returns all public repositories owned by the namespace.
+	ListRepositories(ctx context.Context, namespace string) ([]RepositoryMetadata, error)
+}
+
+// DownloadURLResolver is implemented by release sources whose asset download URLs cannot be fetched by clients as is,
+// for example because they require credentials. Such URLs are resolved to one clients can download from whenever
+// they are served, as the resolved URLs may expire.
+type DownloadURLResolver interface {
+	// ResolveDownloadURL returns the URL clients should download the asset at downloadURL from.
+	ResolveDownloadURL(ctx context.Context, downloadURL string) (string, error)
+}
+
+// ReleaseSkipper is implemented by release sources that read each release with its own request, such as OCI
+// registries reading the manifest of each tag. Such sources can skip the releases that are already known.
+type ReleaseSkipper interface {
+	// FetchReleasesSkipping is like FetchReleases, but does not read the releases of the tags that known returns true for.
+	FetchReleasesSkipping(ctx context.Context, namespace, name string, since *time.Time, known func(tag string) bool) ([]Release, error)
+}
+
+// FetchNewReleases returns the releases of a repository like FetchReleases, skipping the tags that known returns true
+// for if the source is a ReleaseSkipper. Other sources may still return the releases of known tags.
+func FetchNewReleases(ctx context.Context, src ReleaseSource, namespace, name string, since *time.Time, known func(tag string) bool) ([]Release, error) {
+	if skipper, ok := src.(ReleaseSkipper); ok && known != nil {
+		return skipper.FetchReleasesSkipping(ctx, namespace, name, since, known)
+	}
+	return src.FetchReleases(ctx, namespace, name, since)
+}
+
+// RepositoryMetadata describes a repository hosting a provider or module.
+type RepositoryMetadata struct {
+	Name        string   // The name of the repository.
+	Description string   // The description of the repository.
+	URL         string   // The web URL of the repository.
+	Topics      []string // The topics the repository is tagged with, if supported by the backend.
+	Archived    bool     // Whether the repository is archived, and read-only.
+	Disabled    bool     // Whether the repository has been disabled by the backend, if supported.
+	// MovedTo is the "<owner>/<name>" the repository was renamed or transferred to, if the backend
+	// redirected the request to a different repository.
+	MovedTo string
+}
+
+// Release represents a single published release of a repository.
+type Release struct {
+	TagName      string    // The tag name associated with the release.
+	Assets       []Asset   // The files attached to the release.
+	IsPrerelease bool      // Indicates if the release is marked as a prerelease by the backend.
+	CreatedAt    time.Time // The time the release was created.
+}
+
+// Asset represents a single file attached to a release.
+type Asset struct {
+	Name        string // The name of the asset.
+	DownloadURL string // The URL to download the asset.
+}
+
+// FindAssetBySuffix returns the first asset whose name ends with the given suffix, or nil if there is none.
+func FindAssetBySuffix(assets []Asset, suffix string) *Asset {
+	slog.Info("Finding asset by suffix", "suffix", suffix)
+	for i := range assets {
+		if strings.HasSuffix(assets[i].Name, suffix) {
+			slog.Info("Asset found", "asset", assets[i])
+			return &assets[i]
+		}
+	}
+	slog.Info("Asset not found")
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package static reads provider and module releases from a plain HTTPS directory or object store bucket.
+//
+// Each repository is expected to live in its own directory below the base URL, for example
+// https://releases.example.com/terraform-provider-foo/. The directory either contains an index.json
+// describing the releases, or is served as an HTML directory listing of the release files.
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	IndexFormatJSON = "json"
+	IndexFormatHTML = "html"
+
+	// IndexFileName is the name of the index file in each repository directory when using the JSON index format.
+	IndexFileName = "index.json"
+
+	requestTimeout = 60 * time.Second
+)
+
+// Index is the format of the index.json file describing the releases of a single repository.
+type Index struct {
+	Versions []IndexVersion `json:"versions"`
+}
+
+// IndexVersion describes a single release in an Index.
+type IndexVersion struct {
+	Version    string       `json:"version"`              // The version number, without a "v" prefix.
+	CreatedAt  time.Time    `json:"created_at"`           // The time the release was published.
+	Prerelease bool         `json:"prerelease,omitempty"` // Indicates if the release is a prerelease.
+	Assets     []IndexAsset `json:"assets"`               // The files belonging to the release.
+}
+
+// IndexAsset describes a single file of a release in an Index. If URL is empty or relative,
+// it is resolved against the location of the index.
+type IndexAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// ReleaseSource implements source.ReleaseSource on top of a static index of release files.
+type ReleaseSource struct {
+	baseURL     *url.URL
+	indexFormat string
+	token       string
+	httpClient  *http.Client
+}
+
+// NewReleaseSource creates a ReleaseSource reading repositories below baseURL, using either the
+// JSON index or HTML directory listing format.
+func NewReleaseSource(baseURL, indexFormat, token string) (*ReleaseSource, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", baseURL, err)
+	}
+
+	switch indexFormat {
+	case "":
+		indexFormat = IndexFormatJSON
+	case IndexFormatJSON, IndexFormatHTML:
+	default:
+		return nil, fmt.Errorf("unknown index format %q, expected %q or %q", indexFormat, IndexFormatJSON, IndexFormatHTML)
+	}
+
+	return &ReleaseSource{
+		baseURL:     parsed,
+		indexFormat: indexFormat,
+		token:       token,
+		httpClient:  xray.Client(&http.Client{Timeout: requestTimeout}),
+	}, nil
+}
+
+// repositoryURL returns the directory of the given repository. Namespaces are not part of the path,
+// as each namespace is configured with its own base URL.
+func (s *ReleaseSource) repositoryURL(name string) *url.URL {
+	return s.baseURL.ResolveReference(&url.URL{Path: url.PathEscape(name) + "/"})
+}
+
+func (s *ReleaseSource) RepositoryExists(ctx context.Context, namespace, name string) (exists bool, err error) {
+	err = xray.Capture(ctx, "static.repository.exists", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		_, found, fetchErr := s.fetchReleases(tracedCtx, name)
+		exists = found
+		return fetchErr
+	})
+
+	return exists, err
+}
+
+func (s *ReleaseSource) FetchReleases(ctx context.Context, namespace, name string, since *time.Time) (releases []source.Release, err error) {
+	err = xray.Capture(ctx, "static.releases.fetch", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+		xray.AddAnnotation(tracedCtx, "name", name)
+
+		allReleases, found, fetchErr := s.fetchReleases(tracedCtx, name)
+		if fetchErr != nil {
+			return fetchErr
+		}
+		if !found {
+			return fmt.Errorf("no release index found for %s", name)
+		}
+
+		for _, r := range allReleases {
+			// releases without a known creation time are always returned, as we cannot tell whether they are new
+			if since != nil && !r.CreatedAt.IsZero() && r.CreatedAt.Before(*since) {
+				continue
+			}
+			releases = append(releases, r)
+		}
+		return nil
+	})
+
+	slog.Info("Static releases fetched", "count", len(releases))
+	return releases, err
+}
+
+func (s *ReleaseSource) FindRelease(ctx context.Context, namespace, name, version string) (*source.Release, error) {
+	releases, err := s.FetchReleases(ctx, namespace, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range releases {
+		if releases[i].TagName == fmt.Sprintf("v%s", version) {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *ReleaseSource) DownloadAsset(ctx context.Context, asset source.Asset) (body io.ReadCloser, err error) {
+	err = xray.Capture(ctx, "static.asset.download", func(tracedCtx context.Context) error {
+		slog.Info("Downloading asset", "url", asset.DownloadURL)
+
+		resp, reqErr := s.get(tracedCtx, asset.DownloadURL)
+		if reqErr != nil {
+			return fmt.Errorf("error downloading asset: %w", reqErr)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status code when downloading asset: %d", resp.StatusCode)
+		}
+
+		body = resp.Body
+		return nil
+	})
+
+	return body, err
+}
+
+// ModuleDownloadURL returns the URL of the module archive (.tar.gz, .tgz or .zip) attached to the release of the given tag.
+func (s *ReleaseSource) ModuleDownloadURL(ctx context.Context, namespace, name, tag string) (string, error) {
+	release, err := s.FindRelease(ctx, namespace, name, strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return "", err
+	}
+	if release == nil {
+		return "", fmt.Errorf("no release found for tag %s", tag)
+	}
+
+	for _, suffix := range []string{".tar.gz", ".tgz", ".zip"} {
+		if asset := source.FindAssetBySuffix(release.Assets, suffix); asset != nil {
+			return asset.DownloadURL, nil
+		}
+	}
+	return "", fmt.Errorf("no module archive found for tag %s", tag)
+}
+
+// fetchReleases reads the index of a repository. The boolean result is false if the index does not exist.
+func (s *ReleaseSource) fetchReleases(ctx context.Context, name string) ([]source.Release, bool, error) {
+	repositoryURL := s.repositoryURL(name)
+
+	if s.indexFormat == IndexFormatHTML {
+		return s.fetchReleasesFromListing(ctx, name, repositoryURL)
+	}
+
+	indexURL := repositoryURL.ResolveReference(&url.URL{Path: IndexFileName})
+	contents, found, err := s.getContents(ctx, indexURL.String())
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	var index Index
+	if err := json.Unmarshal(contents, &index); err != nil {
+		return nil, true, fmt.Errorf("failed to parse index %s: %w", indexURL, err)
+	}
+
+	return index.toSourceReleases(indexURL), true, nil
+}
+
+//nolint:gochecknoglobals // This should be treated as a constant.
+var linkPattern = regexp.MustCompile(`href="([^"?#]+)"`)
+
+// fetchReleasesFromListing builds releases from the files in an HTML directory listing. Release files are expected to be
+// named "<name>_<version>_..." (or "<name>_<version>.tar.gz" for module archives), either directly in the listing or in
+// one level of subdirectories (for example one per version).
+func (s *ReleaseSource) fetchReleasesFromListing(ctx context.Context, name string, repositoryURL *url.URL) ([]source.Release, bool, error) {
+	assets, found, err := s.listAssets(ctx, repositoryURL, true)
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	filePattern := regexp.MustCompile(fmt.Sprintf(`^%s_([^_]+?)(_|\.tar\.gz$|\.tgz$|\.zip$)`, regexp.QuoteMeta(name)))
+
+	var releases []source.Release
+	releaseIndex := make(map[string]int)
+	for _, asset := range assets {
+		matches := filePattern.FindStringSubmatch(asset.Name)
+		if matches == nil {
+			continue
+		}
+
+		version := matches[1]
+		i, ok := releaseIndex[version]
+		if !ok {
+			i = len(releases)
+			releaseIndex[version] = i
+			releases = append(releases, source.Release{TagName: fmt.Sprintf("v%s", version)})
+		}
+		releases[i].Assets = append(releases[i].Assets, asset)
+	}
+
+	return releases, true, nil
+}
+
+func (s *ReleaseSource) listAssets(ctx context.Context, dirURL *url.URL, recurse bool) ([]source.Asset, bool, error) {
+	contents, found, err := s.getContents(ctx, dirURL.String())
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	var assets []source.Asset
+	for _, match := range linkPattern.FindAllStringSubmatch(string(contents), -1) {
+		linkURL, parseErr := dirURL.Parse(match[1])
+		// only follow links that stay below the directory being listed
+		if parseErr != nil || linkURL.Host != dirURL.Host || !strings.HasPrefix(linkURL.Path, dirURL.Path) || linkURL.Path == dirURL.Path {
+			continue
+		}
+
+		if strings.HasSuffix(linkURL.Path, "/") {
+			if !recurse {
+				continue
+			}
+			subAssets, _, subErr := s.listAssets(ctx, linkURL, false)
+			if subErr != nil {
+				return nil, true, subErr
+			}
+			assets = append(assets, subAssets...)
+			continue
+		}
+
+		assets = append(assets, source.Asset{
+			Name:        linkURL.Path[strings.LastIndex(linkURL.Path, "/")+1:],
+			DownloadURL: linkURL.String(),
+		})
+	}
+
+	return assets, true, nil
+}
+
+// getContents reads the contents of a URL. The boolean result is false if the URL returned a 404.
+func (s *ReleaseSource) getContents(ctx context.Context, rawURL string) ([]byte, bool, error) {
+	resp, err := s.get(ctx, rawURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	// buckets commonly answer with 403 rather than 404 for keys that do not exist
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status code when getting %s: %d", rawURL, resp.StatusCode)
+	}
+
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return contents, true, nil
+}
+
+// get performs a GET request. The token, if any, is only sent to the configured host.
+func (s *ReleaseSource) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if s.token != "" && req.URL.Host == s.baseURL.Host {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+	}
+
+	return s.httpClient.Do(req)
+}
+
+func (i Index) toSourceReleases(indexURL *url.URL) []source.Release {
+	releases := make([]source.Release, 0, len(i.Versions))
+	for _, v := range i.Versions {
+		assets := make([]source.Asset, 0, len(v.Assets))
+		for _, a := range v.Assets {
+			location := a.URL
+			if location == "" {
+				location = a.Name
+			}
+
+			assetURL, err := indexURL.Parse(location)
+			if err != nil {
+				slog.Warn("Skipping asset with invalid URL", "asset", a.Name, "url", location)
+				continue
+			}
+
+			assets = append(assets, source.Asset{
+				Name:        a.Name,
+				DownloadURL: assetURL.String(),
+			})
+		}
+
+		releases = append(releases, source.Release{
+			TagName:      fmt.Sprintf("v%s", strings.TrimPrefix(v.Version, "v")),
+			Assets:       assets,
+			IsPrerelease: v.Prerelease,
+			CreatedAt:    v.CreatedAt,
+		})
+	}
+	return releases
+}
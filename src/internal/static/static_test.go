@@ -0,0 +1,126 @@
+package static_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/static"
+)
+
+const shaSums = `1111  terraform-provider-dummy_1.0.0_linux_amd64.zip
+2222  terraform-provider-dummy_1.0.0_windows_amd64.zip
+`
+
+func newFakeBucket(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	files := map[string]string{
+		// JSON index, with assets both relative to the index and absolute
+		"/json/terraform-provider-dummy/index.json": `{"versions": [{"version": "1.0.0", "created_at": "2023-10-01T10:00:00Z", "assets": [
+			{"name": "terraform-provider-dummy_1.0.0_linux_amd64.zip"},
+			{"name": "terraform-provider-dummy_1.0.0_windows_amd64.zip", "url": "1.0.0/terraform-provider-dummy_1.0.0_windows_amd64.zip"},
+			{"name": "terraform-provider-dummy_1.0.0_SHA256SUMS"},
+			{"name": "terraform-provider-dummy_1.0.0_SHA256SUMS.sig"}
+		]}]}`,
+		"/json/terraform-provider-dummy/terraform-provider-dummy_1.0.0_SHA256SUMS": shaSums,
+
+		// HTML directory listing with one subdirectory per version
+		"/html/terraform-provider-dummy/": `<html><body><a href="../">../</a><a href="1.0.0/">1.0.0/</a></body></html>`,
+		"/html/terraform-provider-dummy/1.0.0/": `<html><body>
+			<a href="terraform-provider-dummy_1.0.0_linux_amd64.zip">zip</a>
+			<a href="/html/terraform-provider-dummy/1.0.0/terraform-provider-dummy_1.0.0_windows_amd64.zip">zip</a>
+			<a href="terraform-provider-dummy_1.0.0_SHA256SUMS">sums</a>
+			<a href="https://elsewhere.example.com/terraform-provider-dummy_1.0.0_darwin_arm64.zip">elsewhere</a>
+		</body></html>`,
+		"/html/terraform-provider-dummy/1.0.0/terraform-provider-dummy_1.0.0_SHA256SUMS": shaSums,
+		"/html/terraform-aws-vpc/": `<a href="terraform-aws-vpc_2.1.0.tar.gz">2.1.0</a>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := files[r.URL.Path]
+		if !ok {
+			if strings.HasSuffix(r.URL.Path, ".zip") {
+				fmt.Fprint(w, "binary")
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, contents)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderVersions(t *testing.T) {
+	server := newFakeBucket(t)
+
+	for _, format := range []string{static.IndexFormatJSON, static.IndexFormatHTML} {
+		t.Run(format, func(t *testing.T) {
+			src, err := static.NewReleaseSource(server.URL+"/"+format, format, "")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			exists, err := src.RepositoryExists(context.Background(), "vendor", "terraform-provider-dummy")
+			if err != nil || !exists {
+				t.Fatalf("expected repository to exist, got %v (error: %v)", exists, err)
+			}
+
+			exists, err = src.RepositoryExists(context.Background(), "vendor", "terraform-provider-missing")
+			if err != nil || exists {
+				t.Fatalf("expected repository to not exist, got %v (error: %v)", exists, err)
+			}
+
+			versions, _, err := providers.GetVersions(context.Background(), src, "vendor", "terraform-provider-dummy", nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(versions) != 1 || versions[0].Version != "1.0.0" {
+				t.Fatalf("unexpected versions %v", versions)
+			}
+			if len(versions[0].DownloadDetails) != 2 {
+				t.Fatalf("expected linux and windows platforms, got %v", versions[0].DownloadDetails)
+			}
+
+			details := versions[0].GetVersionDetails("windows", "amd64")
+			expectedURL := fmt.Sprintf("%s/%s/terraform-provider-dummy/1.0.0/terraform-provider-dummy_1.0.0_windows_amd64.zip", server.URL, format)
+			if details == nil || details.SHASum != "2222" || details.DownloadURL != expectedURL {
+				t.Fatalf("unexpected download details %v", details)
+			}
+		})
+	}
+}
+
+func TestModuleDownloadURL(t *testing.T) {
+	server := newFakeBucket(t)
+
+	src, err := static.NewReleaseSource(server.URL+"/html", static.IndexFormatHTML, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	versions, _, err := modules.GetVersions(context.Background(), src, "vendor", "terraform-aws-vpc", nil)
+	if err != nil || len(versions) != 1 || versions[0].Version != "2.1.0" {
+		t.Fatalf("unexpected versions %v (error: %v)", versions, err)
+	}
+
+	tag, err := modules.ResolveTag(context.Background(), src, "vendor", "terraform-aws-vpc", "2.1.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	downloadURL, err := src.ModuleDownloadURL(context.Background(), "vendor", "terraform-aws-vpc", tag)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := server.URL + "/html/terraform-aws-vpc/terraform-aws-vpc_2.1.0.tar.gz"; downloadURL != expected {
+		t.Errorf("expected %s, got %s", expected, downloadURL)
+	}
+}
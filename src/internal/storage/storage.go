@@ -0,0 +1,136 @@
+// Package storage stores artifacts published directly to the registry.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"golang.org/x/exp/slog"
+)
+
+// ErrPreconditionFailed is returned by PutIfMatch when the object was changed since it was read.
+var ErrPreconditionFailed = errors.New("the object was changed concurrently")
+
+// ObjectStore is a flat key/value store for published files, which are served to clients from URL.
+type ObjectStore interface {
+	// Get returns the contents stored under key, or nil if there is nothing stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetWithETag is like Get, and also returns the entity tag of the contents, or "" if there is nothing stored under key.
+	GetWithETag(ctx context.Context, key string) ([]byte, string, error)
+	// Put stores contents under key, replacing anything stored there before.
+	Put(ctx context.Context, key string, contents []byte, contentType string) error
+	// PutIfMatch stores contents under key only if the stored contents still have the given entity tag, or if nothing
+	// is stored under key when etag is "". It returns ErrPreconditionFailed otherwise.
+	PutIfMatch(ctx context.Context, key string, contents []byte, contentType, etag string) error
+	// URL returns the public URL that the contents stored under key are served from.
+	URL(key string) string
+}
+
+// S3Store implements ObjectStore on top of an S3 bucket that is publicly readable from publicURL,
+// either directly or through a CDN.
+type S3Store struct {
+	client    *s3.Client
+	bucket    string
+	publicURL *url.URL
+}
+
+func NewS3Store(awsConfig aws.Config, bucket, publicURL string) (*S3Store, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(publicURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid public URL %q: %w", publicURL, err)
+	}
+
+	return &S3Store{
+		client:    s3.NewFromConfig(awsConfig),
+		bucket:    bucket,
+		publicURL: parsed,
+	}, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	contents, _, err := s.GetWithETag(ctx, key)
+	return contents, err
+}
+
+func (s *S3Store) GetWithETag(ctx context.Context, key string) (contents []byte, etag string, err error) {
+	err = xray.Capture(ctx, "storage.get", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "key", key)
+
+		output, getErr := s.client.GetObject(tracedCtx, &s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		if getErr != nil {
+			var noSuchKey *s3types.NoSuchKey
+			if errors.As(getErr, &noSuchKey) {
+				slog.Info("Object not found in store", "key", key)
+				return nil
+			}
+			return fmt.Errorf("failed to get object %s: %w", key, getErr)
+		}
+		defer output.Body.Close()
+
+		contents, getErr = io.ReadAll(output.Body)
+		if getErr != nil {
+			return fmt.Errorf("failed to read object %s: %w", key, getErr)
+		}
+		etag = aws.ToString(output.ETag)
+		return nil
+	})
+
+	return contents, etag, err
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, contents []byte, contentType string) error {
+	return s.put(ctx, key, contents, contentType)
+}
+
+func (s *S3Store) PutIfMatch(ctx context.Context, key string, contents []byte, contentType, etag string) error {
+	// the S3 client of this SDK version has no fields for conditional writes, so their headers are set directly
+	condition := smithyhttp.SetHeaderValue("If-None-Match", "*")
+	if etag != "" {
+		condition = smithyhttp.SetHeaderValue("If-Match", etag)
+	}
+	return s.put(ctx, key, contents, contentType, s3.WithAPIOptions(condition))
+}
+
+func (s *S3Store) put(ctx context.Context, key string, contents []byte, contentType string, optFns ...func(*s3.Options)) error {
+	return xray.Capture(ctx, "storage.put", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "key", key)
+
+		slog.Info("Storing object", "key", key, "size", len(contents))
+		_, err := s.client.PutObject(tracedCtx, &s3.PutObjectInput{
+			Bucket:      aws.String(s.bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(contents),
+			ContentType: aws.String(contentType),
+		}, optFns...)
+
+		// S3 answers with 409 Conflict when another conditional write of the object is in progress
+		var responseErr *awshttp.ResponseError
+		if errors.As(err, &responseErr) && (responseErr.HTTPStatusCode() == http.StatusPreconditionFailed || responseErr.HTTPStatusCode() == http.StatusConflict) {
+			slog.Info("Object was changed concurrently", "key", key)
+			return ErrPreconditionFailed
+		}
+		if err != nil {
+			return fmt.Errorf("failed to put object %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+func (s *S3Store) URL(key string) string {
+	return s.publicURL.ResolveReference(&url.URL{Path: key}).String()
+}
@@ -1,7 +1,125 @@
-// Package warnings defines the warnings associated with the provider
+// Package warnings defines the warnings associated with providers and modules
 package warnings
 
-// ProviderWarnings return the list of warnings for a given provider identified by its namespace and type
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentofu/registry/internal/semver"
+	"golang.org/x/exp/slog"
+)
+
+// Rule attaches a warning to the providers and modules matching its address, optionally limited to a range of versions.
+type Rule struct {
+	// Address is a namespace, applying to all its providers and modules, a provider ("<namespace>/<type>")
+	// or a module ("<namespace>/<name>/<system>"), as requested by clients.
+	Address string `json:"address"`
+	// Versions is a version constraint such as "< 2.0.0", limiting the rule to the matching versions.
+	// Like in OpenTofu, prereleases only match constraints naming a prerelease.
+	Versions string `json:"versions,omitempty"`
+	Message  string `json:"message"`
+
+	constraints semver.Constraints
+}
+
+// File is the JSON document the rules are loaded from.
+type File struct {
+	Warnings []Rule `json:"warnings"`
+}
+
+// Rules are the warning rules loaded from a File, on top of the built-in rules. A nil *Rules holds only the built-in rules.
+type Rules struct {
+	rules []Rule
+}
+
+//nolint:gochecknoglobals // This should be treated as a constant.
+var builtinRules = []Rule{
+	// https://github.com/opentofu/registry/issues/108
+	{Address: "hashicorp/terraform", Message: `This provider is archived and no longer needed. The terraform_remote_state data source is built into the latest OpenTofu release.`},
+}
+
+// Parse validates a rules File. Empty contents hold no rules.
+func Parse(contents []byte) (*Rules, error) {
+	if len(contents) == 0 {
+		return &Rules{}, nil
+	}
+
+	var file File
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("could not parse warnings: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(file.Warnings))
+	for _, rule := range file.Warnings {
+		parts := strings.Split(rule.Address, "/")
+		for _, part := range parts {
+			if part == "" || len(parts) > 3 {
+				return nil, fmt.Errorf("%q is not a valid namespace, provider or module address", rule.Address)
+			}
+		}
+		if rule.Message == "" {
+			return nil, fmt.Errorf("the warning for %s must have a message", rule.Address)
+		}
+
+		constraints, err := semver.ParseConstraints(rule.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versions for %s: %w", rule.Address, err)
+		}
+		rule.constraints = constraints
+		rules = append(rules, rule)
+	}
+	return &Rules{rules: rules}, nil
+}
+
+// ProviderWarnings returns the warnings of a provider identified by its namespace and type. Rules limited to a range
+// of versions apply if any of the given versions is in the range.
+func (r *Rules) ProviderWarnings(providerNamespace, providerType string, versions []string) []string {
+	return r.warnings(providerNamespace+"/"+providerType, versions)
+}
+
+// ModuleWarnings returns the warnings of a module identified by its namespace, name and system. Rules limited to a range
+// of versions apply if any of the given versions is in the range.
+func (r *Rules) ModuleWarnings(namespace, name, system string, versions []string) []string {
+	return r.warnings(namespace+"/"+name+"/"+system, versions)
+}
+
+func (r *Rules) warnings(address string, versions []string) []string {
+	rules := builtinRules
+	if r != nil {
+		rules = append(rules[:len(rules):len(rules)], r.rules...)
+	}
+
+	var warn []string
+	for _, rule := range rules {
+		if rule.applies(address, versions) {
+			warn = append(warn, rule.Message)
+		}
+	}
+	return warn
+}
+
+func (r Rule) applies(address string, versions []string) bool {
+	namespace, _, _ := strings.Cut(address, "/")
+	if r.Address != address && r.Address != namespace {
+		return false
+	}
+	if r.Versions == "" {
+		return true
+	}
+
+	for _, v := range versions {
+		if r.constraints.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderWarnings return the list of built-in warnings for a given provider identified by its namespace and type
 //
 // Example: registry.terraform.io/hashicorp/terraform
 //
@@ -9,13 +127,79 @@ package warnings
 // fmt.Println(warn)
 // >> [This provider is archived and no longer needed. The terraform_remote_state data source is built into the latest OpenTofu release.]
 func ProviderWarnings(providerNamespace, providerType string) []string {
-	switch providerNamespace { //nolint:gocritic // Switch is more appropriate than 'if' for the use case
-	case "hashicorp":
-		switch providerType { //nolint:gocritic // Switch is more appropriate than 'if' for the use case
-		case "terraform":
-			return []string{`This provider is archived and no longer needed. The terraform_remote_state data source is built into the latest OpenTofu release.`}
-		}
+	var rules *Rules
+	return rules.ProviderWarnings(providerNamespace, providerType, nil)
+}
+
+// Repository is the state of the repository a provider or module is read from.
+type Repository struct {
+	Archived bool
+	Disabled bool
+	// MovedTo is the "<owner>/<name>" the repository was renamed or transferred to, if any.
+	MovedTo string
+}
+
+// RepositoryWarnings returns the deprecation warnings of a provider or module, given as kind, read from a repository
+// that is archived, disabled or has moved.
+func RepositoryWarnings(kind string, repo Repository) []string {
+	var warn []string
+	if repo.Archived {
+		warn = append(warn, fmt.Sprintf("The repository of this %s is archived. The %s is deprecated and will not receive new versions.", kind, kind))
+	}
+	if repo.Disabled {
+		warn = append(warn, fmt.Sprintf("The repository of this %s has been disabled. The %s is deprecated and will not receive new versions.", kind, kind))
+	}
+	if repo.MovedTo != "" {
+		warn = append(warn, fmt.Sprintf("The repository of this %s has moved to %s.", kind, repo.MovedTo))
+	}
+	return warn
+}
+
+// Loader keeps the rules in memory between requests, loading them again once they are older than maxAge
+// so that changes to the rules are picked up without a redeploy.
+type Loader struct {
+	// fetch returns the contents of the rules File, or nil if there is none.
+	fetch  func(ctx context.Context) ([]byte, error)
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	rules    *Rules
+	loadedAt time.Time
+}
+
+func NewLoader(fetch func(ctx context.Context) ([]byte, error), maxAge time.Duration) *Loader {
+	return &Loader{fetch: fetch, maxAge: maxAge}
+}
+
+// Rules returns the current rules. If the rules cannot be loaded, the previously loaded ones keep being used.
+// A nil Loader only has the built-in rules.
+func (l *Loader) Rules(ctx context.Context) *Rules {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.maxAge {
+		return l.rules
+	}
+
+	// don't retry on every request if the rules are broken or the store is unavailable
+	l.loadedAt = time.Now()
+
+	contents, err := l.fetch(ctx)
+	if err != nil {
+		slog.Error("Error loading warnings, using the previous ones", "error", err)
+		return l.rules
+	}
+	rules, err := Parse(contents)
+	if err != nil {
+		slog.Error("Invalid warnings, using the previous ones", "error", err)
+		return l.rules
 	}
 
-	return nil
+	l.rules = rules
+	slog.Info("Loaded warnings", "rules", len(rules.rules))
+	return l.rules
 }
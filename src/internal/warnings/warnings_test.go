@@ -1,8 +1,11 @@
 package warnings
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestProviderWarnings(t *testing.T) {
@@ -42,3 +45,98 @@ func TestProviderWarnings(t *testing.T) {
 		)
 	}
 }
+
+func TestRules(t *testing.T) {
+	rules, err := Parse([]byte(`{"warnings": [
+		{"address": "acme", "message": "namespace"},
+		{"address": "acme/widget", "message": "provider"},
+		{"address": "acme/widget", "versions": "< 2.0.0", "message": "old versions"},
+		{"address": "acme/vpc/aws", "message": "module"}
+	]}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"provider", rules.ProviderWarnings("acme", "widget", []string{"2.1.0"}), []string{"namespace", "provider"}},
+		{"version range", rules.ProviderWarnings("acme", "widget", []string{"2.1.0", "1.0.0"}), []string{"namespace", "provider", "old versions"}},
+		{"other provider", rules.ProviderWarnings("acme", "other", nil), []string{"namespace"}},
+		{"module", rules.ModuleWarnings("acme", "vpc", "aws", []string{"1.0.0"}), []string{"namespace", "module"}},
+		{"module named like a provider", rules.ModuleWarnings("acme", "widget", "aws", nil), []string{"namespace"}},
+		{"other namespace", rules.ProviderWarnings("foo", "widget", nil), nil},
+		{"built-in", rules.ProviderWarnings("hashicorp", "terraform", nil), ProviderWarnings("hashicorp", "terraform")},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":       `{"warnings": {}}`,
+		"empty address":      `{"warnings": [{"address": "", "message": "a"}]}`,
+		"too long address":   `{"warnings": [{"address": "a/b/c/d", "message": "a"}]}`,
+		"missing message":    `{"warnings": [{"address": "a"}]}`,
+		"invalid constraint": `{"warnings": [{"address": "a", "versions": "not a version", "message": "a"}]}`,
+	}
+	for name, contents := range tests {
+		if _, err := Parse([]byte(contents)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	contents := `{"warnings": [{"address": "acme", "message": "first"}]}`
+	var fetchErr error
+	fetches := 0
+	loader := NewLoader(func(_ context.Context) ([]byte, error) {
+		fetches++
+		return []byte(contents), fetchErr
+	}, time.Hour)
+
+	ctx := context.Background()
+	if got := loader.Rules(ctx).ProviderWarnings("acme", "widget", nil); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Errorf("got %v, want [first]", got)
+	}
+
+	// the rules are kept until they expire
+	contents = `{"warnings": [{"address": "acme", "message": "second"}]}`
+	loader.Rules(ctx)
+	if fetches != 1 {
+		t.Errorf("expected the rules to be fetched once, got %d", fetches)
+	}
+
+	loader.loadedAt = time.Time{}
+	if got := loader.Rules(ctx).ProviderWarnings("acme", "widget", nil); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("got %v, want [second]", got)
+	}
+
+	// failing to reload keeps the previous rules
+	loader.loadedAt = time.Time{}
+	fetchErr = errors.New("unavailable")
+	if got := loader.Rules(ctx).ProviderWarnings("acme", "widget", nil); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("got %v, want [second]", got)
+	}
+}
+
+func TestRepositoryWarnings(t *testing.T) {
+	if got := RepositoryWarnings("provider", Repository{}); got != nil {
+		t.Errorf("expected no warnings, got %v", got)
+	}
+
+	got := RepositoryWarnings("module", Repository{Archived: true, MovedTo: "acme/terraform-aws-network"})
+	want := []string{
+		"The repository of this module is archived. The module is deprecated and will not receive new versions.",
+		"The repository of this module has moved to acme/terraform-aws-network.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+// Package yank holds the provider and module versions that must no longer be served, for example because they
+// turned out to be broken or malicious after being released.
+package yank
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/registry/internal/semver"
+)
+
+// List maps provider ("<namespace>/<type>") and module ("<namespace>/<name>/<system>") addresses to their yanked
+// versions and the reason each of them was yanked. Addresses are effective addresses, after following redirects.
+type List map[string]map[string]string
+
+// Parse validates the addresses and versions of a yank list, normalizing the versions by removing any "v" prefix.
+func Parse(raw map[string]map[string]string) (List, error) {
+	list := make(List, len(raw))
+	for address, versions := range raw {
+		if parts := strings.Count(address, "/") + 1; parts != 2 && parts != 3 {
+			return nil, fmt.Errorf("%q is not a valid provider or module address", address)
+		}
+
+		list[address] = make(map[string]string, len(versions))
+		for rawVersion, reason := range versions {
+			version, err := semver.Normalize(rawVersion)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version for %s: %w", address, err)
+			}
+			if reason == "" {
+				return nil, fmt.Errorf("the reason for yanking %s %s must be given", address, version)
+			}
+			list[address][version] = reason
+		}
+	}
+	return list, nil
+}
+
+// Reason returns why the given version of an address was yanked, and false if it was not yanked.
+func (l List) Reason(address, version string) (string, bool) {
+	reason, ok := l[address][strings.TrimPrefix(version, "v")]
+	return reason, ok
+}
+
+// Warning describes a yanked version to clients.
+func Warning(version, reason string) string {
+	return fmt.Sprintf("Version %s has been yanked and is no longer available: %s", version, reason)
+}
+
+// Filter removes the yanked versions of an address from items, as returned by versionOf, and returns a warning for each removed version.
+func Filter[T any](l List, address string, items []T, versionOf func(T) string) ([]T, []string) {
+	if len(l[address]) == 0 {
+		return items, nil
+	}
+
+	var warnings []string
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if reason, ok := l.Reason(address, versionOf(item)); ok {
+			warnings = append(warnings, Warning(versionOf(item), reason))
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered, warnings
+}
@@ -0,0 +1,48 @@
+package yank_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/registry/internal/yank"
+)
+
+func TestFilter(t *testing.T) {
+	list, err := yank.Parse(map[string]map[string]string{
+		"acme/widget":  {"v1.1.0": "Broken checksums"},
+		"acme/vpc/aws": {"2.0.0": "Deletes subnets"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	identity := func(v string) string { return v }
+	versions, warnings := yank.Filter(list, "acme/widget", []string{"1.2.0", "1.1.0", "1.0.0"}, identity)
+	if !reflect.DeepEqual(versions, []string{"1.2.0", "1.0.0"}) {
+		t.Errorf("unexpected versions %v", versions)
+	}
+	if !reflect.DeepEqual(warnings, []string{yank.Warning("1.1.0", "Broken checksums")}) {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	versions, warnings = yank.Filter(list, "other/widget", []string{"1.1.0"}, identity)
+	if len(versions) != 1 || warnings != nil {
+		t.Errorf("expected other addresses to be left alone, got %v %v", versions, warnings)
+	}
+
+	if reason, ok := list.Reason("acme/vpc/aws", "v2.0.0"); !ok || reason != "Deletes subnets" {
+		t.Errorf("unexpected reason %q", reason)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, raw := range map[string]map[string]map[string]string{
+		"invalid address": {"acme": {"1.0.0": "reason"}},
+		"invalid version": {"acme/widget": {"latest": "reason"}},
+		"missing reason":  {"acme/widget": {"1.0.0": ""}},
+	} {
+		if _, err := yank.Parse(raw); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
+	"golang.org/x/exp/slog"
+)
+
+// ListAdvisoriesResponse lists the OSV advisories of a provider, whichever versions they affect.
+type ListAdvisoriesResponse struct {
+	ID         string                `json:"id"`
+	Advisories []advisories.Advisory `json:"advisories"`
+}
+
+func listProviderAdvisories(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getListProvidersPathParams(req)
+		params.AnnotateLogger()
+
+		location := config.ProviderLocation(params.Namespace, params.Type)
+		response := ListAdvisoriesResponse{
+			ID:         fmt.Sprintf("%s/%s", params.Namespace, params.Type),
+			Advisories: config.Advisories.Database(ctx).For(providerAddress(location)),
+		}
+		// the list is always returned, even if there are no advisories
+		if response.Advisories == nil {
+			response.Advisories = []advisories.Advisory{}
+		}
+
+		slog.Info("Listing advisories", "advisories", len(response.Advisories))
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
+
+// matchAdvisories returns the advisories affecting any of the given versions of a provider or module, identified by
+// its effective address, along with a warning naming each advisory.
+func matchAdvisories(db *advisories.Database, address string, versions []string) ([]advisories.Match, []string) {
+	matches := db.Match(address, versions)
+
+	var warn []string
+	for _, m := range matches {
+		warn = append(warn, m.Warning())
+	}
+	return matches, warn
+}
+
+func providerAddress(location mappings.ProviderLocation) string {
+	return fmt.Sprintf("%s/%s", location.Namespace, location.Type)
+}
+
+func moduleAddress(location mappings.ModuleLocation) string {
+	return fmt.Sprintf("%s/%s/%s", location.Namespace, location.Name, location.System)
+}
@@ -11,7 +11,7 @@ import (
 type LambdaFunc func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
 
 func main() {
-	configBuilder := config.NewBuilder(config.WithProviderRedirects())
+	configBuilder := config.NewBuilder(config.WithProviderRedirects(), config.WithPublishing(), config.WithWebhooks())
 
 	config, err := configBuilder.BuildConfig(context.Background(), "registry.buildconfig")
 	if err != nil {
@@ -10,7 +10,6 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 
-	"github.com/opentofu/registry/internal/github"
 	"github.com/opentofu/registry/internal/modules"
 )
 
@@ -35,10 +34,14 @@ func downloadModuleVersion(config config.Config) LambdaFunc {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		params := getDownloadModuleHandlerPathParams(req)
 		params.AnnotateLogger()
-		repoName := modules.GetRepoName(params.System, params.Name)
+		location := config.ModuleLocation(params.Namespace, params.Name, params.System)
+
+		if reason, yanked := config.YankedVersions.Reason(fmt.Sprintf("%s/%s/%s", location.Namespace, location.Name, location.System), params.Version); yanked {
+			return yankedResponse(params.Version, reason), nil
+		}
 
 		// check if the repo exists
-		exists, err := github.RepositoryExists(ctx, config.ManagedGithubClient, params.Namespace, repoName)
+		location, exists, repoWarnings, err := checkModuleRepository(ctx, config, location)
 		if err != nil {
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 		}
@@ -47,14 +50,25 @@ func downloadModuleVersion(config config.Config) LambdaFunc {
 			return NotFoundResponse, nil
 		}
 
-		releaseTag, err := getReleaseTag(ctx, config, params.Namespace, repoName, params.Version)
+		src := config.ModuleReleaseSource(location)
+
+		// TODO: Create a modulecache, similar to the providercache, and use it here to avoid unnecessary API calls to the release source
+		releaseTag, err := modules.ResolveTag(ctx, src, location.Owner, location.Repository, params.Version)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		downloadURL, err := src.ModuleDownloadURL(ctx, location.Owner, location.Repository, releaseTag)
 		if err != nil {
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 		}
 
-		return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent, Body: "", Headers: map[string]string{
-			"X-Terraform-Get": fmt.Sprintf("git::https://github.com/%s/%s?ref=%s", params.Namespace, repoName, releaseTag),
-		}}, nil
+		warn := append(moduleWarnings(ctx, config, params.Namespace, params.Name, params.System, []modules.Version{{Version: params.Version}}), repoWarnings...)
+		_, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), moduleAddress(location), []string{params.Version})
+		warn = append(warn, advisoryWarnings...)
+		return withWarningHeaders(events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent, Body: "", Headers: map[string]string{
+			"X-Terraform-Get": downloadURL,
+		}}, warn), nil
 	}
 }
 
@@ -66,20 +80,3 @@ func getDownloadModuleHandlerPathParams(req events.APIGatewayProxyRequest) Downl
 		Version:   req.PathParameters["version"],
 	}
 }
-
-func getReleaseTag(ctx context.Context, config config.Config, namespace string, repoName string, version string) (string, error) {
-	// TODO: Create a modulecache, similar to the providercache, and use it here to avoid unnecessary API calls to GitHub
-	// First we check if a tag with "v" prefix exists in GitHub
-	release, err := github.FindRelease(ctx, config.RawGithubv4Client, namespace, repoName, version)
-	if err != nil {
-		return "", err
-	}
-
-	// If the release exists, then the tag does have the "v" prefix
-	// If it does not, then we assume the tag exists without the "v" prefix
-	if release != nil {
-		return fmt.Sprintf("v%s", version), nil
-	}
-
-	return version, nil
-}
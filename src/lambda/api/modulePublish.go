@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/publish"
+	"golang.org/x/exp/slog"
+)
+
+type PublishModulePathParams struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	System    string `json:"system"`
+	Version   string `json:"version"`
+}
+
+func (p PublishModulePathParams) AnnotateLogger() {
+	logger := slog.Default()
+	logger = logger.
+		With("namespace", p.Namespace).
+		With("name", p.Name).
+		With("system", p.System).
+		With("version", p.Version)
+	slog.SetDefault(logger)
+}
+
+func getPublishModulePathParams(req events.APIGatewayProxyRequest) PublishModulePathParams {
+	return PublishModulePathParams{
+		Namespace: req.PathParameters["namespace"],
+		Name:      req.PathParameters["name"],
+		System:    req.PathParameters["system"],
+		Version:   req.PathParameters["version"],
+	}
+}
+
+type PublishModuleVersionResponse struct {
+	Version     string `json:"version"`
+	DownloadURL string `json:"download_url"`
+}
+
+// publishModuleVersion accepts a module version as a gzipped tarball in the request body and stores it in the artifact store,
+// from where it is served by downloadModuleVersion.
+func publishModuleVersion(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getPublishModulePathParams(req)
+		params.AnnotateLogger()
+
+		if rejection := authorizePublish(config, params.Namespace, req); rejection != nil {
+			return *rejection, nil
+		}
+
+		archive, err := readRequestBody(req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		publisher := publish.NewPublisher(config.ArtifactStore, config.ProviderVersionCache)
+		downloadURL, err := publisher.PublishModule(ctx, publish.ModuleVersion{
+			Namespace: params.Namespace,
+			Name:      params.Name,
+			System:    params.System,
+			Version:   params.Version,
+			Archive:   archive,
+		})
+		if err != nil {
+			return publishErrorResponse(err)
+		}
+
+		resBody, err := json.Marshal(PublishModuleVersionResponse{Version: params.Version, DownloadURL: downloadURL})
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusCreated, Body: string(resBody)}, nil
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/semver"
+	"golang.org/x/exp/slog"
+)
+
+// ResolveModuleVersionResponse describes the highest module version matching a version constraint.
+type ResolveModuleVersionResponse struct {
+	Version string `json:"version"`
+	// DownloadURL is the source address of the version, as returned in the X-Terraform-Get header of the download endpoint.
+	DownloadURL string   `json:"download_url"`
+	Warnings    []string `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting the resolved version.
+	Advisories []advisories.Match `json:"advisories,omitempty"`
+}
+
+func resolveModuleVersion(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getListModuleVersionsPathParams(req)
+		params.AnnotateLogger()
+
+		constraint := req.QueryStringParameters["constraint"]
+		constraints, err := semver.ParseConstraints(constraint)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		location := config.ModuleLocation(params.Namespace, params.Name, params.System)
+
+		// check the repo exists
+		location, exists, repoWarnings, err := checkModuleRepository(ctx, config, location)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		if !exists {
+			return NotFoundResponse, nil
+		}
+
+		src := config.ModuleReleaseSource(location)
+
+		versions, ingestionWarnings, err := modules.GetVersions(ctx, src, location.Owner, location.Repository, nil)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		if len(ingestionWarnings) > 0 {
+			slog.Warn("Some tags were skipped", "warnings", ingestionWarnings)
+		}
+
+		versions, _ = removeYankedModuleVersions(config, location, versions)
+
+		i := semver.Highest(versions, constraints, func(v modules.Version) string { return v.Version })
+		if i < 0 {
+			slog.Info("No version matches the constraint", "constraint", constraint)
+			return errorResponse(http.StatusNotFound, fmt.Sprintf("no version of %s/%s/%s matches %q", params.Namespace, params.Name, params.System, constraint)), nil
+		}
+		resolved := versions[i].Version
+
+		releaseTag, err := modules.ResolveTag(ctx, src, location.Owner, location.Repository, resolved)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		downloadURL, err := src.ModuleDownloadURL(ctx, location.Owner, location.Repository, releaseTag)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		slog.Info("Resolved version", "constraint", constraint, "version", resolved)
+		warn := append(moduleWarnings(ctx, config, params.Namespace, params.Name, params.System, versions[i:i+1]), repoWarnings...)
+		warn = append(warn, ingestionWarnings...)
+		matches, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), moduleAddress(location), []string{resolved})
+		resBody, err := json.Marshal(ResolveModuleVersionResponse{
+			Version:     resolved,
+			DownloadURL: downloadURL,
+			Warnings:    append(warn, advisoryWarnings...),
+			Advisories:  matches,
+		})
+		if err != nil {
+			slog.Error("Error marshalling response", "error", err)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
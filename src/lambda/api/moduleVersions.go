@@ -5,12 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/opentofu/registry/internal/advisories"
 	"github.com/opentofu/registry/internal/config"
 	"golang.org/x/exp/slog"
 
 	"github.com/aws/aws-lambda-go/events"
 
-	"github.com/opentofu/registry/internal/github"
 	"github.com/opentofu/registry/internal/modules"
 )
 
@@ -39,6 +39,11 @@ func getListModuleVersionsPathParams(req events.APIGatewayProxyRequest) ListModu
 
 type ListModuleVersionsResponse struct {
 	Modules []ModulesResponse `json:"modules"`
+	// Warnings is not part of the module registry protocol. It holds the warnings configured for the module
+	// and explains versions that were left out of the list.
+	Warnings []string `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting any of the versions of the module.
+	Advisories []advisories.Match `json:"advisories,omitempty"`
 }
 
 type ModulesResponse struct {
@@ -49,10 +54,10 @@ func listModuleVersions(config config.Config) LambdaFunc {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		params := getListModuleVersionsPathParams(req)
 		params.AnnotateLogger()
-		repoName := modules.GetRepoName(params.System, params.Name)
+		location := config.ModuleLocation(params.Namespace, params.Name, params.System)
 
 		// check the repo exists
-		exists, err := github.RepositoryExists(ctx, config.ManagedGithubClient, params.Namespace, repoName)
+		location, exists, repoWarnings, err := checkModuleRepository(ctx, config, location)
 		if err != nil {
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 		}
@@ -60,14 +65,25 @@ func listModuleVersions(config config.Config) LambdaFunc {
 			return NotFoundResponse, nil
 		}
 
+		src := config.ModuleReleaseSource(location)
+
 		// TODO: Implement ddb caching similar to provider versions, but for modules
 		// this will also allow us to populate the `since` parameter in the module.GetVersions call below
 
 		// fetch all the versions
-		versions, err := modules.GetVersions(ctx, config.RawGithubv4Client, params.Namespace, repoName, nil)
+		versions, ingestionWarnings, err := modules.GetVersions(ctx, src, location.Owner, location.Repository, nil)
 		if err != nil {
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 		}
+		if len(ingestionWarnings) > 0 {
+			slog.Warn("Some tags were skipped", "warnings", ingestionWarnings)
+		}
+
+		versions, yankWarnings := removeYankedModuleVersions(config, location, versions)
+		warn := append(moduleWarnings(ctx, config, params.Namespace, params.Name, params.System, versions), repoWarnings...)
+		warn = append(append(warn, yankWarnings...), ingestionWarnings...)
+		matches, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), moduleAddress(location), moduleVersionNumbers(versions))
+		warn = append(warn, advisoryWarnings...)
 
 		response := ListModuleVersionsResponse{
 			Modules: []ModulesResponse{
@@ -75,6 +91,8 @@ func listModuleVersions(config config.Config) LambdaFunc {
 					Versions: versions,
 				},
 			},
+			Warnings:   warn,
+			Advisories: matches,
 		}
 
 		resBody, err := json.Marshal(response)
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/warnings"
+	"golang.org/x/exp/slog"
+)
+
+// maxBatchProviders bounds the number of providers in a batch request, so that filling the cache for all of them
+// fits within the API Gateway timeout.
+const maxBatchProviders = 50
+
+// maxConcurrentFills is the number of providers missing from the cache that are fetched from their repositories at once.
+const maxConcurrentFills = 5
+
+type BatchProvidersRequest struct {
+	// Providers are the addresses of the providers to look up, in the "<namespace>/<type>" format.
+	Providers []string `json:"providers"`
+}
+
+// BatchProviderVersions holds the versions of a single provider in the batch response, or the errors that prevented listing them.
+type BatchProviderVersions struct {
+	ID       string          `json:"id"`
+	Versions []types.Version `json:"versions,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting any of the versions of the provider.
+	Advisories []advisories.Match `json:"advisories,omitempty"`
+	Errors     []string           `json:"errors,omitempty"`
+}
+
+type BatchProvidersResponse struct {
+	Providers []BatchProviderVersions `json:"providers"`
+}
+
+// batchProvider is a provider requested in a batch, along with its location.
+type batchProvider struct {
+	ListProvidersPathParams
+	Location mappings.ProviderLocation
+}
+
+// address is the provider's address as requested, rather than by its effective namespace.
+func (p batchProvider) address() string {
+	return fmt.Sprintf("%s/%s", p.Namespace, p.Type)
+}
+
+func (p batchProvider) cacheKey() string {
+	return fmt.Sprintf("%s/%s", p.Location.Namespace, p.Location.Type)
+}
+
+func batchProviderVersions(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		var request BatchProvidersRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			return errorResponse(http.StatusBadRequest, "invalid request body"), nil
+		}
+
+		requested, err := parseBatchProviders(config, request.Providers)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		keys := make([]string, 0, len(requested))
+		for _, p := range requested {
+			keys = append(keys, p.cacheKey())
+		}
+
+		// For now, we will ignore errors from the cache and just fetch every provider from its repository instead
+		documents, err := config.ProviderVersionCache.BatchGetItems(ctx, keys)
+		if err != nil {
+			slog.Error("Error getting providers from cache", "error", err)
+		}
+
+		rules, db := config.Warnings.Rules(ctx), config.Advisories.Database(ctx)
+		response := BatchProvidersResponse{Providers: make([]BatchProviderVersions, len(requested))}
+
+		var wg sync.WaitGroup
+		fills := make(chan struct{}, maxConcurrentFills)
+		for i, p := range requested {
+			document := documents[p.cacheKey()]
+			if document != nil && document.IsStale() {
+				slog.Info("Document is stale, triggering lambda", "provider", p.cacheKey(), "last_updated", document.LastUpdated)
+				if triggerErr := triggerPopulateProviderVersions(ctx, config, p.Location.Namespace, p.Location.Type); triggerErr != nil {
+					slog.Error("Error triggering lambda", "error", triggerErr)
+				}
+			}
+			// moves are rare, so the document of the provider a repository moved to is read on its own
+			p.Location, document = followProviderMove(ctx, config, p.Location, document)
+			if document != nil && len(document.Versions) > 0 {
+				response.Providers[i] = batchProviderResult(config, rules, db, p, document)
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, p batchProvider) {
+				defer wg.Done()
+				fills <- struct{}{}
+				defer func() { <-fills }()
+				response.Providers[i] = fillBatchProvider(ctx, config, rules, db, p)
+			}(i, p)
+		}
+		wg.Wait()
+
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
+
+// parseBatchProviders validates and deduplicates the requested provider addresses, keeping their order.
+func parseBatchProviders(config config.Config, addresses []string) ([]batchProvider, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("at least one provider must be given")
+	}
+
+	seen := make(map[string]bool)
+	var requested []batchProvider
+	for _, address := range addresses {
+		namespace, providerType, ok := strings.Cut(address, "/")
+		if !ok || namespace == "" || providerType == "" || strings.Contains(providerType, "/") {
+			return nil, fmt.Errorf("invalid provider address %q, expected <namespace>/<type>", address)
+		}
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+
+		requested = append(requested, batchProvider{
+			ListProvidersPathParams: ListProvidersPathParams{Namespace: namespace, Type: providerType},
+			Location:                config.ProviderLocation(namespace, providerType),
+		})
+	}
+
+	if len(requested) > maxBatchProviders {
+		return nil, fmt.Errorf("at most %d providers can be given", maxBatchProviders)
+	}
+	return requested, nil
+}
+
+// fillBatchProvider lists the versions of a provider that is not cached yet from its repository.
+func fillBatchProvider(ctx context.Context, config config.Config, rules *warnings.Rules, db *advisories.Database, p batchProvider) BatchProviderVersions {
+	versionList, ingestionWarnings, repoExists, err := fillProviderVersions(ctx, config, p.Location)
+	if err != nil {
+		slog.Error("Error fetching versions from repository", "provider", p.cacheKey(), "error", err)
+		return BatchProviderVersions{ID: p.address(), Errors: []string{"could not fetch versions"}}
+	}
+	if !repoExists {
+		slog.Info("Repo does not exist", "provider", p.cacheKey())
+		return BatchProviderVersions{ID: p.address(), Errors: []string{"not found"}}
+	}
+	return batchProviderResult(config, rules, db, p, &types.CacheItem{Provider: p.cacheKey(), Versions: versionList, IngestionWarnings: ingestionWarnings})
+}
+
+// batchProviderResult removes the yanked versions of a provider and applies the namespace's unsigned release policy, listing them newest first.
+func batchProviderResult(config config.Config, rules *warnings.Rules, db *advisories.Database, p batchProvider, document *types.CacheItem) BatchProviderVersions {
+	versionList, yankWarnings := removeYankedProviderVersions(config, p.Location.Namespace, p.Location.Type, document.Versions)
+	warn := append(rules.ProviderWarnings(p.Namespace, p.Type, versionNumbers(versionList)), providerMoveWarnings(config.ProviderLocation(p.Namespace, p.Type), p.Location)...)
+	warn = append(warn, providerRepositoryWarnings(document)...)
+	warn = append(warn, document.IngestionWarnings...)
+	matches, advisoryWarnings := matchAdvisories(db, providerAddress(p.Location), versionNumbers(versionList))
+	versionList, policyWarnings := providers.ApplyUnsignedReleasePolicy(versionList, config.UnsignedReleasePolicy(p.Location.Namespace))
+	versionList.SortDescending()
+
+	result := BatchProviderVersions{
+		ID:         p.address(),
+		Versions:   versionList.ToVersions(),
+		Warnings:   append(append(append(warn, yankWarnings...), policyWarnings...), advisoryWarnings...),
+		Advisories: matches,
+	}
+	if len(result.Versions) == 0 {
+		result.Errors = []string{"no versions found"}
+	}
+	return result
+}
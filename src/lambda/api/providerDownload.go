@@ -8,12 +8,13 @@ import (
 	"net/http"
 
 	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
 	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
 	"golang.org/x/exp/slog"
 
 	"github.com/aws/aws-lambda-go/events"
 
-	"github.com/opentofu/registry/internal/github"
 	"github.com/opentofu/registry/internal/providers"
 )
 
@@ -50,19 +51,28 @@ func downloadProviderVersion(config config.Config) LambdaFunc {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		params := getDownloadPathParams(req)
 		params.AnnotateLogger()
-		effectiveNamespace := config.EffectiveProviderNamespace(params.Namespace)
-
-		// Construct the repo name.
-		repoName := providers.GetRepoName(params.Type)
+		requested := config.ProviderLocation(params.Namespace, params.Type)
 
 		// For now, we will ignore errors from the cache and just fetch from GH instead
-		document, _ := config.ProviderVersionCache.GetItem(ctx, fmt.Sprintf("%s/%s", effectiveNamespace, params.Type))
+		document, _ := config.ProviderVersionCache.GetItem(ctx, fmt.Sprintf("%s/%s", requested.Namespace, requested.Type))
+		location, document := followProviderMove(ctx, config, requested, document)
+		effectiveNamespace := location.Namespace
+
+		if reason, yanked := config.YankedVersions.Reason(fmt.Sprintf("%s/%s", effectiveNamespace, location.Type), params.Version); yanked {
+			return yankedResponse(params.Version, reason), nil
+		}
+
+		warn := config.Warnings.Rules(ctx).ProviderWarnings(params.Namespace, params.Type, []string{params.Version})
+		_, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), providerAddress(location), []string{params.Version})
+		warn = append(append(warn, providerMoveWarnings(requested, location)...), advisoryWarnings...)
+
 		if document != nil {
-			return processDocumentForProviderDownload(document, effectiveNamespace, params)
+			response, err := processDocumentForProviderDownload(ctx, document, config.ReleaseSourceFor(effectiveNamespace), effectiveNamespace, config.UnsignedReleasePolicy(effectiveNamespace), params)
+			return withWarningHeaders(response, append(warn, providerRepositoryWarnings(document)...)), err
 		}
 
 		// check the repo exists
-		exists, err := github.RepositoryExists(ctx, config.ManagedGithubClient, effectiveNamespace, repoName)
+		exists, err := config.ProviderReleaseSource(location).RepositoryExists(ctx, location.Owner, location.Repository)
 		if err != nil {
 			slog.Error("Error checking if repo exists", "error", err)
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
@@ -73,16 +83,18 @@ func downloadProviderVersion(config config.Config) LambdaFunc {
 		}
 
 		// if the document didn't exist in the cache, trigger the lambda to populate it and return the current results from GH
-		if triggerErr := triggerPopulateProviderVersions(ctx, config, effectiveNamespace, params.Type); triggerErr != nil {
+		if triggerErr := triggerPopulateProviderVersions(ctx, config, effectiveNamespace, location.Type); triggerErr != nil {
 			slog.Error("Error triggering lambda", "error", triggerErr)
 		}
 
-		return fetchVersionFromGithub(ctx, config, effectiveNamespace, repoName, params)
+		response, err := fetchVersionFromGithub(ctx, config, location, params)
+		return withWarningHeaders(response, warn), err
 	}
 }
 
-func fetchVersionFromGithub(ctx context.Context, config config.Config, effectiveNamespace string, repoName string, params DownloadHandlerPathParams) (events.APIGatewayProxyResponse, error) {
-	versionDownloadResponse, err := providers.GetVersion(ctx, config.RawGithubv4Client, effectiveNamespace, repoName, params.Version, params.OS, params.Architecture)
+func fetchVersionFromGithub(ctx context.Context, config config.Config, location mappings.ProviderLocation, params DownloadHandlerPathParams) (events.APIGatewayProxyResponse, error) {
+	policy := config.UnsignedReleasePolicy(location.Namespace)
+	versionDownloadResponse, err := providers.GetVersion(ctx, config.ProviderReleaseSource(location), location.Owner, location.Repository, params.Version, params.OS, params.Architecture, policy)
 	if err != nil {
 		var fetchErr *providers.FetchError
 		// if it's a providers.FetchError
@@ -94,6 +106,10 @@ func fetchVersionFromGithub(ctx context.Context, config config.Config, effective
 		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 	}
 
+	if err := resolveDownloadURLs(ctx, config.ReleaseSourceFor(location.Namespace), versionDownloadResponse); err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+	}
+
 	resBody, err := json.Marshal(versionDownloadResponse)
 	if err != nil {
 		slog.Error("Error marshalling response", "error", err)
@@ -111,10 +127,14 @@ func handleFetchFromGithubErr(err *providers.FetchError) (events.APIGatewayProxy
 		slog.Info("Asset for download not found in release")
 		return NotFoundResponse, nil
 	}
+	if err.Code == providers.ErrCodeSignatureNotFound {
+		slog.Info("Release is not signed and the unsigned release policy requires a signature")
+		return NotFoundResponse, nil
+	}
 	return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 }
 
-func processDocumentForProviderDownload(document *types.CacheItem, effectiveNamespace string, params DownloadHandlerPathParams) (events.APIGatewayProxyResponse, error) {
+func processDocumentForProviderDownload(ctx context.Context, document *types.CacheItem, src source.ReleaseSource, effectiveNamespace string, policy providers.UnsignedReleasePolicy, params DownloadHandlerPathParams) (events.APIGatewayProxyResponse, error) {
 	slog.Info("Found document in cache", "last_updated", document.LastUpdated, "versions", len(document.Versions))
 
 	// try and find the version in the document
@@ -124,6 +144,11 @@ func processDocumentForProviderDownload(document *types.CacheItem, effectiveName
 		return NotFoundResponse, nil
 	}
 
+	if versionDetails.SHASumsSignatureURL == "" && !policy.AllowsUnsigned() {
+		slog.Info("Version is not signed and the unsigned release policy requires a signature, returning 404", "version", params.Version)
+		return NotFoundResponse, nil
+	}
+
 	// attach the signing keys
 	publicKeys, keysErr := providers.KeysForNamespace(effectiveNamespace)
 	if keysErr != nil {
@@ -136,6 +161,10 @@ func processDocumentForProviderDownload(document *types.CacheItem, effectiveName
 
 	versionDetails.SigningKeys = keys
 
+	if err := resolveDownloadURLs(ctx, src, versionDetails); err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+	}
+
 	slog.Info("Found version in document", "version", params.Version)
 	resBody, err := json.Marshal(versionDetails)
 	if err != nil {
@@ -143,3 +172,25 @@ func processDocumentForProviderDownload(document *types.CacheItem, effectiveName
 	}
 	return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
 }
+
+// resolveDownloadURLs replaces the URLs of the version's files by ones clients can download from, for release sources
+// whose URLs need to be resolved whenever they are served.
+func resolveDownloadURLs(ctx context.Context, src source.ReleaseSource, details *types.VersionDetails) error {
+	resolver, ok := src.(source.DownloadURLResolver)
+	if !ok {
+		return nil
+	}
+
+	for _, downloadURL := range []*string{&details.DownloadURL, &details.SHASumsURL, &details.SHASumsSignatureURL} {
+		if *downloadURL == "" {
+			continue
+		}
+		resolved, err := resolver.ResolveDownloadURL(ctx, *downloadURL)
+		if err != nil {
+			slog.Error("Error resolving download URL", "url", *downloadURL, "error", err)
+			return fmt.Errorf("could not resolve download URL: %w", err)
+		}
+		*downloadURL = resolved
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+// ProviderSummary describes a single provider and its latest version.
+type ProviderSummary struct {
+	ID          string     `json:"id"`                     // The address of the provider, "<namespace>/<name>".
+	Namespace   string     `json:"namespace"`              // The namespace of the provider.
+	Name        string     `json:"name"`                   // The type of the provider.
+	Description string     `json:"description"`            // The description of the provider's repository.
+	Source      string     `json:"source"`                 // The web URL of the provider's repository.
+	Version     string     `json:"version"`                // The latest version of the provider.
+	PublishedAt *time.Time `json:"published_at,omitempty"` // The time the latest version was released, if known.
+}
+
+// ProviderVersionSummary describes a single version in the provider metadata response.
+type ProviderVersionSummary struct {
+	Version     string     `json:"version"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+type GetProviderResponse struct {
+	ProviderSummary
+	Versions []ProviderVersionSummary `json:"versions"`
+	Warnings []string                 `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting any of the versions of the provider.
+	Advisories []advisories.Match `json:"advisories,omitempty"`
+
+	// IngestionWarnings describe releases that were left out of the versions, such as tags that are not valid versions.
+	IngestionWarnings []string `json:"ingestion_warnings,omitempty"`
+}
+
+type ListNamespaceProvidersResponse struct {
+	Providers []ProviderSummary `json:"providers"`
+}
+
+func getProvider(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getListProvidersPathParams(req)
+		params.AnnotateLogger()
+
+		requested := config.ProviderLocation(params.Namespace, params.Type)
+
+		// For now, we will ignore errors from the cache and just fetch from the repository instead
+		document, _ := getProviderFromCache(ctx, config, requested.Namespace, requested.Type)
+		location, document := followProviderMove(ctx, config, requested, document)
+		effectiveNamespace, effectiveType := location.Namespace, location.Type
+
+		if document == nil || len(document.Versions) == 0 {
+			versionList, ingestionWarnings, repoExists, err := listVersionsFromRepository(ctx, config, location)
+			if !repoExists {
+				if err != nil {
+					slog.Error("Error checking if repo exists", "error", err)
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+				}
+				slog.Info("Repo does not exist")
+				return NotFoundResponse, nil
+			}
+			if err != nil {
+				slog.Error("Error fetching versions from repository", "error", err)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+			}
+
+			if err := triggerPopulateProviderVersions(ctx, config, effectiveNamespace, effectiveType); err != nil {
+				slog.Error("Error triggering lambda", "error", err)
+			}
+
+			if document == nil {
+				document = &types.CacheItem{Provider: fmt.Sprintf("%s/%s", effectiveNamespace, effectiveType)}
+			}
+			document.Versions = versionList
+			document.IngestionWarnings = ingestionWarnings
+		}
+
+		versionList, yankWarnings := removeYankedProviderVersions(config, effectiveNamespace, effectiveType, document.Versions)
+		warn := append(providerWarnings(ctx, config, params.Namespace, params.Type, versionList), providerMoveWarnings(requested, location)...)
+		warn = append(warn, yankWarnings...)
+
+		versionList, policyWarnings := providers.ApplyUnsignedReleasePolicy(versionList, config.UnsignedReleasePolicy(effectiveNamespace))
+		warn = append(warn, policyWarnings...)
+
+		matches, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), providerAddress(location), versionNumbers(versionList))
+		warn = append(warn, advisoryWarnings...)
+
+		latest := versionList.Latest()
+		if latest == nil {
+			slog.Info("Provider has no versions")
+			return NotFoundResponse, nil
+		}
+
+		// the metadata is only stored by the populate lambda, so fall back to asking the repository directly
+		if document.Description == "" && document.SourceURL == "" {
+			fillRepositoryMetadata(ctx, config, location, document)
+		}
+		warn = append(warn, providerRepositoryWarnings(document)...)
+
+		response := GetProviderResponse{
+			ProviderSummary:   newProviderSummary(params.Namespace, params.Type, document, latest),
+			Versions:          make([]ProviderVersionSummary, 0, len(versionList)),
+			Advisories:        matches,
+			IngestionWarnings: document.IngestionWarnings,
+		}
+
+		versionList.SortDescending()
+		for _, v := range versionList {
+			response.Versions = append(response.Versions, ProviderVersionSummary{
+				Version:     v.Version,
+				PublishedAt: optionalTime(v.PublishedAt),
+			})
+		}
+
+		if len(warn) > 0 {
+			response.Warnings = warn
+		}
+
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
+
+func listNamespaceProviders(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		namespace := req.PathParameters["namespace"]
+		slog.SetDefault(slog.Default().With("namespace", namespace))
+
+		effectiveNamespace := config.EffectiveProviderNamespace(namespace)
+
+		// the providers of a namespace are the ones found by the crawler, whose versions are cached individually
+		index, err := config.ProviderNamespaceIndex.GetItem(ctx, effectiveNamespace)
+		if err != nil {
+			slog.Error("Error getting namespace index", "error", err)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		if index == nil {
+			slog.Info("Namespace has not been crawled")
+			return NotFoundResponse, nil
+		}
+
+		// providers can be mapped to another address individually, which is where their versions are cached
+		locations := make([]mappings.ProviderLocation, 0, len(index.Providers))
+		keys := make([]string, 0, len(index.Providers))
+		for _, indexed := range index.Providers {
+			location := config.ProviderLocation(effectiveNamespace, indexed.Type)
+			locations = append(locations, location)
+			keys = append(keys, fmt.Sprintf("%s/%s", location.Namespace, location.Type))
+		}
+
+		documents, err := config.ProviderVersionCache.BatchGetItems(ctx, keys)
+		if err != nil {
+			slog.Error("Error getting providers from cache", "error", err)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		response := ListNamespaceProvidersResponse{
+			Providers: make([]ProviderSummary, 0, len(index.Providers)),
+		}
+		for i, indexed := range index.Providers {
+			// providers whose versions have not been populated yet are left out
+			document := documents[keys[i]]
+			if document == nil {
+				continue
+			}
+
+			location := locations[i]
+			versionList, _ := removeYankedProviderVersions(config, location.Namespace, location.Type, document.Versions)
+			versionList, _ = providers.ApplyUnsignedReleasePolicy(versionList, config.UnsignedReleasePolicy(location.Namespace))
+			latest := versionList.Latest()
+			if latest == nil {
+				continue
+			}
+
+			if document.Description == "" && document.SourceURL == "" {
+				document.Description = indexed.Description
+				document.SourceURL = indexed.SourceURL
+			}
+			response.Providers = append(response.Providers, newProviderSummary(namespace, indexed.Type, document, latest))
+		}
+
+		if len(response.Providers) == 0 {
+			slog.Info("No providers found in namespace")
+			return NotFoundResponse, nil
+		}
+
+		sort.Slice(response.Providers, func(i, j int) bool {
+			return response.Providers[i].Name < response.Providers[j].Name
+		})
+
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
+
+// fillRepositoryMetadata reads the description, URL and state of the provider's repository from its release source, if supported.
+// Errors are only logged, as the metadata is not essential to the response.
+func fillRepositoryMetadata(ctx context.Context, config config.Config, location mappings.ProviderLocation, document *types.CacheItem) {
+	metadataSource, ok := config.ReleaseSourceFor(location.Namespace).(source.MetadataSource)
+	if !ok {
+		return
+	}
+
+	metadata, err := metadataSource.RepositoryMetadata(ctx, location.Owner, location.Repository)
+	if err != nil {
+		slog.Error("Error getting repository metadata", "error", err)
+		return
+	}
+	if metadata == nil {
+		return
+	}
+
+	document.Description = metadata.Description
+	document.SourceURL = metadata.URL
+	document.Archived = metadata.Archived
+	document.Disabled = metadata.Disabled
+	// the location may already be the one the repository moved to
+	if metadata.MovedTo != "" {
+		document.MovedTo = metadata.MovedTo
+	}
+}
+
+// newProviderSummary describes a provider by the namespace it was requested with, rather than its effective namespace.
+func newProviderSummary(namespace, providerType string, document *types.CacheItem, latest *types.CacheVersion) ProviderSummary {
+	return ProviderSummary{
+		ID:          fmt.Sprintf("%s/%s", namespace, providerType),
+		Namespace:   namespace,
+		Name:        providerType,
+		Description: document.Description,
+		Source:      document.SourceURL,
+		Version:     latest.Version,
+		PublishedAt: optionalTime(latest.PublishedAt),
+	}
+}
+
+// optionalTime returns nil for the zero time, so that unknown times are left out of responses.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
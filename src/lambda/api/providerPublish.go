@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/publish"
+	"golang.org/x/exp/slog"
+)
+
+type PublishProviderPathParams struct {
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Version   string `json:"version"`
+}
+
+func (p PublishProviderPathParams) AnnotateLogger() {
+	logger := slog.Default()
+	logger = logger.
+		With("namespace", p.Namespace).
+		With("type", p.Type).
+		With("version", p.Version)
+	slog.SetDefault(logger)
+}
+
+func getPublishProviderPathParams(req events.APIGatewayProxyRequest) PublishProviderPathParams {
+	return PublishProviderPathParams{
+		Namespace: req.PathParameters["namespace"],
+		Type:      req.PathParameters["type"],
+		Version:   req.PathParameters["version"],
+	}
+}
+
+// publishProviderVersion accepts the release files of a provider version as a multipart/form-data upload,
+// stores them in the artifact store and adds the version to the provider version cache.
+func publishProviderVersion(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getPublishProviderPathParams(req)
+		params.AnnotateLogger()
+
+		if rejection := authorizePublish(config, params.Namespace, req); rejection != nil {
+			return *rejection, nil
+		}
+
+		files, err := readUploadedFiles(req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		publisher := publish.NewPublisher(config.ArtifactStore, config.ProviderVersionCache)
+		version, err := publisher.PublishProvider(ctx, publish.ProviderVersion{
+			Namespace: params.Namespace,
+			Type:      params.Type,
+			Version:   params.Version,
+			Files:     files,
+		}, config.UnsignedReleasePolicy(params.Namespace))
+		if err != nil {
+			return publishErrorResponse(err)
+		}
+
+		resBody, err := json.Marshal(version.ToVersion())
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusCreated, Body: string(resBody)}, nil
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/semver"
+	"golang.org/x/exp/slog"
+)
+
+// ResolveProviderVersionResponse describes the highest provider version matching a version constraint.
+type ResolveProviderVersionResponse struct {
+	Version   string   `json:"version"`
+	Protocols []string `json:"protocols"`
+	// Download holds the download details of the version, if an os and architecture were requested.
+	Download *types.VersionDetails `json:"download,omitempty"`
+	Warnings []string              `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting the resolved version.
+	Advisories []advisories.Match `json:"advisories,omitempty"`
+}
+
+func resolveProviderVersion(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		params := getListProvidersPathParams(req)
+		params.AnnotateLogger()
+
+		constraint := req.QueryStringParameters["constraint"]
+		constraints, err := semver.ParseConstraints(constraint)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		// the download details are only returned for a specific platform
+		filter := providers.VersionFilter{OS: req.QueryStringParameters["os"], Arch: req.QueryStringParameters["arch"]}
+		if (filter.OS == "") != (filter.Arch == "") {
+			return errorResponse(http.StatusBadRequest, "os and arch must be given together"), nil
+		}
+
+		requested := config.ProviderLocation(params.Namespace, params.Type)
+		location, document, repoExists, err := loadProviderVersions(ctx, config, requested)
+		if !repoExists {
+			if err != nil {
+				slog.Error("Error checking if repo exists", "error", err)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+			}
+			slog.Info("Repo does not exist")
+			return NotFoundResponse, nil
+		}
+		if err != nil {
+			slog.Error("Error fetching versions from github", "error", err)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		effectiveNamespace := location.Namespace
+		versionList, warn := removeYankedProviderVersions(config, effectiveNamespace, location.Type, document.Versions)
+
+		versionList, policyWarnings := providers.ApplyUnsignedReleasePolicy(versionList, config.UnsignedReleasePolicy(effectiveNamespace))
+		warn = append(warn, policyWarnings...)
+
+		resolved := filter.Apply(versionList).Resolve(constraints)
+		if resolved == nil {
+			slog.Info("No version matches the constraint", "constraint", constraint)
+			return errorResponse(http.StatusNotFound, fmt.Sprintf("no version of %s/%s matches %q", params.Namespace, params.Type, constraint)), nil
+		}
+
+		providerWarn := append(providerWarnings(ctx, config, params.Namespace, params.Type, types.VersionList{*resolved}), providerMoveWarnings(requested, location)...)
+		warn = append(append(providerWarn, providerRepositoryWarnings(document)...), warn...)
+		warn = append(warn, document.IngestionWarnings...)
+		matches, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), providerAddress(location), []string{resolved.Version})
+		warn = append(warn, advisoryWarnings...)
+
+		response := ResolveProviderVersionResponse{
+			Version:    resolved.Version,
+			Protocols:  resolved.Protocols,
+			Advisories: matches,
+		}
+
+		if filter.OS != "" {
+			response.Download = resolved.GetVersionDetails(filter.OS, filter.Arch)
+			if resolveErr := resolveDownloadURLs(ctx, config.ReleaseSourceFor(location.Namespace), response.Download); resolveErr != nil {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, resolveErr
+			}
+
+			publicKeys, keysErr := providers.KeysForNamespace(effectiveNamespace)
+			if keysErr != nil {
+				slog.Error("Could not get public keys", "error", keysErr)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, keysErr
+			}
+			response.Download.SigningKeys = types.SigningKeys{GPGPublicKeys: publicKeys}
+		}
+
+		if len(warn) > 0 {
+			response.Warnings = warn
+		}
+
+		slog.Info("Resolved version", "constraint", constraint, "version", resolved.Version)
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
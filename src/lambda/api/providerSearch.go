@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/search"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// searchIndexMaxAge is how long the search index is kept in memory before it is rebuilt from the namespace indexes.
+	searchIndexMaxAge = 10 * time.Minute
+)
+
+// searchIndexCache keeps the search index between invocations of the lambda.
+type searchIndexCache struct {
+	mu      sync.Mutex
+	index   *search.Index
+	builtAt time.Time
+}
+
+//nolint:gochecknoglobals // The index has to outlive a single request to be worth building.
+var providerSearchIndex = &searchIndexCache{}
+
+func (c *searchIndexCache) get(ctx context.Context, config config.Config) (*search.Index, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index != nil && time.Since(c.builtAt) < searchIndexMaxAge {
+		return c.index, nil
+	}
+
+	namespaces, err := config.ProviderNamespaceIndex.List(ctx)
+	if err != nil {
+		// keep serving the previous index, if there is one, rather than failing every search
+		if c.index != nil {
+			slog.Error("Error rebuilding search index, using the previous one", "error", err)
+			return c.index, nil
+		}
+		return nil, err
+	}
+
+	c.index = search.NewIndex(namespaces, config.ProviderTier)
+	c.builtAt = time.Now()
+	slog.Info("Built search index", "providers", c.index.Len())
+	return c.index, nil
+}
+
+type SearchProvidersMeta struct {
+	Limit         int  `json:"limit"`
+	CurrentOffset int  `json:"current_offset"`
+	NextOffset    *int `json:"next_offset,omitempty"`
+	Total         int  `json:"total"`
+}
+
+type SearchProvidersResult struct {
+	ID          string   `json:"id"`
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Source      string   `json:"source"`
+	Tier        string   `json:"tier"`
+	Topics      []string `json:"topics,omitempty"`
+}
+
+type SearchProvidersResponse struct {
+	Meta      SearchProvidersMeta     `json:"meta"`
+	Providers []SearchProvidersResult `json:"providers"`
+}
+
+func searchProviders(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		query, err := getSearchQuery(config, req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		slog.Info("Searching providers", "q", query.Text, "namespace", query.Namespace, "tier", query.Tier, "offset", query.Offset, "limit", query.Limit)
+
+		index, err := providerSearchIndex.get(ctx, config)
+		if err != nil {
+			slog.Error("Error building search index", "error", err)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		results := index.Search(query)
+
+		response := SearchProvidersResponse{
+			Meta: SearchProvidersMeta{
+				Limit:         query.Limit,
+				CurrentOffset: query.Offset,
+				Total:         results.Total,
+			},
+			Providers: make([]SearchProvidersResult, 0, len(results.Hits)),
+		}
+		if next := query.Offset + query.Limit; next < results.Total {
+			response.Meta.NextOffset = &next
+		}
+
+		for _, hit := range results.Hits {
+			response.Providers = append(response.Providers, SearchProvidersResult{
+				ID:          fmt.Sprintf("%s/%s", hit.Namespace, hit.Type),
+				Namespace:   hit.Namespace,
+				Name:        hit.Type,
+				Description: hit.Description,
+				Source:      hit.SourceURL,
+				Tier:        hit.Tier,
+				Topics:      hit.Topics,
+			})
+		}
+
+		resBody, err := json.Marshal(response)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+		}
+
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: string(resBody)}, nil
+	}
+}
+
+func getSearchQuery(config config.Config, req events.APIGatewayProxyRequest) (search.Query, error) {
+	query := search.Query{
+		Text:  req.QueryStringParameters["q"],
+		Limit: defaultSearchLimit,
+	}
+
+	if namespace := req.QueryStringParameters["namespace"]; namespace != "" {
+		query.Namespace = config.EffectiveProviderNamespace(namespace)
+	}
+
+	if tier := req.QueryStringParameters["tier"]; tier != "" {
+		parsedTier, err := search.ParseTier(tier)
+		if err != nil {
+			return query, err
+		}
+		query.Tier = parsedTier
+	}
+
+	if rawLimit := req.QueryStringParameters["limit"]; rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			return query, fmt.Errorf("limit must be a number between 1 and %d", maxSearchLimit)
+		}
+		query.Limit = limit
+	}
+
+	if rawOffset := req.QueryStringParameters["offset"]; rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return query, fmt.Errorf("offset must be a non-negative number")
+		}
+		query.Offset = offset
+	}
+
+	return query, nil
+}
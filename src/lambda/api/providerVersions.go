@@ -2,19 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/opentofu/registry/internal/advisories"
 	"github.com/opentofu/registry/internal/config"
-	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/jobs"
+	"github.com/opentofu/registry/internal/mappings"
 	"github.com/opentofu/registry/internal/providers"
 	"github.com/opentofu/registry/internal/providers/types"
-	"github.com/opentofu/registry/internal/warnings"
 	"golang.org/x/exp/slog"
 )
 
@@ -38,9 +39,82 @@ func getListProvidersPathParams(req events.APIGatewayProxyRequest) ListProviders
 	}
 }
 
+// ListProviderVersionsQuery holds the optional query parameters of the versions listing.
+type ListProviderVersionsQuery struct {
+	Filter providers.VersionFilter
+	// Limit is the maximum number of versions in a page. Responses are only paginated if it is set.
+	Limit int
+	// Cursor is the last version of the previous page.
+	Cursor string
+}
+
+// ListProviderVersionsMeta is only included in paginated responses.
+type ListProviderVersionsMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 type ListProviderVersionsResponse struct {
 	Versions []types.Version `json:"versions"`
 	Warnings []string        `json:"warnings,omitempty"`
+	// Advisories are the security advisories affecting any of the versions of the provider.
+	Advisories []advisories.Match        `json:"advisories,omitempty"`
+	Meta       *ListProviderVersionsMeta `json:"meta,omitempty"`
+}
+
+const maxVersionsLimit = 100
+
+func getListProviderVersionsQuery(req events.APIGatewayProxyRequest) (ListProviderVersionsQuery, error) {
+	var query ListProviderVersionsQuery
+	params := req.QueryStringParameters
+
+	if protocol := params["protocol"]; protocol != "" {
+		major, err := providers.ParseProtocolMajor(protocol)
+		if err != nil {
+			return query, err
+		}
+		query.Filter.ProtocolMajor = major
+	}
+
+	query.Filter.OS = params["os"]
+	query.Filter.Arch = params["arch"]
+
+	if rawSince := params["since"]; rawSince != "" {
+		since, err := time.Parse(time.RFC3339, rawSince)
+		if err != nil {
+			since, err = time.Parse(time.DateOnly, rawSince)
+		}
+		if err != nil {
+			return query, fmt.Errorf("since must be an RFC 3339 timestamp or a date")
+		}
+		query.Filter.Since = &since
+	}
+
+	if rawInclude := params["include_prereleases"]; rawInclude != "" {
+		include, err := strconv.ParseBool(rawInclude)
+		if err != nil {
+			return query, fmt.Errorf("include_prereleases must be true or false")
+		}
+		query.Filter.ExcludePrereleases = !include
+	}
+
+	if rawLimit := params["limit"]; rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxVersionsLimit {
+			return query, fmt.Errorf("limit must be a number between 1 and %d", maxVersionsLimit)
+		}
+		query.Limit = limit
+	}
+
+	if rawCursor := params["cursor"]; rawCursor != "" {
+		cursor, err := base64.RawURLEncoding.DecodeString(rawCursor)
+		if err != nil {
+			return query, fmt.Errorf("invalid cursor")
+		}
+		query.Cursor = string(cursor)
+	}
+
+	return query, nil
 }
 
 func listProviderVersions(config config.Config) LambdaFunc {
@@ -48,18 +122,13 @@ func listProviderVersions(config config.Config) LambdaFunc {
 		params := getListProvidersPathParams(req)
 		params.AnnotateLogger()
 
-		effectiveNamespace := config.EffectiveProviderNamespace(params.Namespace)
-
-		// Warnings lookup: https://github.com/opentofu/registry/issues/108
-		warn := warnings.ProviderWarnings(params.Namespace, params.Type)
-
-		// For now, we will ignore errors from the cache and just fetch from GH instead
-		versionList, _ := listVersionsFromCache(ctx, config, effectiveNamespace, params.Type)
-		if len(versionList) > 0 {
-			return versionsResponse(versionList, warn)
+		query, err := getListProviderVersionsQuery(req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
 		}
 
-		versionList, repoExists, err := listVersionsFromRepository(ctx, config, effectiveNamespace, params.Type)
+		requested := config.ProviderLocation(params.Namespace, params.Type)
+		location, document, repoExists, err := loadProviderVersions(ctx, config, requested)
 		if !repoExists {
 			if err != nil {
 				slog.Error("Error checking if repo exists", "error", err)
@@ -74,22 +143,86 @@ func listProviderVersions(config config.Config) LambdaFunc {
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
 		}
 
-		// if the document didn't exist in the cache, trigger the lambda to populate it
-		if err := triggerPopulateProviderVersions(ctx, config, effectiveNamespace, params.Type); err != nil {
-			slog.Error("Error triggering lambda", "error", err)
-		}
+		effectiveNamespace := location.Namespace
+		policy := config.UnsignedReleasePolicy(effectiveNamespace)
+
+		versionList, yankWarnings := removeYankedProviderVersions(config, effectiveNamespace, location.Type, document.Versions)
+		warn := append(providerWarnings(ctx, config, params.Namespace, params.Type, versionList), providerMoveWarnings(requested, location)...)
+		warn = append(warn, providerRepositoryWarnings(document)...)
+		warn = append(warn, yankWarnings...)
+		warn = append(warn, document.IngestionWarnings...)
+
+		matches, advisoryWarnings := matchAdvisories(config.Advisories.Database(ctx), providerAddress(location), versionNumbers(versionList))
+		warn = append(warn, advisoryWarnings...)
 
-		return versionsResponse(versionList, warn)
+		return versionsResponse(versionList, policy, warn, matches, query)
 	}
 }
 
-// listVersionsFromCache retrieves version details for a given effective namespace and provider type from the cache.
-// - If the cached document is not present or there's an error during retrieval, the function returns an error.
-// - If the cached document is present and is not stale, the cached versions are returned directly.
+// loadProviderVersions returns the location and cached document of a provider, falling back to a document holding
+// only the versions listed from the repository if the provider is not cached yet. If the provider's repository was
+// recorded as moved to the repository of another provider, that provider's location and document are returned.
+// The returned bool is false if the versions were not cached and the repository does not exist.
+func loadProviderVersions(ctx context.Context, config config.Config, location mappings.ProviderLocation) (mappings.ProviderLocation, *types.CacheItem, bool, error) {
+	// For now, we will ignore errors from the cache and just fetch from GH instead
+	document, _ := getProviderFromCache(ctx, config, location.Namespace, location.Type)
+	location, document = followProviderMove(ctx, config, location, document)
+	if document != nil && len(document.Versions) > 0 {
+		return location, document, true, nil
+	}
+
+	versionList, ingestionWarnings, repoExists, err := fillProviderVersions(ctx, config, location)
+	if !repoExists || err != nil {
+		return location, nil, repoExists, err
+	}
+	return location, &types.CacheItem{
+		Provider:          fmt.Sprintf("%s/%s", location.Namespace, location.Type),
+		Versions:          versionList,
+		IngestionWarnings: ingestionWarnings,
+	}, true, nil
+}
+
+// followProviderMove returns the location a provider is read from after its repository was recorded as renamed or
+// transferred by the populate lambda. If the repository moved to the repository of another provider, the cached
+// document of that provider is returned as well, or nil if it is not cached yet.
+func followProviderMove(ctx context.Context, config config.Config, location mappings.ProviderLocation, document *types.CacheItem) (mappings.ProviderLocation, *types.CacheItem) {
+	if document == nil || document.MovedTo == "" {
+		return location, document
+	}
+
+	moved, changed := config.MovedProviderLocation(location, document.MovedTo)
+	if !changed {
+		return moved, document
+	}
+
+	slog.Info("Provider has moved, serving the provider it moved to", "moved_to", document.MovedTo, "namespace", moved.Namespace, "type", moved.Type)
+	movedDocument, _ := getProviderFromCache(ctx, config, moved.Namespace, moved.Type)
+	return moved, movedDocument
+}
+
+// fillProviderVersions returns the versions of a provider that is not cached yet from its repository,
+// and triggers the lambda to populate the cache with them.
+func fillProviderVersions(ctx context.Context, config config.Config, location mappings.ProviderLocation) (types.VersionList, []string, bool, error) {
+	versionList, ingestionWarnings, repoExists, err := listVersionsFromRepository(ctx, config, location)
+	if !repoExists || err != nil {
+		return nil, nil, repoExists, err
+	}
+
+	// if the document didn't exist in the cache, trigger the lambda to populate it
+	if err := triggerPopulateProviderVersions(ctx, config, location.Namespace, location.Type); err != nil {
+		slog.Error("Error triggering lambda", "error", err)
+	}
+
+	return versionList, ingestionWarnings, true, nil
+}
+
+// getProviderFromCache retrieves the document of a given effective namespace and provider type from the cache.
+// - If the cached document is not present or there's an error during retrieval, the function returns nil.
+// - If the cached document is present and is not stale, it is returned directly.
 // - If the cached document is present and is detected as stale:
 //   - An asynchronous update via a lambda function is triggered.
-//   - The stale version details are returned.
-func listVersionsFromCache(ctx context.Context, config config.Config, effectiveNamespace, providerType string) ([]types.Version, error) {
+//   - The stale document is returned.
+func getProviderFromCache(ctx context.Context, config config.Config, effectiveNamespace, providerType string) (*types.CacheItem, error) {
 	document, err := config.ProviderVersionCache.GetItem(ctx, fmt.Sprintf("%s/%s", effectiveNamespace, providerType))
 	if err != nil || document == nil {
 		return nil, err
@@ -105,40 +238,71 @@ func listVersionsFromCache(ctx context.Context, config config.Config, effectiveN
 		}
 	}
 
-	// if it's stale or not, we still return the cached versions
-	return document.Versions.ToVersions(), nil
+	// if it's stale or not, we still return the cached document
+	return document, nil
 }
 
-func listVersionsFromRepository(ctx context.Context, config config.Config, effectiveNamespace, providerType string) ([]types.Version, bool, error) {
-	repoName := providers.GetRepoName(providerType)
-	exists, err := github.RepositoryExists(ctx, config.ManagedGithubClient, effectiveNamespace, repoName)
+// listVersionsFromRepository returns the versions of a provider listed from its repository, along with warnings
+// describing the releases that were skipped.
+func listVersionsFromRepository(ctx context.Context, config config.Config, location mappings.ProviderLocation) (types.VersionList, []string, bool, error) {
+	src := config.ProviderReleaseSource(location)
+	exists, err := src.RepositoryExists(ctx, location.Owner, location.Repository)
 	if err != nil {
-		return nil, exists, err
+		return nil, nil, exists, err
 	}
 
 	slog.Info("Fetching versions from github\n")
-	versionList, err := providers.GetVersions(ctx, config.RawGithubv4Client, effectiveNamespace, repoName, nil)
-	return versionList.ToVersions(), exists, err
+	versionList, ingestionWarnings, err := providers.GetVersions(ctx, src, location.Owner, location.Repository, nil)
+	if len(ingestionWarnings) > 0 {
+		slog.Warn("Some releases were skipped", "warnings", ingestionWarnings)
+	}
+	return versionList, ingestionWarnings, exists, err
 }
 
 func triggerPopulateProviderVersions(ctx context.Context, config config.Config, effectiveNamespace string, effectiveType string) error {
-	slog.Info("Invoking populate provider versions lambda asynchronously to update dynamodb document\n")
-	// invoke the async lambda to update the dynamodb document
-	_, err := config.LambdaClient.Invoke(ctx, &lambda.InvokeInput{
-		FunctionName:   aws.String(os.Getenv("POPULATE_PROVIDER_VERSIONS_FUNCTION_NAME")),
-		InvocationType: "Event", // Event == async
-		Payload:        []byte(fmt.Sprintf("{\"namespace\": \"%s\", \"type\": \"%s\"}", effectiveNamespace, effectiveType)),
-	})
-	if err != nil {
-		slog.Error("Error invoking lambda", "error", err)
-		return err
-	}
-	return nil
+	return jobs.PopulateProviderVersions{Namespace: effectiveNamespace, Type: effectiveType}.Dispatch(ctx, config.PopulateDispatcher)
+}
+
+// forcePopulateProviderVersions triggers the lambda to populate the versions of a provider even if its cached document
+// is up to date, such as when a new release was just published. trigger identifies the event forcing it, so that the
+// jobs forced by distinct events are not dropped as duplicates of each other.
+func forcePopulateProviderVersions(ctx context.Context, config config.Config, effectiveNamespace string, effectiveType string, trigger string) error {
+	job := jobs.PopulateProviderVersions{Namespace: effectiveNamespace, Type: effectiveType, Force: true, Trigger: trigger}
+	return job.Dispatch(ctx, config.PopulateDispatcher)
 }
 
-func versionsResponse(versions []types.Version, warnings []string) (events.APIGatewayProxyResponse, error) {
+// versionsResponse builds the versions listing response, applying the namespace's unsigned release policy
+// and the query's filters to both cached and freshly fetched versions alike. Versions are listed newest first.
+func versionsResponse(versionList types.VersionList, policy providers.UnsignedReleasePolicy, warnings []string, matches []advisories.Match, query ListProviderVersionsQuery) (events.APIGatewayProxyResponse, error) {
+	versionList, policyWarnings := providers.ApplyUnsignedReleasePolicy(versionList, policy)
+	warnings = append(warnings, policyWarnings...)
+
+	versionList = query.Filter.Apply(versionList)
+	versionList.SortDescending()
+
+	var meta *ListProviderVersionsMeta
+	if query.Cursor != "" {
+		var ok bool
+		if versionList, ok = versionList.After(query.Cursor); !ok {
+			return errorResponse(http.StatusBadRequest, "invalid cursor"), nil
+		}
+	}
+	if query.Limit > 0 {
+		meta = &ListProviderVersionsMeta{Limit: query.Limit}
+		if len(versionList) > query.Limit {
+			versionList = versionList[:query.Limit]
+			meta.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(versionList[len(versionList)-1].Version))
+		}
+	}
+
 	response := ListProviderVersionsResponse{
-		Versions: versions,
+		Versions:   versionList.ToVersions(),
+		Advisories: matches,
+		Meta:       meta,
+	}
+	// the protocol requires a list, even if there are no versions
+	if response.Versions == nil {
+		response.Versions = []types.Version{}
 	}
 
 	if len(warnings) > 0 {
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/publish"
+	"golang.org/x/exp/slog"
+)
+
+// authorizePublish checks that publishing is enabled for the namespace and that the request carries its publish token.
+// It returns a non-nil response if the request must be rejected.
+func authorizePublish(config config.Config, namespace string, req events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	if !config.PublishingEnabled(namespace) {
+		slog.Info("Publishing is not enabled for namespace")
+		return &NotFoundResponse
+	}
+
+	token := strings.TrimPrefix(getHeader(req, "Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(config.PublishTokens[namespace])) != 1 {
+		slog.Info("Rejecting publish request with invalid token")
+		response := errorResponse(http.StatusUnauthorized, "invalid or missing token")
+		return &response
+	}
+
+	return nil
+}
+
+// readUploadedFiles reads the files of a multipart/form-data request body, keyed by their filename.
+// Note that API Gateway and Lambda limit request bodies to around 6MB, which also bounds the size of uploads.
+func readUploadedFiles(req events.APIGatewayProxyRequest) (map[string][]byte, error) {
+	mediaType, mediaParams, err := mime.ParseMediaType(getHeader(req, "Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, fmt.Errorf("expected a multipart/form-data request")
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	reader := multipart.NewReader(bytes.NewReader(body), mediaParams["boundary"])
+	for {
+		part, partErr := reader.NextPart()
+		if errors.Is(partErr, io.EOF) {
+			break
+		}
+		if partErr != nil {
+			return nil, fmt.Errorf("could not read request body: %w", partErr)
+		}
+
+		filename := part.FileName()
+		if filename == "" {
+			continue
+		}
+		if _, duplicate := files[filename]; duplicate {
+			return nil, fmt.Errorf("%s was uploaded more than once", filename)
+		}
+
+		contents, readErr := io.ReadAll(part)
+		if readErr != nil {
+			return nil, fmt.Errorf("could not read %s: %w", filename, readErr)
+		}
+		files[filename] = contents
+	}
+
+	return files, nil
+}
+
+// readRequestBody returns the raw request body, decoding it if API Gateway passed it base64 encoded.
+func readRequestBody(req events.APIGatewayProxyRequest) ([]byte, error) {
+	if !req.IsBase64Encoded {
+		return []byte(req.Body), nil
+	}
+
+	body, err := base64.StdEncoding.DecodeString(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode request body: %w", err)
+	}
+	return body, nil
+}
+
+// publishErrorResponse maps errors returned by the publisher to a response.
+func publishErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
+	var validationErr *publish.ValidationError
+	if errors.As(err, &validationErr) {
+		slog.Info("Rejecting invalid upload", "reason", validationErr.Reason)
+		return errorResponse(http.StatusBadRequest, validationErr.Reason), nil
+	}
+	if errors.Is(err, publish.ErrVersionExists) {
+		slog.Info("Rejecting upload of existing version")
+		return errorResponse(http.StatusConflict, err.Error()), nil
+	}
+
+	slog.Error("Error publishing version", "error", err)
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+}
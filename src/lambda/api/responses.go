@@ -1,10 +1,28 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
 //nolint:gochecknoglobals // This should be treated as a constant.
 var NotFoundResponse = events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound, Body: `{"errors":["not found"]}`}
+
+// errorResponse builds a response in the same format as NotFoundResponse with the given status code and message.
+func errorResponse(statusCode int, message string) events.APIGatewayProxyResponse {
+	body, _ := json.Marshal(map[string][]string{"errors": {message}})
+	return events.APIGatewayProxyResponse{StatusCode: statusCode, Body: string(body)}
+}
+
+// getHeader returns the value of a request header, matching its name case-insensitively.
+func getHeader(req events.APIGatewayProxyRequest, name string) string {
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
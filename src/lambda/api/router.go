@@ -14,35 +14,87 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 )
 
-func RouteHandlers(config config.Config) map[string]LambdaFunc {
-	return map[string]LambdaFunc{
+// Route maps requests with the given method and a path matching the given pattern to a handler.
+type Route struct {
+	Method  string
+	Pattern string
+	Handler LambdaFunc
+}
+
+// RouteHandlers returns the routes of the registry API. Routes are matched in order,
+// so more specific patterns must come before the ones they overlap with.
+func RouteHandlers(config config.Config) []Route {
+	return []Route{
 		// Download provider version
 		// `/v1/providers/{namespace}/{type}/{version}/download/{os}/{arch}`
-		"^/v1/providers/[^/]+/[^/]+/[^/]+/download/[^/]+/[^/]+$": downloadProviderVersion(config),
+		{http.MethodGet, "^/v1/providers/[^/]+/[^/]+/[^/]+/download/[^/]+/[^/]+$", downloadProviderVersion(config)},
 
 		// List provider versions
 		// `/v1/providers/{namespace}/{type}/versions`
-		"^/v1/providers/[^/]+/[^/]+/versions$": listProviderVersions(config),
+		{http.MethodGet, "^/v1/providers/[^/]+/[^/]+/versions$", listProviderVersions(config)},
+
+		// Resolve a provider version constraint
+		// `/v1/providers/{namespace}/{type}/resolve?constraint={constraint}&os={os}&arch={arch}`
+		{http.MethodGet, "^/v1/providers/[^/]+/[^/]+/resolve$", resolveProviderVersion(config)},
+
+		// Publish provider version
+		// `/v1/providers/{namespace}/{type}/versions/{version}`
+		{http.MethodPost, "^/v1/providers/[^/]+/[^/]+/versions/[^/]+$", publishProviderVersion(config)},
+
+		// Search providers
+		// `/v1/providers/search?q={query}&namespace={namespace}&tier={tier}&limit={limit}&offset={offset}`
+		{http.MethodGet, "^/v1/providers/search$", searchProviders(config)},
+
+		// Batch list provider versions
+		// `/v1/providers/batch`
+		{http.MethodPost, "^/v1/providers/batch$", batchProviderVersions(config)},
+
+		// Get provider metadata
+		// `/v1/providers/{namespace}/{type}`
+		{http.MethodGet, "^/v1/providers/[^/]+/[^/]+$", getProvider(config)},
+
+		// List providers in a namespace, must come after the search route
+		// `/v1/providers/{namespace}`
+		{http.MethodGet, "^/v1/providers/[^/]+$", listNamespaceProviders(config)},
+
+		// List the security advisories of a provider
+		// `/v1/advisories/{namespace}/{type}`
+		{http.MethodGet, "^/v1/advisories/[^/]+/[^/]+$", listProviderAdvisories(config)},
 
 		// List module versions
 		// `/v1/modules/{namespace}/{name}/{system}/versions`
-		"^/v1/modules/[^/]+/[^/]+/[^/]+/versions$": listModuleVersions(config),
+		{http.MethodGet, "^/v1/modules/[^/]+/[^/]+/[^/]+/versions$", listModuleVersions(config)},
+
+		// Resolve a module version constraint
+		// `/v1/modules/{namespace}/{name}/{system}/resolve?constraint={constraint}`
+		{http.MethodGet, "^/v1/modules/[^/]+/[^/]+/[^/]+/resolve$", resolveModuleVersion(config)},
+
+		// Publish module version
+		// `/v1/modules/{namespace}/{name}/{system}/versions/{version}`
+		{http.MethodPost, "^/v1/modules/[^/]+/[^/]+/[^/]+/versions/[^/]+$", publishModuleVersion(config)},
 
 		// Download module version
 		// `/v1/modules/{namespace}/{name}/{system}/{version}/download`
-		"^/v1/modules/[^/]+/[^/]+/[^/]+/[^/]+/download$": downloadModuleVersion(config),
+		{http.MethodGet, "^/v1/modules/[^/]+/[^/]+/[^/]+/[^/]+/download$", downloadModuleVersion(config)},
+
+		// Receive GitHub webhook deliveries
+		// `/v1/webhooks/github`
+		{http.MethodPost, "^/v1/webhooks/github$", githubWebhook(config)},
 
 		// .well-known/terraform.json
-		"^/.well-known/terraform.json$": terraformWellKnownMetadataHandler(config),
+		{http.MethodGet, "^/.well-known/terraform.json$", terraformWellKnownMetadataHandler(config)},
 	}
 }
 
-func getRouteHandler(config config.Config, path string) LambdaFunc {
+func getRouteHandler(config config.Config, method, path string) LambdaFunc {
 	// We will replace this with some sort of actual router (chi, gorilla, etc)
 	// for now regex is fine
-	for route, handler := range RouteHandlers(config) {
-		if match, _ := regexp.MatchString(route, path); match {
-			return handler
+	for _, route := range RouteHandlers(config) {
+		if route.Method != method {
+			continue
+		}
+		if match, _ := regexp.MatchString(route.Pattern, path); match {
+			return route.Handler
 		}
 	}
 	return nil
@@ -55,10 +107,11 @@ func Router(config config.Config) LambdaFunc {
 		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 		logger = logger.
 			With("request_id", req.RequestContext.RequestID).
+			With("method", req.HTTPMethod).
 			With("path", req.Path)
 		slog.SetDefault(logger)
 
-		handler := getRouteHandler(config, req.Path)
+		handler := getRouteHandler(config, req.HTTPMethod, req.Path)
 		if handler == nil {
 			slog.Error("No route handler found for path")
 			return events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound, Body: fmt.Sprintf("No route handler found for path %s", req.Path)}, nil
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
+	"github.com/opentofu/registry/internal/warnings"
+	"golang.org/x/exp/slog"
+)
+
+// providerWarnings returns the warnings of a provider, identified by the address requested by the client,
+// for the versions being served.
+func providerWarnings(ctx context.Context, config config.Config, namespace, providerType string, versionList types.VersionList) []string {
+	return config.Warnings.Rules(ctx).ProviderWarnings(namespace, providerType, versionNumbers(versionList))
+}
+
+func versionNumbers(versionList types.VersionList) []string {
+	versions := make([]string, 0, len(versionList))
+	for _, v := range versionList {
+		versions = append(versions, v.Version)
+	}
+	return versions
+}
+
+// moduleWarnings returns the warnings of a module, identified by the address requested by the client,
+// for the versions being served.
+func moduleWarnings(ctx context.Context, config config.Config, namespace, name, system string, versions []modules.Version) []string {
+	return config.Warnings.Rules(ctx).ModuleWarnings(namespace, name, system, moduleVersionNumbers(versions))
+}
+
+func moduleVersionNumbers(versions []modules.Version) []string {
+	raw := make([]string, 0, len(versions))
+	for _, v := range versions {
+		raw = append(raw, v.Version)
+	}
+	return raw
+}
+
+// providerRepositoryWarnings returns the deprecation warnings of a provider whose repository was recorded as archived,
+// disabled or moved by the populate lambda.
+func providerRepositoryWarnings(document *types.CacheItem) []string {
+	return warnings.RepositoryWarnings("provider", warnings.Repository{
+		Archived: document.Archived,
+		Disabled: document.Disabled,
+		MovedTo:  document.MovedTo,
+	})
+}
+
+// providerMoveWarnings returns a warning if a provider is served from another address than the one it was requested
+// at, because its repository was renamed or transferred to the repository of another provider.
+func providerMoveWarnings(requested, location mappings.ProviderLocation) []string {
+	if requested.Namespace == location.Namespace && requested.Type == location.Type {
+		return nil
+	}
+	return []string{fmt.Sprintf("This provider has moved to %s/%s. Update the source address of the provider in your configuration.", location.Namespace, location.Type)}
+}
+
+// checkModuleRepository checks that the repository of a module exists, returning deprecation warnings if it is archived,
+// disabled or has moved. Modules whose repository was renamed or transferred are read from the returned location.
+// The state of the repository is only known if its release source can describe repositories.
+func checkModuleRepository(ctx context.Context, config config.Config, location mappings.ModuleLocation) (mappings.ModuleLocation, bool, []string, error) {
+	metadataSource, ok := config.ReleaseSourceFor(location.Namespace).(source.MetadataSource)
+	if !ok {
+		exists, err := config.ModuleReleaseSource(location).RepositoryExists(ctx, location.Owner, location.Repository)
+		return location, exists, nil, err
+	}
+
+	metadata, err := metadataSource.RepositoryMetadata(ctx, location.Owner, location.Repository)
+	if err != nil || metadata == nil {
+		return location, false, nil, err
+	}
+	if metadata.MovedTo != "" {
+		slog.Info("Repository has moved, reading versions from its new location", "moved_to", metadata.MovedTo)
+		location = config.MovedModuleLocation(location, metadata.MovedTo)
+	}
+	return location, true, warnings.RepositoryWarnings("module", warnings.Repository{
+		Archived: metadata.Archived,
+		Disabled: metadata.Disabled,
+		MovedTo:  metadata.MovedTo,
+	}), nil
+}
+
+// withWarningHeaders adds warnings to a successful download response, which has no room for them in its body,
+// as Warning headers with the "299 Miscellaneous Persistent Warning" code.
+func withWarningHeaders(response events.APIGatewayProxyResponse, warn []string) events.APIGatewayProxyResponse {
+	if len(warn) == 0 || response.StatusCode >= http.StatusBadRequest {
+		return response
+	}
+
+	if response.MultiValueHeaders == nil {
+		response.MultiValueHeaders = make(map[string][]string)
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	for _, w := range warn {
+		response.MultiValueHeaders["Warning"] = append(response.MultiValueHeaders["Warning"], fmt.Sprintf(`299 - "%s"`, escaper.Replace(w)))
+	}
+	return response
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers"
+	"golang.org/x/exp/slog"
+)
+
+// GithubWebhookResponse describes what a webhook delivery was used for.
+type GithubWebhookResponse struct {
+	// Providers are the effective addresses of the providers read from the repository, whose versions are being refreshed.
+	Providers []string `json:"providers,omitempty"`
+	// Module is the address of the module the release or tag belongs to. Module versions are read from the repository
+	// on every request, so they are served as soon as they are tagged and need no refresh.
+	Module string `json:"module,omitempty"`
+	// Ignored explains why the delivery was not used.
+	Ignored string `json:"ignored,omitempty"`
+}
+
+// githubWebhook receives the release and push events of GitHub webhooks, and refreshes the versions of the providers
+// released from the repository of the event right away rather than once their cached versions are stale.
+func githubWebhook(config config.Config) LambdaFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if config.GithubWebhookSecret == "" {
+			slog.Info("Webhooks are not enabled")
+			return NotFoundResponse, nil
+		}
+
+		body, err := readRequestBody(req)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+		if err := github.VerifyWebhookSignature(config.GithubWebhookSecret, body, getHeader(req, "X-Hub-Signature-256")); err != nil {
+			slog.Info("Rejecting webhook delivery with invalid signature", "error", err)
+			return errorResponse(http.StatusUnauthorized, "invalid signature"), nil
+		}
+
+		eventType := getHeader(req, "X-GitHub-Event")
+		delivery := getHeader(req, "X-GitHub-Delivery")
+		slog.SetDefault(slog.Default().With("event", eventType).With("delivery", delivery))
+
+		event, err := github.ParseWebhookEvent(eventType, body)
+		if err != nil {
+			slog.Info("Rejecting invalid webhook delivery", "error", err)
+			return errorResponse(http.StatusBadRequest, err.Error()), nil
+		}
+		if event == nil {
+			slog.Info("Ignoring event that does not add a release or tag")
+			return webhookResponse(http.StatusOK, GithubWebhookResponse{Ignored: "the event does not add a release or tag"})
+		}
+		slog.Info("Received release", "owner", event.Owner, "repository", event.Repository, "tag", event.Tag)
+
+		// repositories named like a provider are not read if the provider is redirected elsewhere, while the source
+		// mappings can read providers from repositories named otherwise
+		if locations := config.SourceMappings.ProvidersInRepository(event.Owner, event.Repository); len(locations) > 0 {
+			// redeliveries of the same delivery are duplicates, while every new release refreshes the versions
+			var trigger string
+			if delivery != "" {
+				trigger = "github_delivery:" + delivery
+			}
+
+			var refreshed []string
+			for _, location := range locations {
+				if err := forcePopulateProviderVersions(ctx, config, location.Namespace, location.Type, trigger); err != nil {
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+				}
+				refreshed = append(refreshed, fmt.Sprintf("%s/%s", location.Namespace, location.Type))
+			}
+			return webhookResponse(http.StatusAccepted, GithubWebhookResponse{Providers: refreshed})
+		}
+
+		if _, ok := providers.GetProviderType(event.Repository); ok {
+			slog.Info("Ignoring release of a repository that is not read")
+			return webhookResponse(http.StatusOK, GithubWebhookResponse{Ignored: "the registry does not read the releases of this repository"})
+		}
+
+		if system, name, ok := modules.GetModuleSystemAndName(event.Repository); ok {
+			return webhookResponse(http.StatusOK, GithubWebhookResponse{Module: fmt.Sprintf("%s/%s/%s", strings.ToLower(event.Owner), name, system)})
+		}
+
+		slog.Info("Ignoring release of a repository that no provider is read from and is not named like a module")
+		return webhookResponse(http.StatusOK, GithubWebhookResponse{Ignored: "no provider is read from the repository and it is not named like a module repository"})
+	}
+}
+
+func webhookResponse(statusCode int, response GithubWebhookResponse) (events.APIGatewayProxyResponse, error) {
+	resBody, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, err
+	}
+	return events.APIGatewayProxyResponse{StatusCode: statusCode, Body: string(resBody)}, nil
+}
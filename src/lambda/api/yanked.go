@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/mappings"
+	"github.com/opentofu/registry/internal/modules"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/yank"
+	"golang.org/x/exp/slog"
+)
+
+// removeYankedProviderVersions removes the yanked versions of a provider, identified by its effective address,
+// and returns a warning explaining each removed version.
+func removeYankedProviderVersions(config config.Config, effectiveNamespace, effectiveType string, versionList types.VersionList) (types.VersionList, []string) {
+	address := fmt.Sprintf("%s/%s", effectiveNamespace, effectiveType)
+	return yank.Filter(config.YankedVersions, address, versionList, func(v types.CacheVersion) string { return v.Version })
+}
+
+// removeYankedModuleVersions removes the yanked versions of a module and returns a warning explaining each removed version.
+func removeYankedModuleVersions(config config.Config, location mappings.ModuleLocation, versions []modules.Version) ([]modules.Version, []string) {
+	address := fmt.Sprintf("%s/%s/%s", location.Namespace, location.Name, location.System)
+	return yank.Filter(config.YankedVersions, address, versions, func(v modules.Version) string { return v.Version })
+}
+
+// yankedResponse is returned instead of the download details of a yanked version.
+func yankedResponse(version, reason string) events.APIGatewayProxyResponse {
+	slog.Info("Version has been yanked, returning 404", "version", version, "reason", reason)
+	return errorResponse(http.StatusNotFound, yank.Warning(version, reason))
+}
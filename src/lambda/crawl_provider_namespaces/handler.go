@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/opentofu/registry/internal/config"
+	"github.com/opentofu/registry/internal/jobs"
+	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slog"
+)
+
+// CrawlProviderNamespacesEvent is sent by the schedule triggering the crawler. If Namespaces is empty,
+// every namespace with registered keys and every namespace in CRAWL_NAMESPACES is crawled.
+type CrawlProviderNamespacesEvent struct {
+	Namespaces []string `json:"namespaces"`
+}
+
+type LambdaFunc func(ctx context.Context, e CrawlProviderNamespacesEvent) (string, error)
+
+func setupLogging() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+}
+
+func HandleRequest(config *config.Config) LambdaFunc {
+	return func(ctx context.Context, e CrawlProviderNamespacesEvent) (string, error) {
+		setupLogging()
+
+		namespaces, err := namespacesToCrawl(config, e)
+		if err != nil {
+			slog.Error("Error getting namespaces to crawl", "error", err)
+			return "", err
+		}
+
+		slog.Info("Crawling namespaces", "namespaces", len(namespaces))
+
+		// a single namespace failing should not stop the others from being crawled
+		var crawlErrors []error
+		for _, namespace := range namespaces {
+			if err := crawlNamespace(ctx, config, namespace); err != nil {
+				slog.Error("Error crawling namespace", "namespace", namespace, "error", err)
+				crawlErrors = append(crawlErrors, fmt.Errorf("failed to crawl %s: %w", namespace, err))
+			}
+		}
+
+		return "", errors.Join(crawlErrors...)
+	}
+}
+
+// namespacesToCrawl returns the effective namespaces to crawl, without duplicates.
+func namespacesToCrawl(config *config.Config, e CrawlProviderNamespacesEvent) ([]string, error) {
+	namespaces := e.Namespaces
+	if len(namespaces) == 0 {
+		namespacesWithKeys, err := providers.NamespacesWithKeys()
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespacesWithKeys, config.CrawlNamespaces...)
+	}
+
+	seen := make(map[string]bool)
+	var effectiveNamespaces []string
+	for _, namespace := range namespaces {
+		effectiveNamespace := config.EffectiveProviderNamespace(namespace)
+		if seen[effectiveNamespace] {
+			continue
+		}
+		seen[effectiveNamespace] = true
+		effectiveNamespaces = append(effectiveNamespaces, effectiveNamespace)
+	}
+
+	sort.Strings(effectiveNamespaces)
+	return effectiveNamespaces, nil
+}
+
+// crawlNamespace lists the provider repositories of a namespace, records them in the namespace index,
+// and triggers the population of each provider's versions.
+func crawlNamespace(ctx context.Context, config *config.Config, namespace string) error {
+	return xray.Capture(ctx, "crawl_provider_namespaces.namespace", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", namespace)
+
+		lister, ok := config.ReleaseSourceFor(namespace).(source.RepositoryLister)
+		if !ok {
+			slog.Info("Release source of namespace cannot list repositories, skipping", "namespace", namespace)
+			return nil
+		}
+
+		repositories, err := lister.ListRepositories(tracedCtx, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to list repositories: %w", err)
+		}
+
+		index := types.NamespaceIndex{
+			Namespace:   namespace,
+			Providers:   []types.IndexedProvider{},
+			LastUpdated: time.Now(),
+		}
+		for _, repo := range repositories {
+			providerType, ok := providers.GetProviderType(repo.Name)
+			if !ok {
+				continue
+			}
+			index.Providers = append(index.Providers, types.IndexedProvider{
+				Type:        providerType,
+				Repository:  repo.Name,
+				Description: repo.Description,
+				SourceURL:   repo.URL,
+				Topics:      repo.Topics,
+			})
+		}
+
+		slog.Info("Found providers in namespace", "namespace", namespace, "providers", len(index.Providers))
+
+		if err := config.ProviderNamespaceIndex.Store(tracedCtx, index); err != nil {
+			return fmt.Errorf("failed to store namespace index: %w", err)
+		}
+
+		// the populate lambda does nothing for providers that are already up to date, so every provider can be enqueued
+		for _, provider := range index.Providers {
+			// providers can be mapped to another address individually, which is where their versions are cached
+			location := config.ProviderLocation(namespace, provider.Type)
+			job := jobs.PopulateProviderVersions{Namespace: location.Namespace, Type: location.Type}
+			if err := job.Dispatch(tracedCtx, config.PopulateDispatcher); err != nil {
+				slog.Error("Error triggering lambda", "namespace", namespace, "type", provider.Type, "error", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/opentofu/registry/internal/config"
+)
+
+func main() {
+	configBuilder := config.NewBuilder(config.WithProviderRedirects())
+	config, err := configBuilder.BuildConfig(context.Background(), "crawl_provider_namespaces.buildconfig")
+	if err != nil {
+		panic(fmt.Errorf("could not build config: %w", err))
+	}
+
+	lambda.Start(HandleRequest(config))
+}
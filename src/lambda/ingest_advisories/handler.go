@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/opentofu/registry/internal/advisories"
+	"github.com/opentofu/registry/internal/config"
+	"golang.org/x/exp/slog"
+)
+
+type LambdaFunc func(ctx context.Context) (string, error)
+
+func setupLogging() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+}
+
+// HandleRequest downloads the advisories feed and stores the advisories relevant to the registry in the artifact
+// store, where the API reads them from. Feeds can be large, so this runs on a schedule rather than on the request path.
+func HandleRequest(config *config.Config) LambdaFunc {
+	return func(ctx context.Context) (string, error) {
+		setupLogging()
+
+		if config.AdvisoriesFeedURL == "" || config.AdvisoriesObjectKey == "" || config.ArtifactStore == nil {
+			return "", errors.New("ADVISORIES_FEED_URL, ADVISORIES_OBJECT_KEY and the artifact store must be configured")
+		}
+
+		err := ingest(ctx, config, advisories.FeedFetcher(config.AdvisoriesFeedURL))
+		if err != nil {
+			slog.Error("Error ingesting advisories", "error", err)
+		}
+		return "", err
+	}
+}
+
+func ingest(ctx context.Context, config *config.Config, fetchFeed func(ctx context.Context) ([]byte, error)) error {
+	contents, err := fetchFeed(ctx)
+	if err != nil {
+		return err
+	}
+
+	feed, err := advisories.ParseFeed(contents)
+	if err != nil {
+		return fmt.Errorf("invalid advisories feed: %w", err)
+	}
+
+	// the stored list is never null, so that it can be told apart from a missing object
+	relevant := append([]advisories.Advisory{}, advisories.Relevant(feed)...)
+	stored, err := json.Marshal(relevant)
+	if err != nil {
+		return fmt.Errorf("failed to marshal advisories: %w", err)
+	}
+
+	if err := config.ArtifactStore.Put(ctx, config.AdvisoriesObjectKey, stored, "application/json"); err != nil {
+		return fmt.Errorf("failed to store advisories: %w", err)
+	}
+
+	slog.Info("Ingested advisories", "feed", len(feed), "stored", len(relevant))
+	return nil
+}
@@ -2,21 +2,30 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/aws/aws-xray-sdk-go/xray"
 	"github.com/opentofu/registry/internal/config"
-	"github.com/opentofu/registry/internal/github"
+	"github.com/opentofu/registry/internal/mappings"
 	"github.com/opentofu/registry/internal/providers"
+	"github.com/opentofu/registry/internal/providers/providercache"
 	"github.com/opentofu/registry/internal/providers/types"
+	"github.com/opentofu/registry/internal/source"
+	"golang.org/x/exp/slices"
 	"golang.org/x/exp/slog"
 )
 
 type PopulateProviderVersionsEvent struct {
 	Namespace string `json:"namespace"`
 	Type      string `json:"type"`
+	// Force populates the versions even if the cached document is up to date, such as when a webhook reports a new release.
+	Force bool `json:"force,omitempty"`
 }
 
 func (p PopulateProviderVersionsEvent) Validate() error {
@@ -41,75 +50,238 @@ func setupLogging(e PopulateProviderVersionsEvent) {
 
 func HandleRequest(config *config.Config) LambdaFunc {
 	return func(ctx context.Context, e PopulateProviderVersionsEvent) (string, error) {
-		setupLogging(e)
+		return "", handle(ctx, config, e, nil)
+	}
+}
 
-		var versions types.VersionList
+// maxFollowedMoves bounds how many moved repositories are followed from a single event, as repositories can be
+// recorded as moved back and forth between providers.
+const maxFollowedMoves = 5
 
-		slog.Info("Populating provider versions")
-		err := xray.Capture(ctx, "populate_provider_versions.handle", func(tracedCtx context.Context) error {
-			xray.AddAnnotation(tracedCtx, "namespace", e.Namespace)
-			xray.AddAnnotation(tracedCtx, "type", e.Type)
+// handle populates the versions of the provider of an event. followed holds the providers whose repositories were
+// followed to this one after they moved.
+func handle(ctx context.Context, config *config.Config, e PopulateProviderVersionsEvent, followed []string) error {
+	setupLogging(e)
 
-			err := e.Validate()
-			if err != nil {
-				slog.Error("invalid event", "error", err)
-				return fmt.Errorf("invalid event: %w", err)
-			}
+	err := e.Validate()
+	if err != nil {
+		slog.Error("invalid event", "error", err)
+		return fmt.Errorf("invalid event: %w", err)
+	}
+
+	// only one invocation populates the versions of a provider at a time
+	key := fmt.Sprintf("%s/%s", e.Namespace, e.Type)
+	owner := newLeaseOwner(ctx)
+	acquired, err := acquireLease(ctx, config, e, owner)
+	if err != nil || !acquired {
+		return err
+	}
+
+	for {
+		movedEvent, err := populate(ctx, config, e)
+		if err != nil {
+			abandonLease(ctx, config, key, owner)
+			return err
+		}
+
+		rerun, err := config.ProviderLeases.Release(ctx, key, owner, leaseExpiry(ctx))
+		if err != nil {
+			// the lease expires on its own
+			slog.Error("Error releasing lease", "error", err)
+		}
+		if !rerun {
+			return followMove(ctx, config, key, movedEvent, followed)
+		}
+
+		slog.Info("Populating versions again, as a refresh was requested while they were populated")
+		e.Force = true
+	}
+}
+
+// followMove populates the versions of the provider a repository moved to, unless it was already followed to from
+// the same event.
+func followMove(ctx context.Context, config *config.Config, key string, movedEvent *PopulateProviderVersionsEvent, followed []string) error {
+	if movedEvent == nil {
+		return nil
+	}
+
+	followed = append(followed, key)
+	movedKey := fmt.Sprintf("%s/%s", movedEvent.Namespace, movedEvent.Type)
+	if slices.Contains(followed, movedKey) || len(followed) > maxFollowedMoves {
+		slog.Warn("Not following the moved repository, as too many moves or a cycle of moves were followed", "moved_to", movedKey, "followed", followed)
+		return nil
+	}
+	return handle(ctx, config, *movedEvent, followed)
+}
+
+// acquireLease takes the lease of the provider, returning false if another invocation holds it. Forced events ask
+// the holder to populate the versions again instead of being dropped, as the holder may have fetched the releases
+// before the one that forced the event was published.
+func acquireLease(ctx context.Context, config *config.Config, e PopulateProviderVersionsEvent, owner string) (bool, error) {
+	key := fmt.Sprintf("%s/%s", e.Namespace, e.Type)
+	for attempt := 0; attempt < 2; attempt++ {
+		err := config.ProviderLeases.Acquire(ctx, key, owner, leaseExpiry(ctx))
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, providercache.ErrLeaseHeld) {
+			return false, err
+		}
+		if !e.Force {
+			slog.Info("Versions are being populated by another invocation, not updating")
+			return false, nil
+		}
+
+		err = config.ProviderLeases.RequestRerun(ctx, key)
+		if err == nil {
+			slog.Info("Versions are being populated by another invocation, which will populate them again")
+			return false, nil
+		}
+		if !errors.Is(err, providercache.ErrLeaseNotHeld) {
+			return false, err
+		}
+		// the lease was released in the meantime
+	}
+
+	// failing lets the invocation be retried
+	return false, fmt.Errorf("could not acquire the lease of %s nor request a rerun from its holder", key)
+}
 
-			var since *time.Time
+// populate fetches the versions of the provider and stores them, unless the cached versions are up to date. If the
+// repository of the provider moved to the repository of another provider, the move is recorded instead and the
+// event populating that provider is returned.
+func populate(ctx context.Context, config *config.Config, e PopulateProviderVersionsEvent) (*PopulateProviderVersionsEvent, error) {
+	var versions types.VersionList
+	var ingestionWarnings []string
+	var metadata *source.RepositoryMetadata
+	var movedEvent *PopulateProviderVersionsEvent
+	var upToDate, fetchedInFull bool
 
-			// check if the document exists in dynamodb, if it does, and it's newer than the allowed max age,
-			// we should treat it as a noop and just return
-			document, err := config.ProviderVersionCache.GetItem(tracedCtx, fmt.Sprintf("%s/%s", e.Namespace, e.Type))
-			if err != nil {
-				// if there was an error getting the document, that's fine. we'll just log it and carry on
-				slog.Error("Error getting document from cache", "error", err)
+	slog.Info("Populating provider versions")
+	err := xray.Capture(ctx, "populate_provider_versions.handle", func(tracedCtx context.Context) error {
+		xray.AddAnnotation(tracedCtx, "namespace", e.Namespace)
+		xray.AddAnnotation(tracedCtx, "type", e.Type)
+
+		var since *time.Time
+
+		// check if the document exists in dynamodb, if it does, and it's newer than the allowed max age,
+		// we should treat it as a noop and just return
+		document, err := config.ProviderVersionCache.GetItem(tracedCtx, fmt.Sprintf("%s/%s", e.Namespace, e.Type))
+		if err != nil {
+			// if there was an error getting the document, that's fine. we'll just log it and carry on
+			slog.Error("Error getting document from cache", "error", err)
+		}
+		if document != nil {
+			if !document.IsStale() && !e.Force {
+				slog.Info("Document is up to date, not updating")
+				upToDate = true
+				return nil
 			}
-			if document != nil {
-				if !document.IsStale() {
-					slog.Info("Document is up to date, not updating")
-					return nil
-				}
-				slog.Info("Document is stale, fetching versions", "last_updated", document.LastUpdated)
-				since = &document.LastUpdated
+			slog.Info("Document is stale or refresh is forced, fetching versions", "last_updated", document.LastUpdated, "force", e.Force)
+			since = &document.LastUpdated
+			if document.SchemaVersion < types.SchemaVersion {
+				// versions cached with an older schema, such as before their publication times were recorded, are
+				// only updated by fetching them again
+				slog.Info("Cached versions have an older schema, fetching all versions", "schema_version", document.SchemaVersion)
+				since = nil
 			}
+		}
 
-			fetchedVersions, err := fetchFromGithub(tracedCtx, e, config, since)
-			if err != nil {
-				return err
-			}
+		// the event holds the effective address of the provider, which resolves to the same location
+		location := config.ProviderLocation(e.Namespace, e.Type)
 
-			// if we have a document, we should combine the fetched versions with the existing versions
-			// this is so that we don't lose any versions that were added since the last time we fetched
-			// but also so we don't add duplicates
-			if since != nil && document != nil {
-				fetchedVersions = append(document.Versions, fetchedVersions...)
-				slog.Info("Combined versions", "versions", len(fetchedVersions))
+		metadata, err = getRepositoryMetadata(tracedCtx, config, location)
+		if err != nil {
+			// the metadata is only informational, so we'll just log it and carry on with the known location
+			slog.Error("Error getting repository metadata", "error", err)
+		}
 
-				// deduplicate the versions
-				fetchedVersions = fetchedVersions.Deduplicate()
-				slog.Info("Deduplicated versions", "versions", len(fetchedVersions))
+		// renamed and transferred repositories are read from their new location
+		if metadata != nil && metadata.MovedTo != "" {
+			moved, changed := config.MovedProviderLocation(location, metadata.MovedTo)
+			if changed {
+				slog.Info("Repository has moved to another provider", "moved_to", metadata.MovedTo, "namespace", moved.Namespace, "type", moved.Type)
+				movedEvent = &PopulateProviderVersionsEvent{Namespace: moved.Namespace, Type: moved.Type, Force: e.Force}
+				if document != nil {
+					versions = document.Versions
+				}
+				return nil
 			}
+			slog.Info("Repository has moved, fetching versions from its new location", "moved_to", metadata.MovedTo)
+			location = moved
+		}
 
-			versions = fetchedVersions
-			return nil
-		})
+		// when fetching new releases, the releases of the cached versions need not be read again
+		var known types.VersionList
+		if since != nil {
+			known = document.Versions
+		}
 
+		fetchedVersions, fetchWarnings, err := fetchFromGithub(tracedCtx, location, config, since, known)
 		if err != nil {
-			slog.Error("Error fetching versions", "error", err)
-			return "", err
+			return err
 		}
 
-		err = storeVersions(ctx, e, versions, config)
-		if err != nil {
-			return "", err
+		// if only new releases were fetched, we should combine the fetched versions with the existing versions
+		// this is so that we don't lose any versions that were added since the last time we fetched
+		// but also so we don't add duplicates, for which the fetched version is kept as it is the most recent.
+		// when all releases were fetched, the cached versions are replaced, so that deleted releases are removed
+		fetchedInFull = since == nil
+		if since != nil {
+			fetchedVersions = append(fetchedVersions, document.Versions...)
+			slog.Info("Combined versions", "versions", len(fetchedVersions))
+
+			// deduplicate the versions
+			fetchedVersions = fetchedVersions.Deduplicate()
+			slog.Info("Deduplicated versions", "versions", len(fetchedVersions))
+			fetchedVersions.SortDescending()
+
+			// the same goes for the warnings about skipped releases
+			fetchWarnings = mergeWarnings(document.IngestionWarnings, fetchWarnings)
+		}
+
+		versions = fetchedVersions
+		ingestionWarnings = fetchWarnings
+		return nil
+	})
+
+	if err != nil {
+		slog.Error("Error fetching versions", "error", err)
+		return nil, err
+	}
+	if upToDate {
+		return nil, nil
+	}
+
+	if movedEvent != nil {
+		// the move is recorded under the old address, which the API then serves from the new one
+		if err := recordMove(ctx, e, config, versions, *metadata); err != nil {
+			return nil, err
 		}
+		return movedEvent, nil
+	}
 
-		return "", nil
+	err = storeVersions(ctx, e, versions, fetchedInFull, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 {
+		// the metadata and warnings are only informational, so failing to update them should not fail the whole population
+		if metadata != nil {
+			if err := config.ProviderVersionCache.StoreRepositoryMetadata(ctx, fmt.Sprintf("%s/%s", e.Namespace, e.Type), *metadata); err != nil {
+				slog.Error("Error storing repository metadata", "error", err)
+			}
+		}
+		if err := config.ProviderVersionCache.StoreIngestionWarnings(ctx, fmt.Sprintf("%s/%s", e.Namespace, e.Type), ingestionWarnings); err != nil {
+			slog.Error("Error storing ingestion warnings", "error", err)
+		}
 	}
+
+	return nil, nil
 }
 
-func storeVersions(ctx context.Context, e PopulateProviderVersionsEvent, versions types.VersionList, config *config.Config) error {
+func storeVersions(ctx context.Context, e PopulateProviderVersionsEvent, versions types.VersionList, fetchedInFull bool, config *config.Config) error {
 	if len(versions) == 0 {
 		slog.Error("No versions found, skipping storage")
 		return nil
@@ -117,28 +289,101 @@ func storeVersions(ctx context.Context, e PopulateProviderVersionsEvent, version
 
 	key := fmt.Sprintf("%s/%s", e.Namespace, e.Type)
 
-	err := config.ProviderVersionCache.Store(ctx, key, versions)
+	store := config.ProviderVersionCache.Store
+	if fetchedInFull {
+		store = config.ProviderVersionCache.StoreFetchedInFull
+	}
+	if err := store(ctx, key, versions); err != nil {
+		return fmt.Errorf("failed to store provider listing: %w", err)
+	}
+	return nil
+}
+
+// defaultLeaseDuration is how long the lease on populating the versions of a provider is held without a deadline
+// for the invocation, which is the maximum duration of a Lambda invocation.
+const defaultLeaseDuration = 15 * time.Minute
+
+// leaseExpiry returns when the lease taken by an invocation expires. Lambda stops invocations at their deadline,
+// so the lease of an invocation that crashed or timed out can be taken over from then on.
+func leaseExpiry(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(defaultLeaseDuration)
+}
+
+// newLeaseOwner identifies an invocation as the owner of a lease by its request ID.
+func newLeaseOwner(ctx context.Context) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		return lc.AwsRequestID
+	}
+
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+// abandonLease lets the next invocation populate the versions of the provider right away after a failure, which the
+// invocation is retried for. Failing to abandon the lease is only logged, as it expires on its own.
+func abandonLease(ctx context.Context, config *config.Config, key, owner string) {
+	if err := config.ProviderLeases.Abandon(ctx, key, owner); err != nil {
+		slog.Error("Error abandoning lease", "error", err)
+	}
+}
+
+// getRepositoryMetadata returns the description, URL and state of the provider's repository, or nil if its release source cannot provide them.
+func getRepositoryMetadata(ctx context.Context, config *config.Config, location mappings.ProviderLocation) (*source.RepositoryMetadata, error) {
+	metadataSource, ok := config.ReleaseSourceFor(location.Namespace).(source.MetadataSource)
+	if !ok {
+		return nil, nil
+	}
+
+	metadata, err := metadataSource.RepositoryMetadata(ctx, location.Owner, location.Repository)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get repository metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// recordMove stores the metadata of a repository that has moved to another provider under the provider's address.
+// The versions are stored again as well, so that the document is not considered stale until the move is checked again.
+func recordMove(ctx context.Context, e PopulateProviderVersionsEvent, config *config.Config, versions types.VersionList, metadata source.RepositoryMetadata) error {
+	key := fmt.Sprintf("%s/%s", e.Namespace, e.Type)
+
+	if err := config.ProviderVersionCache.Store(ctx, key, versions); err != nil {
 		return fmt.Errorf("failed to store provider listing: %w", err)
 	}
+	if err := config.ProviderVersionCache.StoreRepositoryMetadata(ctx, key, metadata); err != nil {
+		return fmt.Errorf("failed to store repository metadata: %w", err)
+	}
 	return nil
 }
 
-func fetchFromGithub(ctx context.Context, e PopulateProviderVersionsEvent, config *config.Config, since *time.Time) (types.VersionList, error) {
-	// Construct the repo name.
-	repoName := providers.GetRepoName(e.Type)
+// mergeWarnings appends the warnings that are not already in existing.
+func mergeWarnings(existing, warnings []string) []string {
+	merged := append([]string{}, existing...)
+	for _, warning := range warnings {
+		if !slices.Contains(merged, warning) {
+			merged = append(merged, warning)
+		}
+	}
+	return merged
+}
+
+func fetchFromGithub(ctx context.Context, location mappings.ProviderLocation, config *config.Config, since *time.Time, known types.VersionList) (types.VersionList, []string, error) {
+	src := config.ProviderReleaseSource(location)
 
 	// if we've been provided with a "since" we don't have to check if the repo exists
 	// we can assume that it does because we've already fetched versions from it before
 
 	if since == nil {
 		// check the repo exists
-		exists, err := github.RepositoryExists(ctx, config.ManagedGithubClient, e.Namespace, repoName)
+		exists, err := src.RepositoryExists(ctx, location.Owner, location.Repository)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check if repo exists: %w", err)
+			return nil, nil, fmt.Errorf("failed to check if repo exists: %w", err)
 		}
 		if !exists {
-			return nil, fmt.Errorf("repo %s/%s does not exist", e.Namespace, repoName)
+			return nil, nil, fmt.Errorf("repo %s/%s does not exist", location.Owner, location.Repository)
 		}
 	} else {
 		slog.Info("Skipping repo existence check because we already have a document in dynamodb")
@@ -146,10 +391,10 @@ func fetchFromGithub(ctx context.Context, e PopulateProviderVersionsEvent, confi
 
 	slog.Info("Fetching versions")
 
-	v, err := providers.GetVersions(ctx, config.RawGithubv4Client, e.Namespace, repoName, since)
+	v, warnings, err := providers.GetNewVersions(ctx, src, location.Owner, location.Repository, since, known)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get versions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get versions: %w", err)
 	}
 
-	return v, nil
+	return v, warnings, nil
 }
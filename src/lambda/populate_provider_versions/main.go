@@ -9,11 +9,15 @@ import (
 )
 
 func main() {
-	configBuilder := config.NewBuilder()
+	configBuilder := config.NewBuilder(config.WithProviderLeases())
 	config, err := configBuilder.BuildConfig(context.Background(), "populate_provider_versions.buildconfig")
 	if err != nil {
 		panic(fmt.Errorf("could not build config: %w", err))
 	}
 
+	if config.JobsQueued() {
+		lambda.Start(HandleQueueRequest(HandleRequest(config)))
+		return
+	}
 	lambda.Start(HandleRequest(config))
 }
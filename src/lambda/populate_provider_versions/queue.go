@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"golang.org/x/exp/slog"
+)
+
+type QueueLambdaFunc func(ctx context.Context, e events.SQSEvent) (events.SQSEventResponse, error)
+
+// HandleQueueRequest handles the messages of the populate jobs queue, which are PopulateProviderVersionsEvents. Failed
+// messages are reported to SQS, which delivers them again once their visibility timeout expires.
+func HandleQueueRequest(handle LambdaFunc) QueueLambdaFunc {
+	return func(ctx context.Context, e events.SQSEvent) (events.SQSEventResponse, error) {
+		var response events.SQSEventResponse
+		for _, record := range e.Records {
+			var event PopulateProviderVersionsEvent
+			if err := json.Unmarshal([]byte(record.Body), &event); err != nil {
+				// the message would fail again on every delivery
+				slog.Error("Dropping invalid populate job", "message", record.MessageId, "error", err)
+				continue
+			}
+
+			if _, err := handle(ctx, event); err != nil {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+			}
+		}
+		return response, nil
+	}
+}
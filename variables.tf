@@ -21,3 +21,96 @@ variable "provider_namespace_redirects" {
     "hashicorp" : "opentofu"
   }
 }
+
+variable "source_mappings_file" {
+  description = "Path to a JSON file mapping individual providers and modules to the repositories they are read from, see the README"
+  type        = string
+  default     = ""
+}
+
+variable "advisories_path" {
+  description = "Path to a JSON file or a directory of JSON files holding security advisories in the OSV format, see the README"
+  type        = string
+  default     = ""
+}
+
+variable "advisories_feed_url" {
+  description = "URL of a feed of security advisories in the OSV format, either a JSON list or a zip archive of advisories, ingested on the advisories_schedule_expression schedule"
+  type        = string
+  default     = ""
+}
+
+variable "advisories_schedule_expression" {
+  description = "How often the advisories feed is ingested, as an EventBridge schedule expression"
+  type        = string
+  default     = "rate(30 minutes)"
+}
+
+variable "yanked_versions" {
+  description = "Map of provider (\"<namespace>/<type>\") and module (\"<namespace>/<name>/<system>\") addresses to the versions that must no longer be served and the reason, e.g. { \"acme/widget\" = { \"1.1.0\" = \"Broken checksums, use 1.1.1\" } }"
+  type        = map(map(string))
+  default     = {}
+}
+
+variable "provider_unsigned_release_policies" {
+  description = "Map of provider namespaces to the policy for releases without a SHA256SUMS signature: require (the default), allow or warn. The \"*\" key applies to all other namespaces."
+  type        = map(string)
+  default     = {}
+}
+
+variable "provider_namespace_tiers" {
+  description = "Map of provider namespaces to their tier in search results: official, partner or community (the default)"
+  type        = map(string)
+  default = {
+    "opentofu" : "official"
+  }
+}
+
+variable "namespace_sources" {
+  description = "Map of namespaces to the backend hosting their releases when it is not GitHub, e.g. { internal = { type = \"gitlab\", url = \"https://gitlab.example.com\", token_secret_asm_name = \"gitlab-token\" } }"
+  type        = map(any)
+  default     = {}
+}
+
+variable "namespace_source_secret_arns" {
+  description = "ARNs of the Secrets Manager secrets holding the API tokens referenced in namespace_sources"
+  type        = list(string)
+  default     = []
+}
+
+variable "publish_api_tokens" {
+  description = "Map of namespaces to the token that requests publishing to them must send as a bearer token, publishing is disabled if empty"
+  type        = map(string)
+  sensitive   = true
+  default     = {}
+}
+
+variable "github_webhook_secret" {
+  description = "Secret that GitHub webhook deliveries to /v1/webhooks/github are signed with, webhooks are disabled if empty"
+  type        = string
+  sensitive   = true
+  default     = ""
+}
+
+variable "job_dispatcher" {
+  description = "How jobs populating provider versions reach the populate lambda: lambda (invoked directly, the default) or sqs (through an SQS FIFO queue, which retries failed jobs)"
+  type        = string
+  default     = "lambda"
+
+  validation {
+    condition     = contains(["lambda", "sqs"], var.job_dispatcher)
+    error_message = "The job_dispatcher must be lambda or sqs."
+  }
+}
+
+variable "crawl_namespaces" {
+  description = "Namespaces to crawl for providers in addition to the namespaces with registered keys"
+  type        = list(string)
+  default     = []
+}
+
+variable "crawl_schedule_expression" {
+  description = "How often the provider namespace crawler runs, as an EventBridge schedule expression"
+  type        = string
+  default     = "rate(1 day)"
+}